@@ -0,0 +1,316 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"rental-profit-api/internal/api"
+	"rental-profit-api/internal/booking"
+	"rental-profit-api/internal/buildinfo"
+	"rental-profit-api/internal/types"
+)
+
+// clientFlags are the flags of the client command on top of localFlags.
+type clientFlags struct {
+	server    string
+	apiKey    string
+	apiKeySet bool
+	retries   int
+	retryWait time.Duration
+	timeout   time.Duration
+}
+
+func (f *clientFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.server, "server", envOr("RENTALCTL_SERVER", "http://localhost:8080"), "base URL of the server (env RENTALCTL_SERVER)")
+	// Not a default taken from the environment, which -h would print
+	fs.Func("api-key", "API key sent as "+api.APIKeyHeader+" (env RENTALCTL_API_KEY)", func(key string) error {
+		f.apiKey, f.apiKeySet = key, true
+		return nil
+	})
+	fs.IntVar(&f.retries, "retries", 3, "retries after a network error, 429, 502, 503 or 504")
+	fs.DurationVar(&f.retryWait, "retry-wait", time.Second, "wait before the first retry, doubled on every retry unless the server sends Retry-After")
+	fs.DurationVar(&f.timeout, "timeout", time.Minute, "timeout of each attempt")
+}
+
+func envOr(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// runClient posts the bookings to /maximize or /stats of a running server
+// and prints the response like the local commands print their results.
+func runClient(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	usage := func() {
+		fmt.Fprintln(stderr, "Usage: rentalctl client maximize|stats [flags] [file ...]")
+		fmt.Fprintln(stderr, `Run "rentalctl client maximize -h" for the flags.`)
+	}
+	if len(args) == 0 {
+		usage()
+		return errUsage
+	}
+	operation := args[0]
+	switch operation {
+	case "maximize", "stats":
+	case "-h", "-help", "help":
+		usage()
+		return flag.ErrHelp
+	default:
+		fmt.Fprintf(stderr, "unknown client command %q\n", operation)
+		usage()
+		return errUsage
+	}
+
+	var remote clientFlags
+	flags, files, err := parseLocalFlags("client "+operation, args[1:], stderr, remote.register)
+	if err != nil {
+		return err
+	}
+	if !remote.apiKeySet {
+		remote.apiKey = os.Getenv("RENTALCTL_API_KEY")
+	}
+	client, err := newAPIClient(remote, stderr)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return errUsage
+	}
+	requests, err := readBookings(files, flags.input, stdin)
+	if err != nil {
+		return err
+	}
+
+	if operation == "stats" {
+		var stats types.StatsResponse
+		if err := client.post("/stats", requests, &stats); err != nil {
+			return err
+		}
+		return writeStats(stdout, flags.output, stats)
+	}
+	var response types.MaximizeResponse
+	if err := client.post("/maximize", requests, &response); err != nil {
+		return err
+	}
+	selected, err := selectedBookings(requests, response.RequestIDs)
+	if err != nil {
+		return err
+	}
+	return writeSchedule(stdout, flags.output, len(requests), selected, response)
+}
+
+// readBookings reads the booking requests of every file, or of stdin,
+// without validating them; the server does.
+func readBookings(files []string, format string, stdin io.Reader) ([]types.BookingRequest, error) {
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+	requests := []types.BookingRequest{}
+	for _, name := range files {
+		read, err := readBookingFile(name, format, stdin)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, read...)
+	}
+	return requests, nil
+}
+
+// selectedBookings looks up the bookings the server selected among those
+// sent, so they can be listed and drawn.
+func selectedBookings(requests []types.BookingRequest, requestIDs []string) ([]booking.Booking, error) {
+	byID := make(map[string]types.BookingRequest, len(requests))
+	for _, request := range requests {
+		if _, ok := byID[request.RequestID]; !ok {
+			byID[request.RequestID] = request
+		}
+	}
+	selected := make([]booking.Booking, 0, len(requestIDs))
+	for _, id := range requestIDs {
+		request, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("server selected unknown request_id %q", id)
+		}
+		checkin, err := time.Parse(booking.DateLayout, request.Checkin)
+		if err != nil {
+			return nil, fmt.Errorf("server selected %q with check_in %q: %w", id, request.Checkin, err)
+		}
+		selected = append(selected, booking.Booking{
+			RequestID:         request.RequestID,
+			Checkin:           checkin,
+			Nights:            request.Nights,
+			SellingRate:       request.SellingRate,
+			Margin:            request.Margin,
+			CancelProbability: request.CancelProbability,
+		})
+	}
+	return selected, nil
+}
+
+// apiClient calls the JSON API, retrying the failures a later attempt can
+// get past.
+type apiClient struct {
+	baseURL   string
+	apiKey    string
+	retries   int
+	retryWait time.Duration
+	http      *http.Client
+	log       io.Writer // Where retries are reported
+}
+
+func newAPIClient(flags clientFlags, log io.Writer) (*apiClient, error) {
+	base, err := url.Parse(flags.server)
+	if err != nil || (base.Scheme != "http" && base.Scheme != "https") || base.Host == "" {
+		return nil, fmt.Errorf("invalid -server %q: must be an http or https URL", flags.server)
+	}
+	if flags.retries < 0 {
+		return nil, fmt.Errorf("invalid -retries %d: must not be negative", flags.retries)
+	}
+	return &apiClient{
+		baseURL:   strings.TrimSuffix(base.String(), "/"),
+		apiKey:    flags.apiKey,
+		retries:   flags.retries,
+		retryWait: flags.retryWait,
+		http:      &http.Client{Timeout: flags.timeout},
+		log:       log,
+	}, nil
+}
+
+// statusError is a response other than 2xx, with the message and request ID
+// of its error body when there is one.
+type statusError struct {
+	code       int
+	message    string
+	requestID  string
+	retryAfter time.Duration
+}
+
+func (e *statusError) Error() string {
+	text := fmt.Sprintf("server answered %d %s", e.code, http.StatusText(e.code))
+	if e.message != "" {
+		text += ": " + e.message
+	}
+	if e.requestID != "" {
+		text += " (request ID " + e.requestID + ")"
+	}
+	return text
+}
+
+// transportError is a request that got no response at all. Unlike a
+// response that cannot be decoded, it may succeed when sent again.
+type transportError struct {
+	err error
+}
+
+func (e *transportError) Error() string {
+	return e.err.Error()
+}
+
+func (e *transportError) Unwrap() error {
+	return e.err
+}
+
+// retryable reports whether the request may succeed later: the server is
+// throttling, overloaded or behind a failing proxy.
+func (e *statusError) retryable() bool {
+	switch e.code {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// post sends body as JSON to path and decodes the response into result.
+// Network errors and retryable statuses are retried up to c.retries times,
+// waiting for the Retry-After of the response or else for c.retryWait,
+// doubled on every retry. A 2xx response that cannot be decoded is not
+// retried.
+func (c *apiClient) post(path string, body, result any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	wait := c.retryWait
+	for attempt := 0; ; attempt++ {
+		err := c.attempt(path, payload, result)
+		if err == nil {
+			return nil
+		}
+		var status *statusError
+		var transport *transportError
+		isStatus := errors.As(err, &status)
+		retryable := errors.As(err, &transport) || (isStatus && status.retryable())
+		if attempt == c.retries || !retryable {
+			return err
+		}
+		delay := wait
+		if isStatus && status.retryAfter >= 0 {
+			delay = status.retryAfter
+		}
+		fmt.Fprintf(c.log, "%v; retrying in %s (%d/%d)\n", err, delay, attempt+1, c.retries)
+		time.Sleep(delay)
+		wait *= 2
+	}
+}
+
+func (c *apiClient) attempt(path string, payload []byte, result any) error {
+	req, err := http.NewRequest(http.MethodPost, c.baseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "rentalctl/"+buildinfo.Read().Version)
+	if c.apiKey != "" {
+		req.Header.Set(api.APIKeyHeader, c.apiKey)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return &transportError{err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+			return fmt.Errorf("decoding response of %s: %w", path, err)
+		}
+		return nil
+	}
+	failure := &statusError{
+		code:       resp.StatusCode,
+		requestID:  resp.Header.Get("X-Request-ID"),
+		retryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+	}
+	var errorResponse types.ErrorResponse
+	if json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&errorResponse) == nil {
+		failure.message = errorResponse.Message
+		if errorResponse.RequestID != "" {
+			failure.requestID = errorResponse.RequestID
+		}
+	}
+	return failure
+}
+
+// parseRetryAfter reads a Retry-After of seconds or an HTTP date. It returns
+// -1 when the header is missing or malformed.
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return -1
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0)
+	}
+	return -1
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"rental-profit-api/internal/api"
+)
+
+func TestRunClient(t *testing.T) {
+	// --- Define Test Scenarios ---
+	testCases := []struct {
+		name                   string
+		args                   []string
+		stdin                  string
+		apiKeyEnv              string // Sets RENTALCTL_API_KEY instead of passing -api-key
+		failures               int    // Requests answered with failureStatus before the API serves
+		failureStatus          int    // 0 drops the connection without a response
+		failureBody            string
+		expectedCode           int
+		expectedAttempts       int
+		expectedStdoutContains []string
+		expectedStderrContains string
+		expectedStderrExcludes string
+	}{
+		{
+			name:             "Maximize Prints Timeline",
+			args:             []string{"client", "maximize"},
+			stdin:            testJSON,
+			expectedAttempts: 1,
+			expectedStdoutContains: []string{
+				"Timeline 2024-01-03 to 2024-01-08, 1 day per column\nB2  |==   |\nB3  |   ==|\n",
+				"Selected 2 of 3 bookings for a total profit of 60.00",
+			},
+		},
+		{
+			name:                   "Stats As CSV",
+			args:                   []string{"client", "stats", "-output", "csv", "-input", "csv"},
+			stdin:                  testCSV,
+			expectedAttempts:       1,
+			expectedStdoutContains: []string{"avg_night,min_night,max_night\n10.83,2.50,15.00\n"},
+		},
+		{
+			name:                   "Retries Unavailable Server",
+			args:                   []string{"client", "maximize", "-output", "json", "-retry-wait", "1ms"},
+			stdin:                  testJSON,
+			failures:               2,
+			failureStatus:          http.StatusServiceUnavailable,
+			expectedAttempts:       3,
+			expectedStdoutContains: []string{`"total_profit": 60`},
+			expectedStderrContains: "server answered 503 Service Unavailable; retrying in 1ms (1/3)",
+		},
+		{
+			name:                   "Honors Retry-After",
+			args:                   []string{"client", "stats", "-retry-wait", "1h"},
+			stdin:                  testJSON,
+			failures:               1,
+			failureStatus:          http.StatusTooManyRequests,
+			failureBody:            `{"message":"Rate limit exceeded","request_id":"req-1"}`,
+			expectedAttempts:       2,
+			expectedStdoutContains: []string{"10.83"},
+			expectedStderrContains: "server answered 429 Too Many Requests: Rate limit exceeded (request ID req-1); retrying in 0s",
+		},
+		{
+			name:                   "Gives Up After Retries",
+			args:                   []string{"client", "maximize", "-retries", "1", "-retry-wait", "1ms"},
+			stdin:                  testJSON,
+			failures:               5,
+			failureStatus:          http.StatusBadGateway,
+			expectedCode:           1,
+			expectedAttempts:       2,
+			expectedStderrContains: "rentalctl client: server answered 502 Bad Gateway\n",
+		},
+		{
+			name:                   "Retries Dropped Connection",
+			args:                   []string{"client", "stats", "-retry-wait", "1ms"},
+			stdin:                  testJSON,
+			failures:               1,
+			expectedAttempts:       2,
+			expectedStdoutContains: []string{"10.83"},
+			expectedStderrContains: "EOF; retrying in 1ms (1/3)",
+		},
+		{
+			name:                   "Undecodable Response Is Not Retried",
+			args:                   []string{"client", "maximize", "-retry-wait", "1ms"},
+			stdin:                  testJSON,
+			failures:               1,
+			failureStatus:          http.StatusOK,
+			failureBody:            "<html>maintenance</html>",
+			expectedCode:           1,
+			expectedAttempts:       1,
+			expectedStderrContains: "decoding response of /maximize: invalid character",
+			expectedStderrExcludes: "retrying",
+		},
+		{
+			name:                   "Rejected Bookings Are Not Retried",
+			args:                   []string{"client", "maximize"},
+			stdin:                  `[{"request_id":"B1","check_in":"2024-01-01","nights":0,"selling_rate":100,"margin":10}]`,
+			expectedCode:           1,
+			expectedAttempts:       1,
+			expectedStderrContains: "server answered 400 Bad Request: validation error: nights must be positive on item 0",
+		},
+		{
+			name:                   "Missing API Key",
+			args:                   []string{"client", "stats", "-api-key", ""},
+			stdin:                  testJSON,
+			expectedCode:           1,
+			expectedAttempts:       1,
+			expectedStderrContains: "server answered 401 Unauthorized",
+		},
+		{
+			name:                   "API Key From Environment",
+			args:                   []string{"client", "stats", "-output", "json"},
+			stdin:                  testJSON,
+			apiKeyEnv:              "test-key",
+			expectedAttempts:       1,
+			expectedStdoutContains: []string{`"avg_night": 10.83`},
+		},
+		{
+			name:                   "Help Does Not Print API Key",
+			args:                   []string{"client", "maximize", "-h"},
+			apiKeyEnv:              "test-key",
+			expectedStderrContains: "-api-key",
+			expectedStderrExcludes: "test-key",
+		},
+		{
+			name:                   "Unknown Client Command",
+			args:                   []string{"client", "compare"},
+			expectedCode:           2,
+			expectedStderrContains: `unknown client command "compare"`,
+		},
+		{
+			name:                   "Invalid Server",
+			args:                   []string{"client", "stats", "-server", "localhost:8080"},
+			expectedCode:           2,
+			expectedStderrContains: `invalid -server "localhost:8080"`,
+		},
+	}
+
+	// --- Execute Scenarios ---
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			mux := http.NewServeMux()
+			mux.HandleFunc("POST /maximize", api.MaximizeProfitHandler)
+			mux.HandleFunc("POST /stats", api.StatsHandler)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if attempts.Add(1) <= int32(tc.failures) {
+					if tc.failureStatus == 0 {
+						panic(http.ErrAbortHandler)
+					}
+					if tc.failureStatus == http.StatusTooManyRequests {
+						w.Header().Set("Retry-After", "0")
+					}
+					w.WriteHeader(tc.failureStatus)
+					w.Write([]byte(tc.failureBody))
+					return
+				}
+				if r.Header.Get(api.APIKeyHeader) != "test-key" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				mux.ServeHTTP(w, r)
+			}))
+			defer server.Close()
+
+			args := []string{tc.args[0], tc.args[1], "-server", server.URL}
+			if tc.apiKeyEnv != "" {
+				t.Setenv("RENTALCTL_API_KEY", tc.apiKeyEnv)
+			} else {
+				args = append(args, "-api-key", "test-key")
+			}
+			args = append(args, tc.args[2:]...)
+			var stdout, stderr bytes.Buffer
+			code := run(args, strings.NewReader(tc.stdin), &stdout, &stderr)
+
+			if code != tc.expectedCode {
+				t.Errorf("run() = %d, want %d. Stderr: %s", code, tc.expectedCode, stderr.String())
+			}
+			if got := int(attempts.Load()); got != tc.expectedAttempts {
+				t.Errorf("Server got %d requests, want %d", got, tc.expectedAttempts)
+			}
+			for _, want := range tc.expectedStdoutContains {
+				if !strings.Contains(stdout.String(), want) {
+					t.Errorf("stdout = %q, want substring %q", stdout.String(), want)
+				}
+			}
+			if !strings.Contains(stderr.String(), tc.expectedStderrContains) {
+				t.Errorf("stderr = %q, want substring %q", stderr.String(), tc.expectedStderrContains)
+			}
+			if tc.expectedStderrExcludes != "" && strings.Contains(stderr.String(), tc.expectedStderrExcludes) {
+				t.Errorf("stderr = %q, must not contain %q", stderr.String(), tc.expectedStderrExcludes)
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"rental-profit-api/internal/types"
+)
+
+// inputFormats are the formats bookings can be read in. json is the body of
+// POST /maximize, ndjson one such booking object per line, and csv has a
+// header row naming the same fields.
+var inputFormats = []string{"json", "ndjson", "csv"}
+
+// csvColumns are the columns a CSV file must have; cancel_probability is
+// optional and other columns are ignored.
+var csvColumns = []string{"request_id", "check_in", "nights", "selling_rate", "margin"}
+
+func isInputFormat(format string) bool {
+	return slices.Contains(inputFormats, format)
+}
+
+// readBookingFile reads the booking requests in the file name, or in stdin
+// for "-". With format auto, the format is taken from the file extension or,
+// failing that, from the first character of the content.
+func readBookingFile(name, format string, stdin io.Reader) ([]types.BookingRequest, error) {
+	var r io.Reader = stdin
+	if name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	buffered := bufio.NewReader(r)
+	if format == "auto" {
+		format = detectFormat(name, buffered)
+	}
+
+	var (
+		requests []types.BookingRequest
+		err      error
+	)
+	switch format {
+	case "json":
+		err = json.NewDecoder(buffered).Decode(&requests)
+		if errors.Is(err, io.EOF) {
+			err = nil
+		}
+	case "ndjson":
+		requests, err = readNDJSON(buffered)
+	case "csv":
+		requests, err = readCSV(buffered)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", displayName(name), err)
+	}
+	return requests, nil
+}
+
+// detectFormat guesses the format of a file from its extension, or from the
+// first non-blank character when the extension does not tell.
+func detectFormat(name string, r *bufio.Reader) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json":
+		return "json"
+	case ".ndjson", ".jsonl":
+		return "ndjson"
+	case ".csv":
+		return "csv"
+	}
+	// Peek as far as buffered; a longer run of blanks is not worth guessing
+	// about
+	head, _ := r.Peek(r.Size())
+	switch trimmed := bytes.TrimLeft(head, " \t\r\n"); {
+	case len(trimmed) == 0, trimmed[0] == '[':
+		return "json"
+	case trimmed[0] == '{':
+		return "ndjson"
+	}
+	return "csv"
+}
+
+func readNDJSON(r io.Reader) ([]types.BookingRequest, error) {
+	var requests []types.BookingRequest
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		var request types.BookingRequest
+		if err := json.Unmarshal(text, &request); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		requests = append(requests, request)
+	}
+	return requests, scanner.Err()
+}
+
+func readCSV(r io.Reader) ([]types.BookingRequest, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range csvColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("header has no %s column", name)
+		}
+	}
+
+	var requests []types.BookingRequest
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return requests, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		request, err := csvBooking(record, columns)
+		if err != nil {
+			line, _ := reader.FieldPos(0)
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		requests = append(requests, request)
+	}
+}
+
+// csvBooking maps a CSV record to a booking request by the column indexes of
+// the header. Numbers must parse; their values are left to validation.
+func csvBooking(record []string, columns map[string]int) (types.BookingRequest, error) {
+	field := func(name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	request := types.BookingRequest{RequestID: field("request_id"), Checkin: field("check_in")}
+	nights, err := strconv.Atoi(field("nights"))
+	if err != nil {
+		return request, fmt.Errorf("nights %q is not an integer", field("nights"))
+	}
+	request.Nights = nights
+	for _, number := range []struct {
+		name   string
+		target *float64
+	}{
+		{"selling_rate", &request.SellingRate},
+		{"margin", &request.Margin},
+		{"cancel_probability", &request.CancelProbability},
+	} {
+		text := field(number.name)
+		if text == "" && number.name == "cancel_probability" {
+			continue
+		}
+		value, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return request, fmt.Errorf("%s %q is not a number", number.name, text)
+		}
+		*number.target = value
+	}
+	return request, nil
+}
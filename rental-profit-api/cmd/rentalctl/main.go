@@ -0,0 +1,179 @@
+// Command rentalctl runs the profit maximization and the statistics of the
+// API on local booking exports, without a server:
+//
+//	rentalctl maximize -output csv bookings.csv
+//	cat bookings.ndjson | rentalctl stats
+//
+// The client command sends the same files to a running server instead:
+//
+//	rentalctl client maximize -server https://rentals.example.com bookings.csv
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"rental-profit-api/internal/api"
+	"rental-profit-api/internal/booking"
+)
+
+// command is a rentalctl subcommand. run gets the arguments after the
+// command name.
+type command struct {
+	name    string
+	summary string
+	run     func(args []string, stdin io.Reader, stdout, stderr io.Writer) error
+}
+
+var commands = []command{
+	{"maximize", "find the most profitable schedule", runMaximize},
+	{"stats", "report profit per night statistics", runStats},
+	{"client", "run maximize or stats on a remote server", runClient},
+}
+
+// errUsage reports bad arguments; the flag set has already printed why.
+var errUsage = errors.New("usage error")
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run executes the command line and returns the exit code: 0 on success, 1
+// when the bookings could not be processed and 2 for bad arguments.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "-help" || args[0] == "help" {
+		printUsage(stderr)
+		if len(args) == 0 {
+			return 2
+		}
+		return 0
+	}
+	for _, cmd := range commands {
+		if cmd.name != args[0] {
+			continue
+		}
+		err := cmd.run(args[1:], stdin, stdout, stderr)
+		switch {
+		case err == nil:
+			return 0
+		case errors.Is(err, flag.ErrHelp):
+			return 0
+		case errors.Is(err, errUsage):
+			return 2
+		}
+		fmt.Fprintf(stderr, "rentalctl %s: %v\n", cmd.name, err)
+		return 1
+	}
+	fmt.Fprintf(stderr, "rentalctl: unknown command %q\n\n", args[0])
+	printUsage(stderr)
+	return 2
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: rentalctl <command> [flags] [file ...]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-10s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `Bookings are read from the files, or from stdin without any or for "-".`)
+	fmt.Fprintln(w, `Run "rentalctl <command> -h" for the flags of a command.`)
+}
+
+// localFlags are the flags shared by the commands that compute locally.
+type localFlags struct {
+	input  string
+	output string
+}
+
+// parseLocalFlags parses the flags of a local command and returns the files
+// to read. extra, if not nil, adds the flags specific to the command.
+func parseLocalFlags(name string, args []string, stderr io.Writer, extra func(fs *flag.FlagSet)) (localFlags, []string, error) {
+	var flags localFlags
+	fs := flag.NewFlagSet("rentalctl "+name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&flags.input, "input", "auto", "input format: "+strings.Join(inputFormats, ", ")+", or auto to go by file extension and content")
+	fs.StringVar(&flags.output, "output", "table", "output format: table, json or csv")
+	if extra != nil {
+		extra(fs)
+	}
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: rentalctl %s [flags] [file ...]\n\nFlags:\n", name)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return flags, nil, err
+		}
+		return flags, nil, errUsage
+	}
+	if flags.input != "auto" && !isInputFormat(flags.input) {
+		fmt.Fprintf(stderr, "invalid -input %q\n", flags.input)
+		return flags, nil, errUsage
+	}
+	switch flags.output {
+	case "table", "json", "csv":
+	default:
+		fmt.Fprintf(stderr, "invalid -output %q\n", flags.output)
+		return flags, nil, errUsage
+	}
+	return flags, fs.Args(), nil
+}
+
+func runMaximize(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	flags, files, err := parseLocalFlags("maximize", args, stderr, nil)
+	if err != nil {
+		return err
+	}
+	bookings, err := loadBookings(files, flags.input, stdin)
+	if err != nil {
+		return err
+	}
+	result := booking.FindMaxProfit(bookings)
+	return writeSchedule(stdout, flags.output, len(bookings), result.OptimalSchedule, api.ToMaximizeResponse(result))
+}
+
+func runStats(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	flags, files, err := parseLocalFlags("stats", args, stderr, nil)
+	if err != nil {
+		return err
+	}
+	bookings, err := loadBookings(files, flags.input, stdin)
+	if err != nil {
+		return err
+	}
+	return writeStats(stdout, flags.output, booking.CalculateOverallStats(bookings))
+}
+
+// loadBookings reads the booking requests of every file, or of stdin, and
+// validates them the way the API does. Errors name the file they are in.
+func loadBookings(files []string, format string, stdin io.Reader) ([]booking.Booking, error) {
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+	var bookings []booking.Booking
+	for _, name := range files {
+		requests, err := readBookingFile(name, format, stdin)
+		if err != nil {
+			return nil, err
+		}
+		mapped, err := api.ValidateAndMapBookings(requests)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", displayName(name), err)
+		}
+		bookings = append(bookings, mapped...)
+	}
+	return bookings, nil
+}
+
+func displayName(name string) string {
+	if name == "-" {
+		return "stdin"
+	}
+	return name
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	testJSON = `[{"request_id":"B1","check_in":"2024-01-01","nights":4,"selling_rate":100,"margin":10},
+{"request_id":"B2","check_in":"2024-01-03","nights":2,"selling_rate":150,"margin":20},
+{"request_id":"B3","check_in":"2024-01-06","nights":2,"selling_rate":150,"margin":20}]`
+	testNDJSON = `{"request_id":"B1","check_in":"2024-01-01","nights":4,"selling_rate":100,"margin":10}
+
+{"request_id":"B2","check_in":"2024-01-03","nights":2,"selling_rate":150,"margin":20}
+{"request_id":"B3","check_in":"2024-01-06","nights":2,"selling_rate":150,"margin":20}
+`
+	testCSV = `request_id,check_in,nights,selling_rate,margin,cancel_probability,channel
+B1,2024-01-01,4,100,10,,direct
+B2,2024-01-03,2,150,20,0.1,ota
+B3,2024-01-06,2,150,20,,ota
+`
+)
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	writeFile := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+		return path
+	}
+	csvFile := writeFile("bookings.csv", testCSV)
+	untypedFile := writeFile("bookings.txt", testNDJSON)
+	firstHalf := writeFile("first.json", `[{"request_id":"B1","check_in":"2024-01-01","nights":4,"selling_rate":100,"margin":10}]`)
+	secondHalf := writeFile("second.ndjson", `{"request_id":"B3","check_in":"2024-01-06","nights":2,"selling_rate":150,"margin":20}`)
+	invalidFile := writeFile("invalid.csv", "request_id,check_in,nights,selling_rate,margin\nB1,2024-01-01,0,100,10\n")
+
+	// --- Define Test Scenarios ---
+	testCases := []struct {
+		name                   string
+		args                   []string
+		stdin                  string
+		expectedCode           int
+		expectedStdoutContains []string
+		expectedStderrContains string
+	}{
+		{
+			name:                   "Maximize JSON From Stdin",
+			args:                   []string{"maximize", "-output", "json"},
+			stdin:                  testJSON,
+			expectedStdoutContains: []string{`"request_ids": [`, `"B2"`, `"B3"`, `"total_profit": 60`},
+		},
+		{
+			name:                   "Maximize NDJSON From Stdin As Table",
+			args:                   []string{"maximize"},
+			stdin:                  testNDJSON,
+			expectedStdoutContains: []string{"2024-01-03  2024-01-05  2       30.00", "Selected 2 of 3 bookings for a total profit of 60.00"},
+		},
+		{
+			name:                   "Maximize CSV File As CSV",
+			args:                   []string{"maximize", "-output", "csv", csvFile},
+			expectedStdoutContains: []string{"request_id,check_in,checkout,nights,profit\nB2,2024-01-03,2024-01-05,2,30.00\nB3,2024-01-06,2024-01-08,2,30.00\n"},
+		},
+		{
+			name:                   "Format Detected From Content",
+			args:                   []string{"stats", "-output", "csv", untypedFile},
+			expectedStdoutContains: []string{"avg_night,min_night,max_night\n10.83,2.50,15.00\n"},
+		},
+		{
+			name:                   "Files Are Combined",
+			args:                   []string{"maximize", "-output", "json", firstHalf, secondHalf},
+			expectedStdoutContains: []string{`"B1",`, `"total_profit": 40`},
+		},
+		{
+			name:                   "Explicit Input Format",
+			args:                   []string{"stats", "-input", "csv", "-"},
+			stdin:                  testCSV,
+			expectedStdoutContains: []string{"10.83      2.50       15.00"},
+		},
+		{
+			name:                   "Validation Names The File",
+			args:                   []string{"stats", invalidFile},
+			expectedCode:           1,
+			expectedStderrContains: "invalid.csv: validation error: nights must be positive on item 0",
+		},
+		{
+			name:                   "Malformed CSV Number",
+			args:                   []string{"maximize", "-input", "csv"},
+			stdin:                  "request_id,check_in,nights,selling_rate,margin\nB1,2024-01-01,four,100,10\n",
+			expectedCode:           1,
+			expectedStderrContains: `stdin: line 2: nights "four" is not an integer`,
+		},
+		{
+			name:                   "Missing CSV Column",
+			args:                   []string{"maximize", "-input", "csv"},
+			stdin:                  "request_id,check_in,nights,selling_rate\n",
+			expectedCode:           1,
+			expectedStderrContains: "header has no margin column",
+		},
+		{
+			name:                   "Malformed NDJSON Line",
+			args:                   []string{"maximize", "-input", "ndjson"},
+			stdin:                  `{"request_id":"B1"}` + "\n{",
+			expectedCode:           1,
+			expectedStderrContains: "stdin: line 2:",
+		},
+		{
+			name:                   "Missing File",
+			args:                   []string{"stats", filepath.Join(dir, "missing.json")},
+			expectedCode:           1,
+			expectedStderrContains: "missing.json",
+		},
+		{
+			name:                   "Invalid Output Format",
+			args:                   []string{"stats", "-output", "xml"},
+			expectedCode:           2,
+			expectedStderrContains: `invalid -output "xml"`,
+		},
+		{
+			name:                   "Unknown Command",
+			args:                   []string{"optimize"},
+			expectedCode:           2,
+			expectedStderrContains: `unknown command "optimize"`,
+		},
+		{
+			name:                   "No Command",
+			expectedCode:           2,
+			expectedStderrContains: "Usage: rentalctl <command>",
+		},
+	}
+
+	// --- Execute Scenarios ---
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := run(tc.args, strings.NewReader(tc.stdin), &stdout, &stderr)
+
+			if code != tc.expectedCode {
+				t.Errorf("run() = %d, want %d. Stderr: %s", code, tc.expectedCode, stderr.String())
+			}
+			for _, want := range tc.expectedStdoutContains {
+				if !strings.Contains(stdout.String(), want) {
+					t.Errorf("stdout = %q, want substring %q", stdout.String(), want)
+				}
+			}
+			if !strings.Contains(stderr.String(), tc.expectedStderrContains) {
+				t.Errorf("stderr = %q, want substring %q", stderr.String(), tc.expectedStderrContains)
+			}
+		})
+	}
+}
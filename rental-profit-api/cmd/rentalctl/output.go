@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"rental-profit-api/internal/booking"
+	"rental-profit-api/internal/types"
+)
+
+// writeSchedule prints the schedule selected out of total bookings. json is
+// the /maximize response; table and csv list the selected bookings, and the
+// table adds a timeline and the totals.
+func writeSchedule(w io.Writer, format string, total int, selected []booking.Booking, response types.MaximizeResponse) error {
+	switch format {
+	case "json":
+		return writeJSON(w, response)
+	case "csv":
+		return writeCSV(w, scheduleRows(selected))
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, row := range scheduleRows(selected) {
+		for i, cell := range row {
+			if i > 0 {
+				fmt.Fprint(tw, "\t")
+			}
+			fmt.Fprint(tw, cell)
+		}
+		fmt.Fprintln(tw)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if len(selected) > 0 {
+		fmt.Fprintln(w)
+		writeTimeline(w, selected)
+	}
+	_, err := fmt.Fprintf(w, "\nSelected %d of %d bookings for a total profit of %s\nProfit per night: avg %s, min %s, max %s\n",
+		len(response.RequestIDs), total, formatAmount(response.TotalProfit),
+		formatAmount(response.AvgNight), formatAmount(response.MinNight), formatAmount(response.MaxNight))
+	return err
+}
+
+// timelineWidth is the most columns a timeline takes; longer schedules put
+// several days in a column.
+const timelineWidth = 60
+
+// writeTimeline draws the stays of a schedule on a shared day axis, one row
+// per booking in check-in order:
+//
+//	Timeline 2024-01-03 to 2024-01-08, 1 day per column
+//	B2  |==   |
+//	B3  |   ==|
+func writeTimeline(w io.Writer, schedule []booking.Booking) {
+	schedule = slices.SortedFunc(slices.Values(schedule), func(a, b booking.Booking) int {
+		return a.Checkin.Compare(b.Checkin)
+	})
+	first := schedule[0].Checkin
+	last := first
+	labelWidth := 0
+	for _, b := range schedule {
+		last = latest(last, booking.CalculateCheckout(b.Checkin, b.Nights))
+		labelWidth = max(labelWidth, len(b.RequestID))
+	}
+	days := daysBetween(first, last)
+	perColumn := (days + timelineWidth - 1) / timelineWidth
+	columns := (days + perColumn - 1) / perColumn
+
+	unit := "day"
+	if perColumn > 1 {
+		unit = "days"
+	}
+	fmt.Fprintf(w, "Timeline %s to %s, %d %s per column\n",
+		first.Format(booking.DateLayout), last.Format(booking.DateLayout), perColumn, unit)
+	for _, b := range schedule {
+		start := daysBetween(first, b.Checkin)
+		from := start / perColumn
+		to := (start + b.Nights + perColumn - 1) / perColumn
+		bar := strings.Repeat(" ", from) + strings.Repeat("=", to-from) + strings.Repeat(" ", columns-to)
+		fmt.Fprintf(w, "%-*s  |%s|\n", labelWidth, b.RequestID, bar)
+	}
+}
+
+func daysBetween(from, to time.Time) int {
+	return int(to.Sub(from).Round(24*time.Hour) / (24 * time.Hour))
+}
+
+func latest(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+// scheduleRows lists the bookings of a schedule under a header row.
+func scheduleRows(schedule []booking.Booking) [][]string {
+	rows := [][]string{{"request_id", "check_in", "checkout", "nights", "profit"}}
+	for _, b := range schedule {
+		rows = append(rows, []string{
+			b.RequestID,
+			b.Checkin.Format(booking.DateLayout),
+			booking.CalculateCheckout(b.Checkin, b.Nights).Format(booking.DateLayout),
+			strconv.Itoa(b.Nights),
+			formatAmount(booking.CalculateProfit(b.SellingRate, b.Margin, b.Nights)),
+		})
+	}
+	return rows
+}
+
+// writeStats prints profit per night statistics; json is the /stats
+// response.
+func writeStats(w io.Writer, format string, stats types.StatsResponse) error {
+	if format == "json" {
+		return writeJSON(w, stats)
+	}
+	rows := [][]string{
+		{"avg_night", "min_night", "max_night"},
+		{formatAmount(stats.AvgProfitPerNight), formatAmount(stats.MinProfitPerNight), formatAmount(stats.MaxProfitPerNight)},
+	}
+	if format == "csv" {
+		return writeCSV(w, rows)
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, row := range rows {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", row[0], row[1], row[2])
+	}
+	return tw.Flush()
+}
+
+func writeJSON(w io.Writer, value any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+func writeCSV(w io.Writer, rows [][]string) error {
+	writer := csv.NewWriter(w)
+	writer.WriteAll(rows)
+	return writer.Error()
+}
+
+// formatAmount prints money with cents, as the API rounds it.
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
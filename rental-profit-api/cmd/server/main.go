@@ -1,40 +1,421 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
+	"runtime"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc"
 
 	"rental-profit-api/internal/api"
+	"rental-profit-api/internal/booking"
+	"rental-profit-api/internal/cache"
+	"rental-profit-api/internal/config"
+	"rental-profit-api/internal/idempotency"
+	"rental-profit-api/internal/jobs"
+	"rental-profit-api/internal/ratelimit"
+	"rental-profit-api/internal/rentalpb"
+	"rental-profit-api/internal/store"
+	"rental-profit-api/internal/types"
+	"rental-profit-api/internal/webhooks"
 )
 
 func main() {
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		Level:     slog.LevelInfo,
-		AddSource: true,
-	}))
-	slog.SetDefault(logger) 
+	// --- Configuration ---
+	cfg, err := config.Load(os.Args[1:], os.Getenv)
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	slog.SetDefault(newLogger(cfg.Log))
 
 	slog.Info("Initializing server...")
+	slog.Info("Effective configuration", "config", cfg)
+
+	api.DefaultOverbookingPolicy = overbookingPolicy(cfg.Overbooking)
+	api.DefaultLimits = apiLimits(cfg.Limits)
+
+	api.ComputeBudget = time.Duration(cfg.Compute.Budget)
+	api.DefaultStrictJSON = cfg.JSON.Strict
+	maxConcurrent := int(cfg.Compute.MaxConcurrent)
+	if maxConcurrent == 0 {
+		maxConcurrent = runtime.NumCPU()
+	}
+	api.ComputeSlots = ratelimit.NewSemaphore(maxConcurrent, int(cfg.Compute.MaxQueued))
+	if cfg.Cache.MaxBytes > 0 {
+		api.ResultCache = cache.NewLRU[string, any](cfg.Cache.MaxBytes)
+	}
+
+	// --- Rate Limiting ---
+	limiter := newLimiter(cfg.RateLimit)
+
+	// --- Authentication ---
+	var apiKeys *api.APIKeys
+	if cfg.Auth.KeysFile != "" {
+		keys, overrides, err := config.LoadAPIKeys(cfg.Auth.KeysFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		tenantsByKey := make(map[string]string, len(keys))
+		for _, key := range keys {
+			tenantsByKey[key.Key] = key.Tenant
+		}
+		apiKeys = api.NewAPIKeys(tenantsByKey)
+		api.Tenants = make(map[string]api.TenantSettings, len(overrides))
+		for tenant, override := range overrides {
+			tenantCfg, err := cfg.ForTenant(tenant, override)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(2)
+			}
+			// Tenants keep the shared limiter unless they change its rate
+			tenantLimiter := limiter
+			if tenantCfg.RateLimit != cfg.RateLimit {
+				tenantLimiter = newLimiter(tenantCfg.RateLimit)
+			}
+			api.Tenants[tenant] = api.TenantSettings{
+				Limits:            apiLimits(tenantCfg.Limits),
+				OverbookingPolicy: overbookingPolicy(tenantCfg.Overbooking),
+				StrictJSON:        tenantCfg.JSON.Strict,
+				RateLimiter:       tenantLimiter,
+			}
+		}
+		slog.Info("API key authentication enabled", "keys", len(keys), "tenants_with_overrides", len(overrides))
+	} else {
+		slog.Warn("API key authentication disabled; every client shares one tenant")
+	}
+
+	// Every route but the probes and /metrics requires an API key, is rate
+	// limited per key and only takes JSON bodies. Patterns name the method,
+	// so other methods get 405 from the mux.
+	authenticate := api.Authenticate(apiKeys)
+	rateLimit := api.RateLimit(limiter)
+	protect := func(handler http.Handler) http.Handler {
+		return authenticate(rateLimit(api.RequireJSON(handler)))
+	}
+	handle := func(pattern string, handler http.HandlerFunc) {
+		http.Handle(pattern, protect(handler))
+	}
+	// Routes that change stored state replay their response to retries
+	// carrying the same Idempotency-Key
+	var idempotencyStore *idempotency.Store
+	if cfg.Idempotency.TTL > 0 {
+		idempotencyStore = idempotency.NewStore(time.Duration(cfg.Idempotency.TTL), cfg.Idempotency.MaxBytes)
+	}
+	idempotent := api.Idempotent(idempotencyStore)
+	handleStateful := func(pattern string, handler http.HandlerFunc) {
+		http.Handle(pattern, protect(idempotent(handler)))
+	}
+	// The core endpoints are also served under /v1, unchanged, and under /v2,
+	// where every response, including auth and rate limit errors, comes in
+	// the envelope and bodies are decoded strictly unless configured otherwise
+	strictV2 := api.StrictJSON(cfg.JSON.StrictV2)
+	handleVersioned := func(method, path string, handler http.HandlerFunc) {
+		handle(method+" "+path, handler)
+		handle(method+" /v1"+path, handler)
+		http.Handle(method+" /v2"+path, api.Envelope(strictV2(protect(handler))))
+		slog.Info("Registered handler for endpoint", "path", method+" "+path, "versions", []string{"v1", "v2"})
+	}
 
 	// --- HTTP Route Registration ---
-	http.HandleFunc("/maximize", api.MaximizeProfitHandler)
-	slog.Info("Registered handler for endpoint", "path", "/maximize")
+	health := api.NewHealth()
+	http.HandleFunc("GET /healthz", health.Liveness)
+	http.HandleFunc("GET /readyz", health.Readiness)
+	http.HandleFunc("GET /version", api.VersionHandler)
+	slog.Info("Registered health endpoints", "paths", []string{"/healthz", "/readyz", "/version"})
+
+	http.HandleFunc("GET /metrics", api.MetricsHandler)
+	slog.Info("Registered handler for endpoint", "path", "/metrics")
+
+	// The spec is public so clients can be generated before a key is issued
+	http.HandleFunc("GET /openapi.json", api.OpenAPIHandler)
+	slog.Info("Registered handler for endpoint", "path", "/openapi.json")
+
+	handleVersioned(http.MethodPost, "/maximize", api.MaximizeProfitHandler)
+	handleVersioned(http.MethodPost, "/stats", api.StatsHandler)
+
+	if cfg.Features.Compare {
+		handle("POST /compare", api.CompareHandler)
+		slog.Info("Registered handler for endpoint", "path", "POST /compare")
+	}
+
+	if cfg.Features.ScheduleValidate {
+		handle("POST /schedule/validate", api.ValidateScheduleHandler)
+		slog.Info("Registered handler for endpoint", "path", "POST /schedule/validate")
+	}
+
+	if cfg.Features.Quote {
+		handle("POST /quote", api.QuoteHandler)
+		slog.Info("Registered handler for endpoint", "path", "POST /quote")
+	}
+
+	// Handlers that emit events publish through this; it stays nil, and
+	// publishing a no-op, while webhooks are disabled
+	var events api.EventPublisher
+	var webhookService *webhooks.Service
+	if cfg.Features.Webhooks {
+		webhookService = webhooks.NewService(webhooks.Options{
+			Workers:        int(cfg.Webhooks.Workers),
+			QueueSize:      int(cfg.Webhooks.QueueSize),
+			MaxAttempts:    int(cfg.Webhooks.MaxAttempts),
+			InitialBackoff: time.Duration(cfg.Webhooks.InitialBackoff),
+			MaxBackoff:     time.Duration(cfg.Webhooks.MaxBackoff),
+			Timeout:        time.Duration(cfg.Webhooks.Timeout),
+			LogSize:        int(cfg.Webhooks.LogSize),
+			MaxPerTenant:   int(cfg.Webhooks.MaxPerTenant),
 
-	http.HandleFunc("/stats", api.StatsHandler)
-	slog.Info("Registered handler for endpoint", "path", "/stats")
+			AllowPrivateNetworks: cfg.Webhooks.AllowPrivateNetworks,
+		})
+		events = webhookService
+		webhookHandler := api.NewWebhookHandler(webhookService)
+		webhookRoutes := []struct {
+			pattern string
+			handler http.HandlerFunc
+		}{
+			{"POST /webhooks", webhookHandler.Create},
+			{"GET /webhooks", webhookHandler.List},
+			{"GET /webhooks/{id}", webhookHandler.Get},
+			{"DELETE /webhooks/{id}", webhookHandler.Delete},
+			{"GET /webhooks/{id}/deliveries", webhookHandler.Deliveries},
+		}
+		for _, route := range webhookRoutes {
+			handleStateful(route.pattern, route.handler)
+			slog.Info("Registered handler for endpoint", "path", route.pattern)
+		}
+	}
+
+	if cfg.Features.Calendars {
+		calendarStore := store.NewCalendarStore()
+		health.AddCheck("calendar_store", calendarStore.Ping)
+		calendars := api.NewCalendarHandler(calendarStore, events)
+		calendarRoutes := []struct {
+			pattern string
+			handler http.HandlerFunc
+		}{
+			{"POST /calendars", calendars.Create},
+			{"GET /calendars/{id}", calendars.Get},
+			{"DELETE /calendars/{id}", calendars.Delete},
+			{"POST /calendars/{id}/bookings", calendars.AddBooking},
+			{"PUT /calendars/{id}/bookings/{request_id}", calendars.UpdateBooking},
+			{"DELETE /calendars/{id}/bookings/{request_id}", calendars.CancelBooking},
+		}
+		for _, route := range calendarRoutes {
+			handleStateful(route.pattern, route.handler)
+			slog.Info("Registered handler for endpoint", "path", route.pattern)
+		}
+	}
+
+	var jobManager *jobs.Manager[types.MaximizeResponse]
+	if cfg.Features.Jobs {
+		jobManager = jobs.NewManager[types.MaximizeResponse](jobs.Options{
+			Workers:   int(cfg.Jobs.Workers),
+			QueueSize: int(cfg.Jobs.QueueSize),
+			TTL:       time.Duration(cfg.Jobs.TTL),
+			Timeout:   time.Duration(cfg.Jobs.Timeout),
+		})
+		health.AddCheck("jobs", jobManager.Ping)
+		jobHandler := api.NewJobHandler(jobManager, events)
+		handleStateful("POST /jobs/maximize", jobHandler.SubmitMaximize)
+		handle("GET /jobs/{id}", jobHandler.Get)
+		slog.Info("Registered job endpoints", "paths", []string{"POST /jobs/maximize", "GET /jobs/{id}"})
+	}
+
+	// --- Middleware ---
+	handler := api.Chain(api.RouteErrors(http.DefaultServeMux),
+		api.RequestID,
+		api.AccessLog(slog.Default()),
+		api.InstrumentHandler,
+		api.Recover(slog.Default()),
+	)
 
 	// --- Server Configuration ---
-	port := "8080"
-	addr := ":" + port
-	slog.Info("Server starting", "address", addr)
+	server := &http.Server{
+		Addr:              cfg.ListenAddr,
+		Handler:           http.MaxBytesHandler(handler, cfg.HTTP.MaxBodyBytes),
+		ReadTimeout:       time.Duration(cfg.HTTP.ReadTimeout),
+		ReadHeaderTimeout: time.Duration(cfg.HTTP.ReadHeaderTimeout),
+		WriteTimeout:      time.Duration(cfg.HTTP.WriteTimeout),
+		IdleTimeout:       time.Duration(cfg.HTTP.IdleTimeout),
+		MaxHeaderBytes:    int(cfg.HTTP.MaxHeaderBytes),
+		ErrorLog:          slog.NewLogLogger(slog.Default().Handler(), slog.LevelWarn),
+	}
+
+	listener, err := net.Listen("tcp", server.Addr)
+	if err != nil {
+		slog.Error("Server failed to start", "error", err)
+		os.Exit(1)
+	}
+	slog.Info("Server starting", "address", listener.Addr().String())
+
+	// The gRPC service shares the handlers' validation, limits and API keys
+	var grpcServer *grpc.Server
+	if cfg.GRPC.ListenAddr != "" {
+		grpcListener, err := net.Listen("tcp", cfg.GRPC.ListenAddr)
+		if err != nil {
+			slog.Error("gRPC server failed to start", "error", err)
+			os.Exit(1)
+		}
+		grpcServer = grpc.NewServer(api.GRPCServerOptions(slog.Default(), apiKeys, limiter)...)
+		rentalpb.RegisterRentalServiceServer(grpcServer, api.NewGRPCServer(limiter))
+		slog.Info("gRPC server starting", "address", grpcListener.Addr().String())
+		go func() {
+			if err := grpcServer.Serve(grpcListener); err != nil {
+				slog.Error("gRPC server stopped with error", "error", err)
+			}
+		}()
+	}
 
 	// --- Start Server ---
-	err := http.ListenAndServe(addr, nil)
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	policy := shutdownPolicy{
+		onShutdown: health.SetShuttingDown,
+		delay:      time.Duration(cfg.HTTP.ShutdownDelay),
+		timeout:    time.Duration(cfg.HTTP.ShutdownTimeout),
+	}
+	// gRPC stops taking calls as soon as the signal arrives and drains
+	// alongside HTTP, under the same deadline
+	if grpcServer != nil {
+		policy.alongside = append(policy.alongside, drainGRPC(grpcServer))
+	}
+	err = serve(ctx, server, listener, policy)
+	// Running jobs are cancelled; nobody could collect their results anyway
+	if jobManager != nil {
+		jobManager.Close()
+	}
+	// Pending webhook retries are dropped; deliveries are best effort
+	if webhookService != nil {
+		webhookService.Close()
+	}
 
 	// --- Error Handling ---
 	if err != nil {
-		slog.Error("Server failed to start", "error", err)
+		slog.Error("Server stopped with error", "error", err)
 		os.Exit(1)
 	}
-}
\ No newline at end of file
+	slog.Info("Server stopped")
+}
+
+// shutdownPolicy controls how serve winds down once asked to stop. The
+// whole shutdown takes at most delay plus timeout.
+type shutdownPolicy struct {
+	onShutdown func()        // Called as soon as shutdown starts, e.g. to fail readiness
+	delay      time.Duration // Keep serving this long so load balancers notice
+	timeout    time.Duration // Then give in-flight requests this long to finish
+	// Drained from the moment shutdown starts, concurrently with the HTTP
+	// server; each must return once its context is done
+	alongside []func(ctx context.Context) error
+}
+
+// serve runs server on listener until ctx is cancelled, then stops accepting
+// connections and lets in-flight requests drain as the policy says before
+// closing whatever is left.
+func serve(ctx context.Context, server *http.Server, listener net.Listener, policy shutdownPolicy) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.Serve(listener)
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	slog.Info("Shutdown signal received", "delay", policy.delay.String())
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), policy.delay+policy.timeout)
+	defer cancel()
+	alongsideErr := make(chan error, len(policy.alongside))
+	for _, drain := range policy.alongside {
+		go func() {
+			alongsideErr <- drain(shutdownCtx)
+		}()
+	}
+	if policy.onShutdown != nil {
+		policy.onShutdown()
+	}
+	time.Sleep(policy.delay)
+
+	slog.Info("Draining in-flight requests", "timeout", policy.timeout.String())
+	err := server.Shutdown(shutdownCtx)
+	if err != nil {
+		server.Close()
+		err = fmt.Errorf("graceful shutdown did not complete: %w", err)
+	} else if serveErr := <-serveErr; !errors.Is(serveErr, http.ErrServerClosed) {
+		err = serveErr
+	}
+	for range policy.alongside {
+		err = errors.Join(err, <-alongsideErr)
+	}
+	return err
+}
+
+// drainGRPC stops server from taking new calls and lets in-flight ones
+// finish until ctx is done, then closes whatever is left.
+func drainGRPC(server *grpc.Server) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		stopped := make(chan struct{})
+		go func() {
+			server.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+			return nil
+		case <-ctx.Done():
+			server.Stop()
+			return fmt.Errorf("gRPC graceful shutdown did not complete: %w", ctx.Err())
+		}
+	}
+}
+
+func apiLimits(limits config.LimitsConfig) api.Limits {
+	return api.Limits{
+		MaxBookings:     int(limits.MaxBookings),
+		MaxNights:       int(limits.MaxNights),
+		MaxDateSpanDays: int(limits.MaxDateSpanDays),
+		MaxSellingRate:  limits.MaxSellingRate,
+	}
+}
+
+func overbookingPolicy(overbooking config.OverbookingConfig) booking.OverbookingPolicy {
+	return booking.OverbookingPolicy{
+		MaxWalkProbability: overbooking.MaxWalkProbability,
+		WalkPenalty:        overbooking.WalkPenalty,
+	}
+}
+
+// newLimiter returns the limiter for rateLimit, or nil if it is disabled.
+func newLimiter(rateLimit config.RateLimitConfig) *ratelimit.Limiter {
+	if rateLimit.RequestsPerSecond <= 0 {
+		return nil
+	}
+	return ratelimit.NewLimiter(rateLimit.RequestsPerSecond, int(rateLimit.Burst))
+}
+
+func newLogger(logConfig config.LogConfig) *slog.Logger {
+	// The level was checked by config.Validate
+	level, _ := logConfig.SlogLevel()
+	options := &slog.HandlerOptions{
+		Level:     level,
+		AddSource: true,
+	}
+	if logConfig.Format == "text" {
+		return slog.New(slog.NewTextHandler(os.Stdout, options))
+	}
+	return slog.New(slog.NewJSONHandler(os.Stdout, options))
+}
@@ -25,8 +25,8 @@ func startTestServer(t *testing.T) *testServer {
 	t.Helper()
 
 	mux := http.NewServeMux()
-	mux.HandleFunc("/maximize", api.MaximizeProfitHandler)
-	mux.HandleFunc("/stats", api.StatsHandler)
+	mux.HandleFunc("POST /maximize", api.MaximizeProfitHandler)
+	mux.HandleFunc("POST /stats", api.StatsHandler)
 	server := httptest.NewServer(mux)
 
 	return &testServer{server}
@@ -59,6 +59,7 @@ func TestMaximizeE2E(t *testing.T) {
 	}{
 		{
 			name:           "Success Case - Optimal Schedule Found",
+			requestMethod:  http.MethodPost,
 			payload: []types.BookingRequest{
 				{RequestID: "B1", Checkin: "2024-01-01", Nights: 4, SellingRate: 100, Margin: 10},
 				{RequestID: "B_overlap", Checkin: "2024-01-03", Nights: 3, SellingRate: 50, Margin: 10},
@@ -76,24 +77,28 @@ func TestMaximizeE2E(t *testing.T) {
 		},
 		{
 			name:           "Validation Error - Missing Field",
+			requestMethod:  http.MethodPost,
 			payload:        []types.BookingRequest{{Checkin: "2024-01-01", Nights: 1, SellingRate: 10, Margin: 10}},
 			expectedStatus: http.StatusBadRequest,
 			expectedErrMsgContains: "request_id missing",
 		},
 		{
 			name:           "Validation Error - Invalid Date",
+			requestMethod:  http.MethodPost,
 			payload:        []types.BookingRequest{{RequestID: "E1", Checkin: "invalid-date", Nights: 1, SellingRate: 10, Margin: 10}},
 			expectedStatus: http.StatusBadRequest,
 			expectedErrMsgContains: "check_in format error",
 		},
 		{
 			name:           "Validation Error - Non-positive Nights",
+			requestMethod:  http.MethodPost,
 			payload:        []types.BookingRequest{{RequestID: "E2", Checkin: "2024-01-01", Nights: 0, SellingRate: 10, Margin: 10}},
 			expectedStatus: http.StatusBadRequest,
 			expectedErrMsgContains: "nights must be positive",
 		},
 		{
 			name:           "Validation Error - Non-positive selling rate",
+			requestMethod:  http.MethodPost,
 			payload: []types.BookingRequest{
 				{RequestID: "Z1", Checkin: "2024-01-01", Nights: 4, SellingRate: 100, Margin: 10},
 				{RequestID: "Z2", Checkin: "2024-01-06", Nights: 2, SellingRate: -50, Margin: 20},
@@ -103,6 +108,7 @@ func TestMaximizeE2E(t *testing.T) {
 		},
 		{
 			name:           "Validation Error - Zero margin",
+			requestMethod:  http.MethodPost,
 			payload: []types.BookingRequest{
 				{RequestID: "Z1", Checkin: "2024-01-01", Nights: 4, SellingRate: 100, Margin: 0},
 				{RequestID: "Z2", Checkin: "2024-01-06", Nights: 2, SellingRate: -50, Margin: 20},
@@ -112,12 +118,14 @@ func TestMaximizeE2E(t *testing.T) {
 		},
 		{
 			name:           "Bad Request - Invalid JSON",
+			requestMethod:  http.MethodPost,
 			payload:        `{"bad json": this is not valid}`,
 			expectedStatus: http.StatusBadRequest,
 			expectedErrMsgContains: "Invalid JSON format",
 		},
 		{
             name:           "Bad Request - Not An Array",
+            requestMethod:  http.MethodPost,
             payload:        `{"request_id":"B1","check_in":"2024-01-01","nights":4,"selling_rate":100,"margin":10}`,
             expectedStatus: http.StatusBadRequest,
             expectedErrMsgContains: "cannot unmarshal",
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// startServing runs serve on a local listener with a handler that signals
+// when a request arrives and then waits for release.
+func startServing(t *testing.T, policy shutdownPolicy) (url string, arrived, release chan struct{}, cancel context.CancelFunc, done chan error) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+
+	arrived = make(chan struct{})
+	release = make(chan struct{})
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(arrived)
+		<-release
+		io.WriteString(w, "done")
+	})}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done = make(chan error, 1)
+	go func() {
+		done <- serve(ctx, server, listener, policy)
+	}()
+	return "http://" + listener.Addr().String(), arrived, release, cancel, done
+}
+
+func TestServeDrainsInFlightRequests(t *testing.T) {
+	shutdownStarted := make(chan struct{})
+	url, arrived, release, cancel, done := startServing(t, shutdownPolicy{
+		onShutdown: func() { close(shutdownStarted) },
+		timeout:    5 * time.Second,
+	})
+
+	response := make(chan string, 1)
+	go func() {
+		resp, err := http.Get(url)
+		if err != nil {
+			response <- err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		response <- string(body)
+	}()
+
+	<-arrived
+	cancel()
+	<-shutdownStarted
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+
+	if got := <-response; got != "done" {
+		t.Errorf("in-flight request got %q, want it to complete with %q", got, "done")
+	}
+	if err := <-done; err != nil {
+		t.Errorf("serve() unexpected error: %v", err)
+	}
+}
+
+func TestServeShutdownDeadline(t *testing.T) {
+	url, arrived, release, cancel, done := startServing(t, shutdownPolicy{timeout: 50 * time.Millisecond})
+	defer close(release)
+
+	go http.Get(url)
+	<-arrived
+	cancel()
+
+	err := <-done
+	if err == nil || !strings.Contains(err.Error(), "graceful shutdown did not complete") {
+		t.Errorf("serve() error = %v, want the shutdown deadline to be reported", err)
+	}
+}
+
+// TestServeShutdownBound checks that what drains alongside HTTP starts as
+// soon as shutdown does and that both share one deadline of delay plus
+// timeout, rather than draining one after the other.
+func TestServeShutdownBound(t *testing.T) {
+	const delay, timeout = 100 * time.Millisecond, 100 * time.Millisecond
+	drainStarted := make(chan time.Time, 1)
+	url, arrived, release, cancel, done := startServing(t, shutdownPolicy{
+		delay:   delay,
+		timeout: timeout,
+		alongside: []func(ctx context.Context) error{func(ctx context.Context) error {
+			drainStarted <- time.Now()
+			<-ctx.Done()
+			return ctx.Err()
+		}},
+	})
+	defer close(release)
+
+	go http.Get(url)
+	<-arrived
+	start := time.Now()
+	cancel()
+
+	err := <-done
+	elapsed := time.Since(start)
+	if started := (<-drainStarted).Sub(start); started >= delay {
+		t.Errorf("alongside drain started %v after the signal, want before the %v delay ends", started, delay)
+	}
+	if elapsed > delay+timeout+150*time.Millisecond {
+		t.Errorf("shutdown took %v, want about %v", elapsed, delay+timeout)
+	}
+	if err == nil || !strings.Contains(err.Error(), "graceful shutdown did not complete") || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("serve() error = %v, want both the HTTP and the alongside deadline reported", err)
+	}
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// APIKeyHeader carries the API key. "Authorization: Bearer <key>" is
+// accepted as well.
+const APIKeyHeader = "X-API-Key"
+
+// APIKeys maps API keys to the tenant they belong to. Keys are held as
+// SHA-256 digests, so looking one up does not reveal through timing how
+// much of a guessed key was right.
+type APIKeys struct {
+	tenants map[[sha256.Size]byte]string
+}
+
+func NewAPIKeys(tenantsByKey map[string]string) *APIKeys {
+	keys := &APIKeys{tenants: make(map[[sha256.Size]byte]string, len(tenantsByKey))}
+	for key, tenant := range tenantsByKey {
+		keys.tenants[sha256.Sum256([]byte(key))] = tenant
+	}
+	return keys
+}
+
+// lookup returns the key's tenant and an ID naming the key in logs and rate
+// limits without revealing it.
+func (k *APIKeys) lookup(key string) (tenant, keyID string, ok bool) {
+	digest := sha256.Sum256([]byte(key))
+	tenant, ok = k.tenants[digest]
+	return tenant, hex.EncodeToString(digest[:8]), ok
+}
+
+type tenantKey struct{}
+
+type apiKeyIDKey struct{}
+
+// TenantFromContext returns the tenant authenticated by Authenticate. It is
+// "" while the API is open, which every stored resource then shares.
+func TenantFromContext(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantKey{}).(string)
+	return tenant
+}
+
+// Authenticate rejects requests without a known API key with 401 and stores
+// the key's tenant in the request context. With nil keys every request is
+// let through for the "" tenant.
+//
+// Wrap the protected routes with it rather than the whole mux: probes stay
+// public, and the middlewares labelling by route still see the pattern even
+// though Authenticate replaces the request.
+func Authenticate(keys *APIKeys) Middleware {
+	return func(next http.Handler) http.Handler {
+		if keys == nil {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := apiKeyFromRequest(r)
+			if key == "" {
+				authFailures.Inc("missing_key")
+				respondUnauthorized(w, "Missing API key")
+				return
+			}
+			tenant, keyID, ok := keys.lookup(key)
+			if !ok {
+				authFailures.Inc("invalid_key")
+				respondUnauthorized(w, "Invalid API key")
+				return
+			}
+			ctx := context.WithValue(r.Context(), tenantKey{}, tenant)
+			ctx = context.WithValue(ctx, apiKeyIDKey{}, keyID)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func apiKeyFromRequest(r *http.Request) string {
+	if key := r.Header.Get(APIKeyHeader); key != "" {
+		return key
+	}
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if ok && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+	return ""
+}
+
+func respondUnauthorized(w http.ResponseWriter, message string) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="rental-profit-api"`)
+	respondError(w, http.StatusUnauthorized, message)
+}
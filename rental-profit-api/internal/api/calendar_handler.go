@@ -0,0 +1,257 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"rental-profit-api/internal/booking"
+	"rental-profit-api/internal/store"
+	"rental-profit-api/internal/types"
+)
+
+// CalendarHandler serves stored calendars whose optimum is kept up to date
+// incrementally as single bookings arrive, change or get cancelled. Every
+// committed change is published as a calendar.changed event.
+type CalendarHandler struct {
+	store  *store.CalendarStore
+	events EventPublisher
+}
+
+// NewCalendarHandler returns a handler backed by calendarStore. events may be
+// nil when nobody subscribes to calendar changes.
+func NewCalendarHandler(calendarStore *store.CalendarStore, events EventPublisher) *CalendarHandler {
+	if events == nil {
+		events = noEvents{}
+	}
+	return &CalendarHandler{store: calendarStore, events: events}
+}
+
+// Create handles POST /calendars with the same payload as /maximize.
+func (h *CalendarHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var bookingRequest []types.BookingRequest
+	err := decodeJSONList(r, &bookingRequest)
+	if err != nil {
+		respondDecodeError(w, err)
+		return
+	}
+	defer r.Body.Close()
+
+	// Validate the request content and format
+	domainBookings, err := limitsFor(r.Context()).validateAndMapBookings(bookingRequest)
+	if err != nil {
+		respondCalendarError(w, err)
+		return
+	}
+
+	ctx, cancel := withComputeBudget(r.Context())
+	defer cancel()
+	calendar, err := runComputation(ctx, func(ctx context.Context) (*booking.Calendar, error) {
+		return booking.NewCalendarContext(ctx, domainBookings)
+	})
+	if errors.Is(err, booking.ErrDuplicateRequestID) {
+		respondError(w, http.StatusBadRequest, fmt.Sprintf("%v: %v", ErrValidation, err))
+		return
+	}
+	if err != nil {
+		respondComputeError(w, err)
+		return
+	}
+
+	calendarID, err := h.store.Create(TenantFromContext(r.Context()), calendar)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, toCalendarResponse(calendarID, calendar))
+}
+
+// Get handles GET /calendars/{id}.
+func (h *CalendarHandler) Get(w http.ResponseWriter, r *http.Request) {
+	calendarID := r.PathValue("id")
+	var response types.CalendarResponse
+	err := h.store.View(TenantFromContext(r.Context()), calendarID, func(calendar *booking.Calendar) error {
+		response = toCalendarResponse(calendarID, calendar)
+		return nil
+	})
+	if err != nil {
+		respondCalendarError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, response)
+}
+
+// Delete handles DELETE /calendars/{id}.
+func (h *CalendarHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if err := h.store.Delete(TenantFromContext(r.Context()), r.PathValue("id")); err != nil {
+		respondCalendarError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AddBooking handles POST /calendars/{id}/bookings. With ?dry_run=true the
+// booking is only evaluated, which answers an inquiry without storing it.
+func (h *CalendarHandler) AddBooking(w http.ResponseWriter, r *http.Request) {
+	dryRun := false
+	if value := r.URL.Query().Get("dry_run"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, fmt.Sprintf("%v: dry_run must be a boolean", ErrValidation))
+			return
+		}
+		dryRun = parsed
+	}
+
+	newBooking, ok := decodeSingleBooking(w, r)
+	if !ok {
+		return
+	}
+
+	action := "booking_added"
+	if dryRun {
+		action = ""
+	}
+	h.applyChange(w, r, action, func(calendar *booking.Calendar) (booking.CalendarChange, error) {
+		if err := checkCalendarLimits(limitsFor(r.Context()), calendar, newBooking); err != nil {
+			return booking.CalendarChange{}, err
+		}
+		if dryRun {
+			return calendar.Inquire(newBooking)
+		}
+		return calendar.Add(newBooking)
+	})
+}
+
+// UpdateBooking handles PUT /calendars/{id}/bookings/{request_id}.
+func (h *CalendarHandler) UpdateBooking(w http.ResponseWriter, r *http.Request) {
+	requestID := r.PathValue("request_id")
+	changedBooking, ok := decodeSingleBooking(w, r)
+	if !ok {
+		return
+	}
+	if changedBooking.RequestID != requestID {
+		respondError(w, http.StatusBadRequest, fmt.Sprintf("%v: request_id %q does not match path %q", ErrValidation, changedBooking.RequestID, requestID))
+		return
+	}
+
+	h.applyChange(w, r, "booking_updated", func(calendar *booking.Calendar) (booking.CalendarChange, error) {
+		if err := checkCalendarLimits(limitsFor(r.Context()), calendar, changedBooking); err != nil {
+			return booking.CalendarChange{}, err
+		}
+		return calendar.Update(changedBooking)
+	})
+}
+
+// checkCalendarLimits bounds the calendar as it would be with b stored,
+// replacing the booking with the same request_id if there is one. A calendar
+// may not grow one change at a time past what a request may carry, as every
+// change re-runs the DP over all of its bookings.
+func checkCalendarLimits(limits Limits, calendar *booking.Calendar, b booking.Booking) error {
+	bookings := calendar.Bookings()
+	if k := slices.IndexFunc(bookings, func(stored booking.Booking) bool { return stored.RequestID == b.RequestID }); k >= 0 {
+		bookings[k] = b
+	} else {
+		bookings = append(bookings, b)
+	}
+	if err := limits.checkBookingCount(len(bookings)); err != nil {
+		return err
+	}
+	return limits.checkDateSpan(bookings)
+}
+
+// CancelBooking handles DELETE /calendars/{id}/bookings/{request_id}.
+func (h *CalendarHandler) CancelBooking(w http.ResponseWriter, r *http.Request) {
+	requestID := r.PathValue("request_id")
+	h.applyChange(w, r, "booking_cancelled", func(calendar *booking.Calendar) (booking.CalendarChange, error) {
+		return calendar.Cancel(requestID)
+	})
+}
+
+// applyChange runs change against the calendar named in the path and
+// publishes the outcome under action, unless action is empty because nothing
+// was committed. The change takes a compute slot like any optimization; it
+// is not started once the budget has run out, but runs to completion once
+// it has.
+func (h *CalendarHandler) applyChange(w http.ResponseWriter, r *http.Request, action string, change func(*booking.Calendar) (booking.CalendarChange, error)) {
+	tenant, calendarID := TenantFromContext(r.Context()), r.PathValue("id")
+	ctx, cancel := withComputeBudget(r.Context())
+	defer cancel()
+	calendarChange, err := runComputation(ctx, func(ctx context.Context) (booking.CalendarChange, error) {
+		var calendarChange booking.CalendarChange
+		err := h.store.Update(tenant, calendarID, func(calendar *booking.Calendar) error {
+			if ctx.Err() != nil {
+				return context.Cause(ctx)
+			}
+			var changeErr error
+			calendarChange, changeErr = change(calendar)
+			return changeErr
+		})
+		return calendarChange, err
+	})
+	if err != nil {
+		respondCalendarError(w, err)
+		return
+	}
+
+	decision := types.BookingDecisionResponse{
+		RequestID: calendarChange.Booking.RequestID,
+		Accepted:  calendarChange.Accepted,
+		Displaced: requestIDsOf(calendarChange.Displaced),
+		Schedule:  ToMaximizeResponse(calendarChange.Schedule),
+	}
+	if action != "" {
+		h.events.Publish(tenant, EventCalendarChanged, types.CalendarChangedEvent{
+			CalendarID: calendarID,
+			Action:     action,
+			Decision:   decision,
+		})
+	}
+	respondJSON(w, http.StatusOK, decision)
+}
+
+// decodeSingleBooking reads and validates one booking from the request body,
+// writing the error response itself when it fails.
+func decodeSingleBooking(w http.ResponseWriter, r *http.Request) (booking.Booking, bool) {
+	var item types.BookingRequest
+	err := decodeJSON(r, &item)
+	if err != nil {
+		respondDecodeError(w, err)
+		return booking.Booking{}, false
+	}
+	defer r.Body.Close()
+
+	domainBookings, err := limitsFor(r.Context()).validateAndMapBookings([]types.BookingRequest{item})
+	if err != nil {
+		respondCalendarError(w, err)
+		return booking.Booking{}, false
+	}
+	return domainBookings[0], true
+}
+
+func respondCalendarError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrValidation), errors.Is(err, ErrTooManyBookings), errors.Is(err, ErrLimitExceeded):
+		respondInputError(w, err)
+	case errors.Is(err, store.ErrNotFound):
+		respondError(w, http.StatusNotFound, "Calendar not found")
+	case errors.Is(err, booking.ErrUnknownRequestID):
+		respondError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, booking.ErrDuplicateRequestID):
+		respondError(w, http.StatusConflict, err.Error())
+	default:
+		respondComputeError(w, err)
+	}
+}
+
+func toCalendarResponse(calendarID string, calendar *booking.Calendar) types.CalendarResponse {
+	return types.CalendarResponse{
+		CalendarID:   calendarID,
+		BookingCount: calendar.Len(),
+		Schedule:     ToMaximizeResponse(calendar.Schedule()),
+	}
+}
@@ -0,0 +1,277 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"rental-profit-api/internal/store"
+	"rental-profit-api/internal/testutil"
+	"rental-profit-api/internal/types"
+)
+
+func newCalendarMux() *http.ServeMux {
+	calendars := NewCalendarHandler(store.NewCalendarStore(), nil)
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /calendars", calendars.Create)
+	mux.HandleFunc("GET /calendars/{id}", calendars.Get)
+	mux.HandleFunc("DELETE /calendars/{id}", calendars.Delete)
+	mux.HandleFunc("POST /calendars/{id}/bookings", calendars.AddBooking)
+	mux.HandleFunc("PUT /calendars/{id}/bookings/{request_id}", calendars.UpdateBooking)
+	mux.HandleFunc("DELETE /calendars/{id}/bookings/{request_id}", calendars.CancelBooking)
+	return mux
+}
+
+func TestCalendarHandler(t *testing.T) {
+	mux := newCalendarMux()
+
+	req := testutil.NewTestRequest(t, http.MethodPost, "/calendars", []types.BookingRequest{
+		{RequestID: "B1", Checkin: "2024-01-01", Nights: 4, SellingRate: 100, Margin: 20},
+		{RequestID: "B3", Checkin: "2024-01-06", Nights: 2, SellingRate: 100, Margin: 25},
+	})
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, req)
+	if recorder.Code != http.StatusCreated {
+		t.Fatalf("create returned wrong status code: got %v want %v. Body: %s", recorder.Code, http.StatusCreated, recorder.Body.String())
+	}
+	var created types.CalendarResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &created); err != nil {
+		t.Fatalf("Failed to unmarshal create response: %v", err)
+	}
+	calendarPath := "/calendars/" + created.CalendarID
+
+	// --- Define Test Scenarios ---
+	testCases := []struct {
+		name                 string
+		requestMethod        string
+		path                 string
+		requestBody          interface{}
+		expectedStatus       int
+		expectedBodyContains string
+	}{
+		{
+			name:                 "Get Calendar",
+			requestMethod:        http.MethodGet,
+			path:                 calendarPath,
+			expectedStatus:       http.StatusOK,
+			expectedBodyContains: `"booking_count":2,"schedule":{"request_ids":["B1","B3"],"total_profit":45`,
+		},
+		{
+			name:                 "Unknown Calendar",
+			requestMethod:        http.MethodGet,
+			path:                 "/calendars/missing",
+			expectedStatus:       http.StatusNotFound,
+			expectedBodyContains: "Calendar not found",
+		},
+		{
+			name:                 "Inquiry Is Not Stored",
+			requestMethod:        http.MethodPost,
+			path:                 calendarPath + "/bookings?dry_run=true",
+			requestBody:          types.BookingRequest{RequestID: "B4", Checkin: "2024-01-02", Nights: 6, SellingRate: 100, Margin: 60},
+			expectedStatus:       http.StatusOK,
+			expectedBodyContains: `"request_id":"B4","accepted":true,"displaced":["B1","B3"]`,
+		},
+		{
+			name:                 "Invalid Dry Run Flag",
+			requestMethod:        http.MethodPost,
+			path:                 calendarPath + "/bookings?dry_run=maybe",
+			requestBody:          types.BookingRequest{RequestID: "B4", Checkin: "2024-01-02", Nights: 6, SellingRate: 100, Margin: 60},
+			expectedStatus:       http.StatusBadRequest,
+			expectedBodyContains: "dry_run must be a boolean",
+		},
+		{
+			name:                 "Declined Booking",
+			requestMethod:        http.MethodPost,
+			path:                 calendarPath + "/bookings",
+			requestBody:          types.BookingRequest{RequestID: "B2", Checkin: "2024-01-03", Nights: 5, SellingRate: 100, Margin: 30},
+			expectedStatus:       http.StatusOK,
+			expectedBodyContains: `"request_id":"B2","accepted":false,"displaced":[]`,
+		},
+		{
+			name:                 "Duplicate Booking",
+			requestMethod:        http.MethodPost,
+			path:                 calendarPath + "/bookings",
+			requestBody:          types.BookingRequest{RequestID: "B2", Checkin: "2024-01-03", Nights: 5, SellingRate: 100, Margin: 30},
+			expectedStatus:       http.StatusConflict,
+			expectedBodyContains: "duplicate request_id: B2",
+		},
+		{
+			name:                 "Invalid Booking",
+			requestMethod:        http.MethodPost,
+			path:                 calendarPath + "/bookings",
+			requestBody:          types.BookingRequest{RequestID: "B5", Checkin: "2024-01-03", Nights: 0, SellingRate: 100, Margin: 30},
+			expectedStatus:       http.StatusBadRequest,
+			expectedBodyContains: "nights must be positive",
+		},
+		{
+			name:                 "Update Path Mismatch",
+			requestMethod:        http.MethodPut,
+			path:                 calendarPath + "/bookings/B2",
+			requestBody:          types.BookingRequest{RequestID: "B1", Checkin: "2024-01-03", Nights: 5, SellingRate: 100, Margin: 90},
+			expectedStatus:       http.StatusBadRequest,
+			expectedBodyContains: "does not match path",
+		},
+		{
+			name:                 "Updated Booking Takes Over",
+			requestMethod:        http.MethodPut,
+			path:                 calendarPath + "/bookings/B2",
+			requestBody:          types.BookingRequest{RequestID: "B2", Checkin: "2024-01-03", Nights: 5, SellingRate: 100, Margin: 90},
+			expectedStatus:       http.StatusOK,
+			expectedBodyContains: `"request_id":"B2","accepted":true,"displaced":["B1","B3"]`,
+		},
+		{
+			name:                 "Cancel Booking",
+			requestMethod:        http.MethodDelete,
+			path:                 calendarPath + "/bookings/B2",
+			expectedStatus:       http.StatusOK,
+			expectedBodyContains: `"schedule":{"request_ids":["B1","B3"]`,
+		},
+		{
+			name:                 "Cancel Unknown Booking",
+			requestMethod:        http.MethodDelete,
+			path:                 calendarPath + "/bookings/B2",
+			expectedStatus:       http.StatusNotFound,
+			expectedBodyContains: "unknown request_id: B2",
+		},
+		{
+			name:           "Delete Calendar",
+			requestMethod:  http.MethodDelete,
+			path:           calendarPath,
+			expectedStatus: http.StatusNoContent,
+		},
+	}
+
+	// --- Execute Scenarios ---
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			req := testutil.NewTestRequest(t, tt.requestMethod, tt.path, tt.requestBody)
+			recorder := httptest.NewRecorder()
+
+			mux.ServeHTTP(recorder, req)
+
+			if status := recorder.Code; status != tt.expectedStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v", status, tt.expectedStatus)
+				t.Logf("Response Body: %s", recorder.Body.String())
+			}
+
+			if tt.expectedBodyContains != "" {
+				if !strings.Contains(recorder.Body.String(), tt.expectedBodyContains) {
+					t.Errorf("handler returned unexpected body: got %q want substring %q", recorder.Body.String(), tt.expectedBodyContains)
+				}
+			}
+		})
+	}
+}
+
+func TestCalendarBookingLimit(t *testing.T) {
+	previous := DefaultLimits
+	DefaultLimits = Limits{MaxBookings: 2, MaxDateSpanDays: 30}
+	t.Cleanup(func() { DefaultLimits = previous })
+	mux := newCalendarMux()
+
+	req := testutil.NewTestRequest(t, http.MethodPost, "/calendars", []types.BookingRequest{
+		{RequestID: "B1", Checkin: "2024-01-01", Nights: 4, SellingRate: 100, Margin: 20},
+		{RequestID: "B3", Checkin: "2024-01-06", Nights: 2, SellingRate: 100, Margin: 25},
+	})
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, req)
+	if recorder.Code != http.StatusCreated {
+		t.Fatalf("create returned wrong status code: got %v want %v. Body: %s", recorder.Code, http.StatusCreated, recorder.Body.String())
+	}
+	var created types.CalendarResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &created); err != nil {
+		t.Fatalf("Failed to unmarshal create response: %v", err)
+	}
+	calendarPath := "/calendars/" + created.CalendarID
+
+	// --- Define Test Scenarios ---
+	testCases := []struct {
+		name                 string
+		requestMethod        string
+		path                 string
+		requestBody          interface{}
+		expectedStatus       int
+		expectedBodyContains string
+	}{
+		{
+			name:                 "Booking Beyond The Limit",
+			requestMethod:        http.MethodPost,
+			path:                 calendarPath + "/bookings",
+			requestBody:          types.BookingRequest{RequestID: "B4", Checkin: "2024-01-10", Nights: 2, SellingRate: 100, Margin: 20},
+			expectedStatus:       http.StatusRequestEntityTooLarge,
+			expectedBodyContains: "3 bookings exceed the maximum of 2",
+		},
+		{
+			name:                 "Inquiry Beyond The Limit",
+			requestMethod:        http.MethodPost,
+			path:                 calendarPath + "/bookings?dry_run=true",
+			requestBody:          types.BookingRequest{RequestID: "B4", Checkin: "2024-01-10", Nights: 2, SellingRate: 100, Margin: 20},
+			expectedStatus:       http.StatusRequestEntityTooLarge,
+			expectedBodyContains: "3 bookings exceed the maximum of 2",
+		},
+		{
+			name:                 "Update At The Limit",
+			requestMethod:        http.MethodPut,
+			path:                 calendarPath + "/bookings/B3",
+			requestBody:          types.BookingRequest{RequestID: "B3", Checkin: "2024-01-06", Nights: 3, SellingRate: 100, Margin: 25},
+			expectedStatus:       http.StatusOK,
+			expectedBodyContains: `"request_id":"B3","accepted":true`,
+		},
+		{
+			name:                 "Update Past The Date Span",
+			requestMethod:        http.MethodPut,
+			path:                 calendarPath + "/bookings/B3",
+			requestBody:          types.BookingRequest{RequestID: "B3", Checkin: "2024-02-01", Nights: 2, SellingRate: 100, Margin: 25},
+			expectedStatus:       http.StatusUnprocessableEntity,
+			expectedBodyContains: "bookings span 33 days from 2024-01-01 to 2024-02-03, more than the maximum of 30",
+		},
+		{
+			name:                 "Count Is Unchanged",
+			requestMethod:        http.MethodGet,
+			path:                 calendarPath,
+			expectedStatus:       http.StatusOK,
+			expectedBodyContains: `"booking_count":2`,
+		},
+		{
+			name:                 "Cancel Makes Room",
+			requestMethod:        http.MethodDelete,
+			path:                 calendarPath + "/bookings/B3",
+			expectedStatus:       http.StatusOK,
+			expectedBodyContains: `"request_id":"B3"`,
+		},
+		{
+			name:                 "Booking Past The Date Span",
+			requestMethod:        http.MethodPost,
+			path:                 calendarPath + "/bookings",
+			requestBody:          types.BookingRequest{RequestID: "B5", Checkin: "2024-02-01", Nights: 2, SellingRate: 100, Margin: 20},
+			expectedStatus:       http.StatusUnprocessableEntity,
+			expectedBodyContains: "bookings span 33 days",
+		},
+		{
+			name:                 "Booking Within The Date Span",
+			requestMethod:        http.MethodPost,
+			path:                 calendarPath + "/bookings",
+			requestBody:          types.BookingRequest{RequestID: "B5", Checkin: "2024-01-20", Nights: 2, SellingRate: 100, Margin: 20},
+			expectedStatus:       http.StatusOK,
+			expectedBodyContains: `"request_id":"B5","accepted":true`,
+		},
+	}
+
+	// --- Execute Scenarios ---
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			req := testutil.NewTestRequest(t, tt.requestMethod, tt.path, tt.requestBody)
+			recorder := httptest.NewRecorder()
+			mux.ServeHTTP(recorder, req)
+
+			if status := recorder.Code; status != tt.expectedStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v. Body: %s", status, tt.expectedStatus, recorder.Body.String())
+			}
+			if !strings.Contains(recorder.Body.String(), tt.expectedBodyContains) {
+				t.Errorf("handler returned unexpected body: got %q want substring %q", recorder.Body.String(), tt.expectedBodyContains)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"rental-profit-api/internal/booking"
+	"rental-profit-api/internal/types"
+)
+
+func CompareHandler(w http.ResponseWriter, r *http.Request) {
+	var compareRequest types.CompareRequest
+	err := decodeJSON(r, &compareRequest)
+	if err != nil {
+		respondDecodeError(w, err)
+		return
+	}
+	defer r.Body.Close()
+
+	// Validate the request content and format
+	domainBookings, err := limitsFor(r.Context()).validateAndMapBookings(compareRequest.Bookings)
+	if err == nil {
+		err = booking.CheckUniqueRequestIDs(domainBookings)
+		if err != nil {
+			err = fmt.Errorf("%w: %w", ErrValidation, err)
+		}
+	}
+	if err == nil {
+		err = checkSelection(compareRequest.Left, "left")
+	}
+	if err == nil {
+		err = checkSelection(compareRequest.Right, "right")
+	}
+	if err != nil {
+		respondInputError(w, err)
+		return
+	}
+
+	// Execute business logic
+	ctx, cancel := withComputeBudget(r.Context())
+	defer cancel()
+	left, err := resolveSelection(ctx, domainBookings, compareRequest.Left, "left")
+	if err != nil {
+		respondComputeError(w, err)
+		return
+	}
+	right, err := resolveSelection(ctx, domainBookings, compareRequest.Right, "right")
+	if err != nil {
+		respondComputeError(w, err)
+		return
+	}
+	comparison := booking.CompareSchedules(left, right)
+
+	response := types.CompareResponse{
+		Left:           toScheduleSummary(comparison.Left),
+		Right:          toScheduleSummary(comparison.Right),
+		Added:          requestIDsOf(comparison.Added),
+		Removed:        requestIDsOf(comparison.Removed),
+		ProfitDelta:    roundToCents(comparison.ProfitDelta),
+		OccupancyDelta: comparison.OccupancyDelta,
+	}
+
+	respondJSON(w, http.StatusOK, response)
+}
+
+// checkSelection rejects a side that was left out or given as null; an
+// explicit empty list is a valid, empty selection.
+func checkSelection(selection types.ScheduleSelection, side string) error {
+	if selection.IsZero() {
+		return validationError(side, "%s schedule is required: use a list of request_ids or %q", side, types.OptimalSelection)
+	}
+	return nil
+}
+
+// resolveSelection turns one side of a comparison into a schedule, either
+// by running the optimizer or by evaluating the hand-picked bookings.
+func resolveSelection(ctx context.Context, domainBookings []booking.Booking, selection types.ScheduleSelection, side string) (booking.ScheduleResult, error) {
+	if selection.Optimal {
+		return findMaxProfit(ctx, domainBookings)
+	}
+	selected, err := booking.SelectBookings(domainBookings, selection.RequestIDs)
+	if err != nil {
+		return booking.ScheduleResult{}, fmt.Errorf("%w: %s schedule: %w", ErrValidation, side, err)
+	}
+	result, err := booking.EvaluateSchedule(selected)
+	if err != nil {
+		return booking.ScheduleResult{}, fmt.Errorf("%w: %s schedule: %w", ErrValidation, side, err)
+	}
+	return result, nil
+}
+
+func toScheduleSummary(result booking.ScheduleResult) types.ScheduleSummary {
+	return types.ScheduleSummary{
+		RequestIDs:     requestIDsOf(result.OptimalSchedule),
+		TotalProfit:    roundToCents(result.TotalProfit),
+		OccupiedNights: booking.OccupiedNights(result.OptimalSchedule),
+		AvgNight:       roundToCents(result.AvgProfitPerNight),
+		MinNight:       roundToCents(result.MinProfitPerNight),
+		MaxNight:       roundToCents(result.MaxProfitPerNight),
+	}
+}
+
+func requestIDsOf(bookings []booking.Booking) []string {
+	requestIDs := make([]string, len(bookings))
+	for i, b := range bookings {
+		requestIDs[i] = b.RequestID
+	}
+	return requestIDs
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"rental-profit-api/internal/testutil"
+	"rental-profit-api/internal/types"
+)
+
+func TestCompareHandler(t *testing.T) {
+	bookings := []types.BookingRequest{
+		{RequestID: "B1", Checkin: "2024-01-01", Nights: 4, SellingRate: 100, Margin: 20},
+		{RequestID: "B2", Checkin: "2024-01-03", Nights: 5, SellingRate: 100, Margin: 30},
+		{RequestID: "B3", Checkin: "2024-01-06", Nights: 2, SellingRate: 100, Margin: 25},
+	}
+
+	// --- Define Test Scenarios ---
+	testCases := []struct {
+		name                 string
+		requestMethod        string
+		requestBody          interface{}
+		expectedStatus       int
+		expectedBodyContains string
+	}{
+		{
+			name:                 "Invalid Selection Keyword",
+			requestMethod:        http.MethodPost,
+			requestBody:          `{"bookings":[],"left":"best","right":"optimal"}`,
+			expectedStatus:       http.StatusBadRequest,
+			expectedBodyContains: "Invalid JSON format",
+		},
+		{
+			name:                 "Unknown Request ID",
+			requestMethod:        http.MethodPost,
+			requestBody:          types.CompareRequest{Bookings: bookings, Left: types.ScheduleSelection{RequestIDs: []string{"B9"}}, Right: types.ScheduleSelection{Optimal: true}},
+			expectedStatus:       http.StatusBadRequest,
+			expectedBodyContains: "left schedule: unknown request_id: B9",
+		},
+		{
+			name:                 "Overlapping Manual Schedule",
+			requestMethod:        http.MethodPost,
+			requestBody:          types.CompareRequest{Bookings: bookings, Left: types.ScheduleSelection{Optimal: true}, Right: types.ScheduleSelection{RequestIDs: []string{"B1", "B2"}}},
+			expectedStatus:       http.StatusBadRequest,
+			expectedBodyContains: "right schedule: overlapping bookings",
+		},
+		{
+			name:                 "Duplicate Request IDs In Payload",
+			requestMethod:        http.MethodPost,
+			requestBody:          types.CompareRequest{Bookings: append(bookings, bookings[0]), Left: types.ScheduleSelection{Optimal: true}, Right: types.ScheduleSelection{Optimal: true}},
+			expectedStatus:       http.StatusBadRequest,
+			expectedBodyContains: "duplicate request_id: B1",
+		},
+		{
+			name:                 "Manual Versus Optimal",
+			requestMethod:        http.MethodPost,
+			requestBody:          types.CompareRequest{Bookings: bookings, Left: types.ScheduleSelection{RequestIDs: []string{"B2"}}, Right: types.ScheduleSelection{Optimal: true}},
+			expectedStatus:       http.StatusOK,
+			expectedBodyContains: `"added":["B1","B3"],"removed":["B2"],"profit_delta":15,"occupancy_delta":1`,
+		},
+		{
+			name:                 "Missing Selection",
+			requestMethod:        http.MethodPost,
+			requestBody:          `{"bookings":[],"left":"optimal"}`,
+			expectedStatus:       http.StatusBadRequest,
+			expectedBodyContains: `right schedule is required`,
+		},
+		{
+			name:                 "Null Selection",
+			requestMethod:        http.MethodPost,
+			requestBody:          `{"bookings":[],"left":null,"right":"optimal"}`,
+			expectedStatus:       http.StatusBadRequest,
+			expectedBodyContains: `left schedule is required`,
+		},
+		{
+			name:                 "Empty Selection",
+			requestMethod:        http.MethodPost,
+			requestBody:          `{"bookings":[],"left":[],"right":"optimal"}`,
+			expectedStatus:       http.StatusOK,
+			expectedBodyContains: `"added":[],"removed":[]`,
+		},
+	}
+
+	// --- Execute Scenarios ---
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			var req *http.Request
+			if raw, ok := tt.requestBody.(string); ok {
+				req = testutil.NewRawTestRequest(t, tt.requestMethod, "/compare", raw)
+			} else {
+				req = testutil.NewTestRequest(t, tt.requestMethod, "/compare", tt.requestBody)
+			}
+			recorder := httptest.NewRecorder()
+
+			CompareHandler(recorder, req)
+
+			if status := recorder.Code; status != tt.expectedStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v", status, tt.expectedStatus)
+				t.Logf("Response Body: %s", recorder.Body.String())
+			}
+
+			if tt.expectedBodyContains != "" {
+				if !strings.Contains(recorder.Body.String(), tt.expectedBodyContains) {
+					t.Errorf("handler returned unexpected body: got %q want substring %q", recorder.Body.String(), tt.expectedBodyContains)
+				}
+			}
+		})
+	}
+}
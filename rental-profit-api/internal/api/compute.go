@@ -0,0 +1,97 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"rental-profit-api/internal/booking"
+	"rental-profit-api/internal/ratelimit"
+)
+
+// StatusClientClosedRequest is the non-standard status, popularized by
+// nginx, recorded when the client went away before the response was ready.
+const StatusClientClosedRequest = 499
+
+var ErrComputeBudgetExceeded = errors.New("compute budget exceeded")
+
+// ComputeBudget caps how long a single request may spend optimizing. Zero
+// disables the cap. It is set from the server configuration at startup.
+var ComputeBudget time.Duration
+
+// withComputeBudget derives the context an optimization runs under. It ends
+// when the client disconnects or the budget runs out, whichever is first.
+func withComputeBudget(ctx context.Context) (context.Context, context.CancelFunc) {
+	if ComputeBudget <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeoutCause(ctx, ComputeBudget, ErrComputeBudgetExceeded)
+}
+
+// findMaxProfit runs booking.FindMaxProfitContext once a compute slot is
+// free and records how long it took, or why it was abandoned.
+func findMaxProfit(ctx context.Context, bookings []booking.Booking) (booking.ScheduleResult, error) {
+	release, err := acquireComputeSlot(ctx)
+	if errors.Is(err, ratelimit.ErrQueueFull) {
+		throttledRequests.Inc("compute_queue_full")
+		return booking.ScheduleResult{}, err
+	}
+	if err == nil {
+		defer release()
+	}
+
+	start := time.Now()
+	result := booking.ScheduleResult{}
+	if err == nil {
+		result, err = booking.FindMaxProfitContext(ctx, bookings)
+	}
+	switch {
+	case err == nil:
+		findMaxProfitDuration.Observe(time.Since(start).Seconds())
+	case errors.Is(err, ErrComputeBudgetExceeded):
+		findMaxProfitAborted.Inc("budget_exceeded")
+	case errors.Is(err, context.DeadlineExceeded):
+		findMaxProfitAborted.Inc("deadline_exceeded")
+	default:
+		findMaxProfitAborted.Inc("client_closed")
+	}
+	return result, err
+}
+
+// runComputation runs an optimization other than a single FindMaxProfit,
+// such as a quote or a stochastic schedule, once a compute slot is free, so
+// every route counts against the same ComputeSlots. ctx should carry the
+// compute budget.
+func runComputation[T any](ctx context.Context, run func(ctx context.Context) (T, error)) (T, error) {
+	release, err := acquireComputeSlot(ctx)
+	if err != nil {
+		if errors.Is(err, ratelimit.ErrQueueFull) {
+			throttledRequests.Inc("compute_queue_full")
+		}
+		var zero T
+		return zero, err
+	}
+	defer release()
+	return run(ctx)
+}
+
+// respondComputeError reports an optimization that did not finish. Any
+// other error is reported as a problem with the input.
+func respondComputeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ratelimit.ErrQueueFull):
+		respondTooManyRequests(w, computeRetryAfter, "Too many optimizations in progress, retry later")
+	case errors.Is(err, ErrComputeBudgetExceeded):
+		respondError(w, http.StatusServiceUnavailable, fmt.Sprintf("Computation exceeded the budget of %s; retry with fewer bookings", ComputeBudget))
+	case errors.Is(err, context.DeadlineExceeded):
+		respondError(w, http.StatusServiceUnavailable, "Computation did not finish in time")
+	case errors.Is(err, context.Canceled):
+		// Nobody is listening any more, but the status still shows up in
+		// the access log and metrics
+		respondError(w, StatusClientClosedRequest, "Client closed request")
+	default:
+		respondInputError(w, err)
+	}
+}
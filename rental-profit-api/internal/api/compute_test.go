@@ -0,0 +1,68 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"rental-profit-api/internal/booking"
+	"rental-profit-api/internal/testutil"
+	"rental-profit-api/internal/types"
+)
+
+func TestMaximizeClientClosedRequest(t *testing.T) {
+	bookings := []types.BookingRequest{
+		{RequestID: "B1", Checkin: "2024-01-01", Nights: 4, SellingRate: 100, Margin: 10},
+	}
+	abortedBefore := findMaxProfitAborted.Value("client_closed")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := testutil.NewTestRequest(t, http.MethodPost, "/maximize", bookings).WithContext(ctx)
+	recorder := httptest.NewRecorder()
+
+	MaximizeProfitHandler(recorder, req)
+
+	if status := recorder.Code; status != StatusClientClosedRequest {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, StatusClientClosedRequest)
+	}
+	if got := findMaxProfitAborted.Value("client_closed") - abortedBefore; got != 1 {
+		t.Errorf("aborted counter grew by %v, want 1", got)
+	}
+}
+
+func TestComputeBudgetExceeded(t *testing.T) {
+	previous := ComputeBudget
+	ComputeBudget = time.Nanosecond
+	t.Cleanup(func() { ComputeBudget = previous })
+
+	ctx, cancel := withComputeBudget(context.Background())
+	defer cancel()
+	<-ctx.Done()
+
+	_, err := findMaxProfit(ctx, []booking.Booking{{RequestID: "B1", Checkin: time.Now(), Nights: 1, SellingRate: 100, Margin: 10}})
+	recorder := httptest.NewRecorder()
+	respondComputeError(recorder, err)
+
+	if status := recorder.Code; status != http.StatusServiceUnavailable {
+		t.Errorf("wrong status code: got %v want %v", status, http.StatusServiceUnavailable)
+	}
+	if want := "Computation exceeded the budget of 1ns"; !strings.Contains(recorder.Body.String(), want) {
+		t.Errorf("unexpected body: got %q want substring %q", recorder.Body.String(), want)
+	}
+}
+
+func TestComputeBudgetDisabled(t *testing.T) {
+	previous := ComputeBudget
+	ComputeBudget = 0
+	t.Cleanup(func() { ComputeBudget = previous })
+
+	ctx, cancel := withComputeBudget(context.Background())
+	defer cancel()
+	if _, hasDeadline := ctx.Deadline(); hasDeadline {
+		t.Error("expected no deadline when the budget is disabled")
+	}
+}
@@ -0,0 +1,112 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// DefaultStrictJSON makes request bodies decode strictly on every route not
+// wrapped in StrictJSON. It is set from the server configuration at startup.
+var DefaultStrictJSON bool
+
+// errTrailingData rejects a strictly decoded body with more after its value.
+var errTrailingData = errors.New("unexpected data after the JSON body")
+
+type strictJSONKey struct{}
+
+// StrictJSON switches strict decoding on or off for the routes it wraps,
+// regardless of DefaultStrictJSON and the tenant's setting. Strict decoding rejects fields the request
+// type does not have, naming the field and, in lists, the item, and rejects
+// anything after the JSON value.
+func StrictJSON(enabled bool) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), strictJSONKey{}, enabled)))
+		})
+	}
+}
+
+func strictJSON(ctx context.Context) bool {
+	if strict, ok := ctx.Value(strictJSONKey{}).(bool); ok {
+		return strict
+	}
+	if settings, ok := tenantSettings(ctx); ok {
+		return settings.StrictJSON
+	}
+	return DefaultStrictJSON
+}
+
+// decodeJSON decodes the request body into target, strictly if the route
+// asks for it.
+func decodeJSON(r *http.Request, target any) error {
+	decoder := json.NewDecoder(r.Body)
+	if !strictJSON(r.Context()) {
+		return decoder.Decode(target)
+	}
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(target); err != nil {
+		return unknownFieldError(err, "")
+	}
+	return checkTrailingData(decoder)
+}
+
+// decodeJSONList decodes a request body holding a JSON array into target.
+// Decoded strictly, items are decoded one by one so an error can say which
+// item it is about.
+func decodeJSONList[T any](r *http.Request, target *[]T) error {
+	if !strictJSON(r.Context()) {
+		return json.NewDecoder(r.Body).Decode(target)
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	var items []json.RawMessage
+	if err := decoder.Decode(&items); err != nil {
+		return err
+	}
+	if err := checkTrailingData(decoder); err != nil {
+		return err
+	}
+	if items == nil {
+		*target = nil
+		return nil
+	}
+	list := make([]T, len(items))
+	for i, item := range items {
+		itemDecoder := json.NewDecoder(bytes.NewReader(item))
+		itemDecoder.DisallowUnknownFields()
+		if err := itemDecoder.Decode(&list[i]); err != nil {
+			if err := unknownFieldError(err, fmt.Sprintf(" on item %d", i)); errors.Is(err, ErrValidation) {
+				return err
+			}
+			return fmt.Errorf("item %d: %w", i, err)
+		}
+	}
+	*target = list
+	return nil
+}
+
+// unknownFieldError turns the decoder's error for a field the request type
+// lacks into a validation error naming the field, followed by where, such as
+// " on item 2". Other errors are returned as they are.
+func unknownFieldError(err error, where string) error {
+	// encoding/json reports unknown fields only through the error message
+	field, ok := strings.CutPrefix(err.Error(), "json: unknown field ")
+	if !ok {
+		return err
+	}
+	return validationError("unknown_field", "unknown field %s%s", field, where)
+}
+
+// checkTrailingData fails unless the decoder has consumed the whole body.
+func checkTrailingData(decoder *json.Decoder) error {
+	if _, err := decoder.Token(); err != io.EOF {
+		return errTrailingData
+	}
+	return nil
+}
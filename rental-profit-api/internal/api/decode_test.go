@@ -0,0 +1,142 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"rental-profit-api/internal/testutil"
+)
+
+func TestStrictJSON(t *testing.T) {
+	const valid = `{"request_id":"B1","check_in":"2024-01-01","nights":4,"selling_rate":100,"margin":10}`
+	const misspelled = `{"request_id":"B2","checkin":"2024-01-06","nights":2,"selling_rate":150,"margin":20}`
+
+	// --- Define Test Scenarios ---
+	testCases := []struct {
+		name                 string
+		defaultStrict        bool
+		strict               *bool // Wraps the handler in StrictJSON when set
+		handler              http.HandlerFunc
+		body                 string
+		expectedStatus       int
+		expectedBodyContains string
+	}{
+		{
+			name:                 "Lenient Ignores Unknown Field",
+			handler:              MaximizeProfitHandler,
+			body:                 "[" + valid + "," + misspelled + "]",
+			expectedStatus:       http.StatusBadRequest,
+			expectedBodyContains: "check_in format error on item 1",
+		},
+		{
+			name:                 "Lenient Ignores Trailing Data",
+			handler:              MaximizeProfitHandler,
+			body:                 "[" + valid + "] []",
+			expectedStatus:       http.StatusOK,
+			expectedBodyContains: `"request_ids":["B1"]`,
+		},
+		{
+			name:                 "Strict Valid",
+			strict:               ptr(true),
+			handler:              MaximizeProfitHandler,
+			body:                 "[" + valid + "]\n",
+			expectedStatus:       http.StatusOK,
+			expectedBodyContains: `"request_ids":["B1"]`,
+		},
+		{
+			name:                 "Strict Null Body",
+			strict:               ptr(true),
+			handler:              StatsHandler,
+			body:                 "null",
+			expectedStatus:       http.StatusOK,
+			expectedBodyContains: `"avg_night":0`,
+		},
+		{
+			name:                 "Strict Names Unknown Field And Item",
+			strict:               ptr(true),
+			handler:              MaximizeProfitHandler,
+			body:                 "[" + valid + "," + misspelled + "]",
+			expectedStatus:       http.StatusBadRequest,
+			expectedBodyContains: `unknown field \"checkin\" on item 1`,
+		},
+		{
+			name:                 "Strict Trailing Data",
+			strict:               ptr(true),
+			handler:              MaximizeProfitHandler,
+			body:                 "[" + valid + "] []",
+			expectedStatus:       http.StatusBadRequest,
+			expectedBodyContains: "unexpected data after the JSON body",
+		},
+		{
+			name:                 "Strict Names Item Of Wrong Type",
+			strict:               ptr(true),
+			handler:              StatsHandler,
+			body:                 `[` + valid + `,{"request_id":"B2","nights":"2"}]`,
+			expectedStatus:       http.StatusBadRequest,
+			expectedBodyContains: "item 1: json: cannot unmarshal string",
+		},
+		{
+			name:                 "Strict Not An Array",
+			strict:               ptr(true),
+			handler:              StatsHandler,
+			body:                 valid,
+			expectedStatus:       http.StatusBadRequest,
+			expectedBodyContains: "cannot unmarshal object",
+		},
+		{
+			name:                 "Strict Object Body",
+			strict:               ptr(true),
+			handler:              ValidateScheduleHandler,
+			body:                 `{"bookings":[],"request_ids":[],"requestIds":["B1"]}`,
+			expectedStatus:       http.StatusBadRequest,
+			expectedBodyContains: `unknown field \"requestIds\"`,
+		},
+		{
+			name:                 "Strict By Default",
+			defaultStrict:        true,
+			handler:              MaximizeProfitHandler,
+			body:                 "[" + misspelled + "]",
+			expectedStatus:       http.StatusBadRequest,
+			expectedBodyContains: `unknown field \"checkin\" on item 0`,
+		},
+		{
+			name:                 "Route Overrides Default",
+			defaultStrict:        true,
+			strict:               ptr(false),
+			handler:              MaximizeProfitHandler,
+			body:                 "[" + valid + "] []",
+			expectedStatus:       http.StatusOK,
+			expectedBodyContains: `"request_ids":["B1"]`,
+		},
+	}
+
+	// --- Execute Scenarios ---
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			previous := DefaultStrictJSON
+			DefaultStrictJSON = tc.defaultStrict
+			t.Cleanup(func() { DefaultStrictJSON = previous })
+
+			var handler http.Handler = tc.handler
+			if tc.strict != nil {
+				handler = StrictJSON(*tc.strict)(handler)
+			}
+			req := testutil.NewRawTestRequest(t, http.MethodPost, "/", tc.body)
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			if recorder.Code != tc.expectedStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v. Body: %s", recorder.Code, tc.expectedStatus, recorder.Body.String())
+			}
+			if !strings.Contains(recorder.Body.String(), tc.expectedBodyContains) {
+				t.Errorf("handler returned unexpected body: got %q want substring %q", recorder.Body.String(), tc.expectedBodyContains)
+			}
+		})
+	}
+}
+
+func ptr[T any](value T) *T {
+	return &value
+}
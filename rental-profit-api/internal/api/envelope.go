@@ -0,0 +1,103 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"rental-profit-api/internal/types"
+)
+
+type responseMetaKey struct{}
+
+// Envelope serves the /v2 routes on top of the handlers behind the
+// unversioned ones. It buffers the handler's response and re-sends it
+// wrapped in a types.Envelope: successful payloads go into data, error
+// messages into errors, and meta reports the request ID, how long the
+// request took and how many bookings it carried. Headers set by next, such
+// as Retry-After, are kept.
+func Envelope(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		meta := &types.ResponseMeta{}
+		buffer := &bufferedResponse{header: w.Header(), status: http.StatusOK}
+		next.ServeHTTP(buffer, r.WithContext(context.WithValue(r.Context(), responseMetaKey{}, meta)))
+
+		// The ETag of the payload only holds weakly for the envelope, whose
+		// meta differs on every response
+		if etag := w.Header().Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			w.Header().Set("ETag", "W/"+etag)
+		}
+		if buffer.status == http.StatusNotModified {
+			w.WriteHeader(buffer.status)
+			return
+		}
+
+		meta.RequestID = w.Header().Get(RequestIDHeader)
+		meta.Timing.DurationMs = float64(time.Since(start).Microseconds()) / 1000
+		envelope := types.Envelope{Errors: []types.EnvelopeError{}, Meta: *meta}
+		body := bytes.TrimSpace(buffer.body.Bytes())
+		if buffer.status < http.StatusBadRequest {
+			if json.Valid(body) {
+				envelope.Data = body
+			}
+		} else {
+			var errorResponse types.ErrorResponse
+			if json.Unmarshal(body, &errorResponse) != nil || errorResponse.Message == "" {
+				errorResponse.Message = http.StatusText(buffer.status)
+			}
+			envelope.Errors = append(envelope.Errors, types.EnvelopeError{
+				Code:    errorCode(buffer.status),
+				Message: errorResponse.Message,
+			})
+		}
+		respondJSON(w, buffer.status, envelope)
+	})
+}
+
+// observeInputBookings records how many bookings a request carried, in the
+// metrics and, under Envelope, in the response meta.
+func observeInputBookings(r *http.Request, route string, count int) {
+	inputBookings.Observe(float64(count), route)
+	if meta, ok := r.Context().Value(responseMetaKey{}).(*types.ResponseMeta); ok {
+		meta.InputCounts = &types.InputCounts{Bookings: count}
+	}
+}
+
+// errorCode turns a status code into a stable machine-readable error code,
+// such as "too_many_requests" for 429.
+func errorCode(status int) string {
+	text := http.StatusText(status)
+	if text == "" {
+		return "error"
+	}
+	return strings.ToLower(strings.NewReplacer(" ", "_", "-", "_").Replace(text))
+}
+
+// bufferedResponse holds back a response so Envelope can rewrite it. Headers
+// go straight to the real response.
+type bufferedResponse struct {
+	header      http.Header
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (b *bufferedResponse) Header() http.Header {
+	return b.header
+}
+
+func (b *bufferedResponse) WriteHeader(code int) {
+	if !b.wroteHeader {
+		b.status = code
+		b.wroteHeader = true
+	}
+}
+
+func (b *bufferedResponse) Write(body []byte) (int, error) {
+	b.wroteHeader = true
+	return b.body.Write(body)
+}
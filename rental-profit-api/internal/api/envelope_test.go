@@ -0,0 +1,150 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"rental-profit-api/internal/ratelimit"
+	"rental-profit-api/internal/testutil"
+	"rental-profit-api/internal/types"
+)
+
+func TestEnvelope(t *testing.T) {
+	bookings := []types.BookingRequest{
+		{RequestID: "B1", Checkin: "2024-01-01", Nights: 5, SellingRate: 1000, Margin: 10},
+		{RequestID: "B2", Checkin: "2024-01-04", Nights: 4, SellingRate: 1200, Margin: 15},
+	}
+	// Lets one request through, then answers 429
+	throttled := RateLimit(ratelimit.NewLimiter(0.001, 1))(http.HandlerFunc(MaximizeProfitHandler))
+	throttled.ServeHTTP(httptest.NewRecorder(), testutil.NewTestRequest(t, http.MethodPost, "/v2/maximize", bookings))
+
+	// --- Define Test Scenarios ---
+	testCases := []struct {
+		name                  string
+		handler               http.Handler
+		requestMethod         string
+		requestBody           any
+		rawBody               string
+		expectedStatus        int
+		expectedDataContains  string
+		expectedErrorCode     string
+		expectedInputBookings int // -1 when input_counts must be absent
+		expectedHeaders       map[string]string
+	}{
+		{
+			name:                  "Maximize",
+			handler:               http.HandlerFunc(MaximizeProfitHandler),
+			requestMethod:         http.MethodPost,
+			requestBody:           bookings,
+			expectedStatus:        http.StatusOK,
+			expectedDataContains:  `"request_ids":["B2"]`,
+			expectedInputBookings: 2,
+		},
+		{
+			name:                  "Stats",
+			handler:               http.HandlerFunc(StatsHandler),
+			requestMethod:         http.MethodPost,
+			requestBody:           bookings,
+			expectedStatus:        http.StatusOK,
+			expectedDataContains:  `"max_night":45`,
+			expectedInputBookings: 2,
+		},
+		{
+			name:                  "Empty Request",
+			handler:               http.HandlerFunc(StatsHandler),
+			requestMethod:         http.MethodPost,
+			requestBody:           []types.BookingRequest{},
+			expectedStatus:        http.StatusOK,
+			expectedDataContains:  `"avg_night":0`,
+			expectedInputBookings: 0,
+		},
+		{
+			name:                  "Validation Error",
+			handler:               http.HandlerFunc(MaximizeProfitHandler),
+			requestMethod:         http.MethodPost,
+			requestBody:           []types.BookingRequest{{RequestID: "B1", Checkin: "2024-01-01", Nights: 0, SellingRate: 100, Margin: 10}},
+			expectedStatus:        http.StatusBadRequest,
+			expectedErrorCode:     "bad_request",
+			expectedInputBookings: 1,
+		},
+		{
+			name:                  "Invalid JSON",
+			handler:               http.HandlerFunc(MaximizeProfitHandler),
+			requestMethod:         http.MethodPost,
+			rawBody:               `{"request_id":`,
+			expectedStatus:        http.StatusBadRequest,
+			expectedErrorCode:     "bad_request",
+			expectedInputBookings: -1,
+		},
+		{
+			name:                  "Rate Limited",
+			handler:               throttled,
+			requestMethod:         http.MethodPost,
+			requestBody:           bookings,
+			expectedStatus:        http.StatusTooManyRequests,
+			expectedErrorCode:     "too_many_requests",
+			expectedInputBookings: -1,
+			expectedHeaders:       map[string]string{"Retry-After": "1000"},
+		},
+	}
+
+	// --- Execute Scenarios ---
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var req *http.Request
+			if tc.rawBody != "" {
+				req = testutil.NewRawTestRequest(t, tc.requestMethod, "/v2/maximize", tc.rawBody)
+			} else {
+				req = testutil.NewTestRequest(t, tc.requestMethod, "/v2/maximize", tc.requestBody)
+			}
+			req.Header.Set(RequestIDHeader, "test-request-id")
+			recorder := httptest.NewRecorder()
+			RequestID(Envelope(tc.handler)).ServeHTTP(recorder, req)
+
+			if recorder.Code != tc.expectedStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v. Body: %s", recorder.Code, tc.expectedStatus, recorder.Body.String())
+			}
+			for name, value := range tc.expectedHeaders {
+				if got := recorder.Header().Get(name); got != value {
+					t.Errorf("%s = %q, want %q", name, got, value)
+				}
+			}
+
+			var envelope types.Envelope
+			if err := json.Unmarshal(recorder.Body.Bytes(), &envelope); err != nil {
+				t.Fatalf("handler returned invalid JSON: %v", err)
+			}
+			if envelope.Meta.RequestID != "test-request-id" {
+				t.Errorf("meta.request_id = %q, want %q", envelope.Meta.RequestID, "test-request-id")
+			}
+			if envelope.Meta.Timing.DurationMs < 0 {
+				t.Errorf("meta.timing.duration_ms = %v, want a duration", envelope.Meta.Timing.DurationMs)
+			}
+			switch {
+			case tc.expectedInputBookings < 0 && envelope.Meta.InputCounts != nil:
+				t.Errorf("meta.input_counts = %+v, want it absent", *envelope.Meta.InputCounts)
+			case tc.expectedInputBookings >= 0 && (envelope.Meta.InputCounts == nil || envelope.Meta.InputCounts.Bookings != tc.expectedInputBookings):
+				t.Errorf("meta.input_counts = %+v, want %d bookings", envelope.Meta.InputCounts, tc.expectedInputBookings)
+			}
+
+			if tc.expectedErrorCode == "" {
+				if len(envelope.Errors) != 0 {
+					t.Errorf("errors = %+v, want none", envelope.Errors)
+				}
+				if !strings.Contains(string(envelope.Data), tc.expectedDataContains) {
+					t.Errorf("data = %s, want substring %q", envelope.Data, tc.expectedDataContains)
+				}
+				return
+			}
+			if string(envelope.Data) != "null" {
+				t.Errorf("data = %s, want null", envelope.Data)
+			}
+			if len(envelope.Errors) != 1 || envelope.Errors[0].Code != tc.expectedErrorCode || envelope.Errors[0].Message == "" {
+				t.Errorf("errors = %+v, want one %q error with a message", envelope.Errors, tc.expectedErrorCode)
+			}
+		})
+	}
+}
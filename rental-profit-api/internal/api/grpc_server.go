@@ -0,0 +1,280 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"net"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"rental-profit-api/internal/booking"
+	"rental-profit-api/internal/ratelimit"
+	"rental-profit-api/internal/rentalpb"
+	"rental-profit-api/internal/types"
+)
+
+// GRPCServer serves rentalpb.RentalService. Bookings go through the same
+// validation, limits, compute slots and result cache as on /maximize and
+// /stats, so both transports always agree.
+type GRPCServer struct {
+	rentalpb.UnimplementedRentalServiceServer
+	limiter *ratelimit.Limiter
+}
+
+// NewGRPCServer returns the service. limiter should be the one given to
+// GRPCServerOptions: it admits a MaximizeBatch call once, and every booking
+// list after the first is charged here like a call of its own. A nil
+// limiter disables that, as it disables rate limiting.
+func NewGRPCServer(limiter *ratelimit.Limiter) *GRPCServer {
+	return &GRPCServer{limiter: limiter}
+}
+
+func (s *GRPCServer) Maximize(ctx context.Context, req *rentalpb.MaximizeRequest) (*rentalpb.MaximizeResponse, error) {
+	return s.maximize(ctx, rentalpb.RentalService_Maximize_FullMethodName, req.GetBookings())
+}
+
+func (s *GRPCServer) Stats(ctx context.Context, req *rentalpb.StatsRequest) (*rentalpb.StatsResponse, error) {
+	inputBookings.Observe(float64(len(req.GetBookings())), rentalpb.RentalService_Stats_FullMethodName)
+	domainBookings, err := limitsFor(ctx).validateAndMapBookings(fromProtoBookings(req.GetBookings()))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	if len(domainBookings) == 0 {
+		return &rentalpb.StatsResponse{}, nil
+	}
+
+	statsResult, _ := cachedResult("/stats", resultETag(ctx, "/stats", domainBookings), statsResponseSize, func() (types.StatsResponse, error) {
+		return booking.CalculateOverallStats(domainBookings), nil
+	})
+	return &rentalpb.StatsResponse{
+		AvgNight: statsResult.AvgProfitPerNight,
+		MinNight: statsResult.MinProfitPerNight,
+		MaxNight: statsResult.MaxProfitPerNight,
+	}, nil
+}
+
+// MaximizeBatch optimizes the requests one after the other, sending each
+// result as soon as it is ready. A request that fails is reported in its
+// result; the stream only ends early when the client goes away or runs out
+// of rate limit, which every request counts against.
+func (s *GRPCServer) MaximizeBatch(req *rentalpb.MaximizeBatchRequest, stream grpc.ServerStreamingServer[rentalpb.MaximizeBatchResult]) error {
+	ctx := stream.Context()
+	for i, request := range req.GetRequests() {
+		if err := ctx.Err(); err != nil {
+			return status.FromContextError(err).Err()
+		}
+		if limiter := rateLimiterFor(ctx, s.limiter); i > 0 && limiter != nil {
+			if allowed, wait := limiter.Allow(grpcClientID(ctx)); !allowed {
+				throttledRequests.Inc("rate_limit")
+				return status.Errorf(codes.ResourceExhausted, "Rate limit exceeded after %d of %d requests, retry in %ds", i, len(req.GetRequests()), int(math.Ceil(wait.Seconds())))
+			}
+		}
+		result := &rentalpb.MaximizeBatchResult{Index: int32(i)}
+		response, err := s.maximize(ctx, rentalpb.RentalService_MaximizeBatch_FullMethodName, request.GetBookings())
+		if err != nil {
+			st := status.Convert(err)
+			result.Outcome = &rentalpb.MaximizeBatchResult_Error{Error: &rentalpb.Error{Code: int32(st.Code()), Message: st.Message()}}
+		} else {
+			result.Outcome = &rentalpb.MaximizeBatchResult_Response{Response: response}
+		}
+		if err := stream.Send(result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// maximize serves one booking list the way MaximizeProfitHandler does in
+// deterministic mode; method labels the input size metric.
+func (s *GRPCServer) maximize(ctx context.Context, method string, bookings []*rentalpb.Booking) (*rentalpb.MaximizeResponse, error) {
+	inputBookings.Observe(float64(len(bookings)), method)
+	domainBookings, err := limitsFor(ctx).validateAndMapBookings(fromProtoBookings(bookings))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	if len(domainBookings) == 0 {
+		return &rentalpb.MaximizeResponse{RequestIds: []string{}}, nil
+	}
+
+	scheduleResult, err := cachedResult("/maximize", resultETag(ctx, "/maximize", domainBookings), scheduleResultSize, func() (booking.ScheduleResult, error) {
+		ctx, cancel := withComputeBudget(ctx)
+		defer cancel()
+		return findMaxProfit(ctx, domainBookings)
+	})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	selected := len(scheduleResult.OptimalSchedule)
+	scheduledBookings.Add(float64(selected), "selected")
+	scheduledBookings.Add(float64(len(domainBookings)-selected), "rejected")
+
+	response := ToMaximizeResponse(scheduleResult)
+	return &rentalpb.MaximizeResponse{
+		RequestIds:  response.RequestIDs,
+		TotalProfit: response.TotalProfit,
+		AvgNight:    response.AvgNight,
+		MinNight:    response.MinNight,
+		MaxNight:    response.MaxNight,
+	}, nil
+}
+
+func fromProtoBookings(bookings []*rentalpb.Booking) []types.BookingRequest {
+	requests := make([]types.BookingRequest, len(bookings))
+	for i, b := range bookings {
+		requests[i] = types.BookingRequest{
+			RequestID:         b.GetRequestId(),
+			Checkin:           b.GetCheckIn(),
+			Nights:            int(b.GetNights()),
+			SellingRate:       b.GetSellingRate(),
+			Margin:            b.GetMargin(),
+			CancelProbability: b.GetCancelProbability(),
+		}
+	}
+	return requests
+}
+
+// grpcError maps the errors behind the HTTP status codes of respondInputError
+// and respondComputeError to gRPC status codes.
+func grpcError(err error) error {
+	code := codes.Internal
+	switch {
+	case errors.Is(err, ErrValidation), errors.Is(err, ErrLimitExceeded):
+		code = codes.InvalidArgument
+	case errors.Is(err, ErrTooManyBookings), errors.Is(err, ratelimit.ErrQueueFull):
+		code = codes.ResourceExhausted
+	case errors.Is(err, ErrComputeBudgetExceeded), errors.Is(err, context.DeadlineExceeded):
+		code = codes.DeadlineExceeded
+	case errors.Is(err, context.Canceled):
+		code = codes.Canceled
+	}
+	return status.Error(code, err.Error())
+}
+
+// GRPCServerOptions guards the gRPC service the way the HTTP middleware
+// chain guards the JSON API: every call is counted and timed, recovered from
+// panics, authenticated with the API keys and rate limited per key. Nil keys
+// or a nil limiter disable those steps, as they do for HTTP.
+func GRPCServerOptions(logger *slog.Logger, keys *APIKeys, limiter *ratelimit.Limiter) []grpc.ServerOption {
+	guard := &grpcGuard{logger: logger, keys: keys, limiter: limiter}
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(guard.unary),
+		grpc.ChainStreamInterceptor(guard.stream),
+	}
+}
+
+type grpcGuard struct {
+	logger  *slog.Logger
+	keys    *APIKeys
+	limiter *ratelimit.Limiter
+}
+
+func (g *grpcGuard) unary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	defer g.observe(ctx, info.FullMethod, time.Now(), &err)
+	ctx, err = g.admit(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (g *grpcGuard) stream(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer g.observe(stream.Context(), info.FullMethod, time.Now(), &err)
+	ctx, err := g.admit(stream.Context())
+	if err != nil {
+		return err
+	}
+	return handler(srv, &admittedStream{ServerStream: stream, ctx: ctx})
+}
+
+// observe turns a panic into codes.Internal and records the call. It has to
+// be deferred directly so it can recover.
+func (g *grpcGuard) observe(ctx context.Context, method string, start time.Time, err *error) {
+	if panicValue := recover(); panicValue != nil {
+		g.logger.ErrorContext(ctx, "Panic while serving gRPC call",
+			"method", method,
+			"panic", panicValue,
+			"stack", string(debug.Stack()),
+		)
+		*err = status.Error(codes.Internal, "Internal Server Error")
+	}
+	code := status.Code(*err).String()
+	grpcRequests.Inc(method, code)
+	grpcRequestDuration.Observe(time.Since(start).Seconds(), method, code)
+}
+
+// admit authenticates and rate limits a call like Authenticate and
+// RateLimit do, returning the context to serve it under. The tenant's
+// settings in Tenants apply to the call from there on.
+func (g *grpcGuard) admit(ctx context.Context) (context.Context, error) {
+	if g.keys != nil {
+		key := apiKeyFromMetadata(ctx)
+		if key == "" {
+			authFailures.Inc("missing_key")
+			return nil, status.Error(codes.Unauthenticated, "Missing API key")
+		}
+		tenant, keyID, ok := g.keys.lookup(key)
+		if !ok {
+			authFailures.Inc("invalid_key")
+			return nil, status.Error(codes.Unauthenticated, "Invalid API key")
+		}
+		ctx = context.WithValue(ctx, tenantKey{}, tenant)
+		ctx = context.WithValue(ctx, apiKeyIDKey{}, keyID)
+	}
+	if limiter := rateLimiterFor(ctx, g.limiter); limiter != nil {
+		if allowed, wait := limiter.Allow(grpcClientID(ctx)); !allowed {
+			throttledRequests.Inc("rate_limit")
+			return nil, status.Errorf(codes.ResourceExhausted, "Rate limit exceeded, retry in %ds", int(math.Ceil(wait.Seconds())))
+		}
+	}
+	return ctx, nil
+}
+
+// apiKeyFromMetadata reads the API key from the same headers as
+// apiKeyFromRequest; gRPC metadata keys are lower case.
+func apiKeyFromMetadata(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get(strings.ToLower(APIKeyHeader)); len(values) > 0 && values[0] != "" {
+		return values[0]
+	}
+	if values := md.Get("authorization"); len(values) > 0 {
+		scheme, token, ok := strings.Cut(values[0], " ")
+		if ok && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+	}
+	return ""
+}
+
+// grpcClientID names the caller for rate limiting like clientID does.
+func grpcClientID(ctx context.Context) string {
+	if keyID, ok := ctx.Value(apiKeyIDKey{}).(string); ok {
+		return "key:" + keyID
+	}
+	host := "unknown"
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		host = p.Addr.String()
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+	}
+	return "ip:" + host
+}
+
+// admittedStream serves a stream under the context admit returned.
+type admittedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *admittedStream) Context() context.Context {
+	return s.ctx
+}
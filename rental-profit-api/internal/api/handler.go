@@ -2,10 +2,8 @@ package api
 
 import (
 	"fmt"
-	"math"
 	"errors"
 	"net/http"
-	"encoding/json"
 	"time"
 
 	"rental-profit-api/internal/booking"
@@ -13,22 +11,31 @@ import (
 )
 
 func MaximizeProfitHandler(w http.ResponseWriter, r *http.Request) {
+	policy, stochastic, err := parseOverbookingPolicy(r.URL.Query(), overbookingPolicyFor(r.Context()))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	var bookingRequest []types.BookingRequest
-	err := json.NewDecoder(r.Body).Decode(&bookingRequest)
+	err = decodeJSONList(r, &bookingRequest)
 	if err != nil {
-		respondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid JSON format: %v", err))
+		respondDecodeError(w, err)
 		return
 	}
 	defer r.Body.Close()
+	observeInputBookings(r, "/maximize", len(bookingRequest))
 
 	// Validate the request content and format
-	domainBookings, err := validateAndMapBookings(bookingRequest) 
+	domainBookings, err := limitsFor(r.Context()).validateAndMapBookings(bookingRequest) 
 	if err != nil {
-		if errors.Is(err, ErrValidation) {
-			respondError(w, http.StatusBadRequest, err.Error())
-		} else {
-			respondError(w, http.StatusInternalServerError, "Internal server error")
-		}
+		respondInputError(w, err)
+		return
+	}
+
+	// Stochastic mode maximizes expected profit and may overbook
+	if stochastic {
+		respondExpectedProfit(w, r, domainBookings, policy)
 		return
 	}
 
@@ -44,67 +51,45 @@ func MaximizeProfitHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Execute business logic
-	var scheduleResult booking.ScheduleResult
-	var panicErr any
-	func() {
-		defer func() {
-			if r := recover(); r != nil {
-				panicErr = r
-			}
-		}()
-		scheduleResult = booking.FindMaxProfit(domainBookings)
-	}()
-
-	if panicErr != nil {
-		respondError(w, http.StatusInternalServerError, "Internal Server Error")
+	etag := resultETag(r.Context(), "/maximize", domainBookings)
+	if respondNotModified(w, r, "/maximize", etag) {
 		return
 	}
 
-	requestIDs := make([]string, len(scheduleResult.OptimalSchedule))
-	for i, b := range scheduleResult.OptimalSchedule {
-		requestIDs[i] = b.RequestID
+	// Execute business logic, unless the result is cached
+	scheduleResult, err := cachedResult("/maximize", etag, scheduleResultSize, func() (booking.ScheduleResult, error) {
+		ctx, cancel := withComputeBudget(r.Context())
+		defer cancel()
+		return findMaxProfit(ctx, domainBookings)
+	})
+	if err != nil {
+		respondComputeError(w, err)
+		return
 	}
+	selected := len(scheduleResult.OptimalSchedule)
+	scheduledBookings.Add(float64(selected), "selected")
+	scheduledBookings.Add(float64(len(domainBookings)-selected), "rejected")
 
-	// Apply Rounding for Presentation
-	totalProfitRounded := math.Round(scheduleResult.TotalProfit*100) / 100
-	avgNightRounded := math.Round(scheduleResult.AvgProfitPerNight*100) / 100
-	minNightRounded := math.Round(scheduleResult.MinProfitPerNight*100) / 100
-	maxNightRounded := math.Round(scheduleResult.MaxProfitPerNight*100) / 100
-
-	response := types.MaximizeResponse{
-		RequestIDs:  requestIDs,
-		TotalProfit: totalProfitRounded,
-		AvgNight:    avgNightRounded,
-		MinNight:    minNightRounded,
-		MaxNight:    maxNightRounded,
-	}
+	response := ToMaximizeResponse(scheduleResult)
 
+	w.Header().Set("ETag", etag)
 	respondJSON(w, http.StatusOK, response)
 }
 
 func StatsHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		respondError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
-		return
-	}
-
 	var bookingRequest []types.BookingRequest 
-	err := json.NewDecoder(r.Body).Decode(&bookingRequest)
+	err := decodeJSONList(r, &bookingRequest)
 	if err != nil {
-		respondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid JSON format: %v", err))
+		respondDecodeError(w, err)
 		return
 	}
 	defer r.Body.Close()
+	observeInputBookings(r, "/stats", len(bookingRequest))
 
 	// Validate the request content and format
-	domainBookings, err := validateAndMapBookings(bookingRequest)
+	domainBookings, err := limitsFor(r.Context()).validateAndMapBookings(bookingRequest)
 	if err != nil {
-		if errors.Is(err, ErrValidation) {
-			respondError(w, http.StatusBadRequest, err.Error())
-		} else {
-			respondError(w, http.StatusInternalServerError, "Internal Server Error")
-		}
+		respondInputError(w, err)
 		return
 	}
 
@@ -118,54 +103,83 @@ func StatsHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Execute business logic
-	var statsResult types.StatsResponse
-	var panicErr any // Use any for panic value
-	func() {
-		defer func() {
-			if r := recover(); r != nil {
-				panicErr = r
-			}
-		}()
-		statsResult = booking.CalculateOverallStats(domainBookings)
-	}()
-
-	if panicErr != nil {
-		respondError(w, http.StatusInternalServerError, "Internal Server Error")
+	etag := resultETag(r.Context(), "/stats", domainBookings)
+	if respondNotModified(w, r, "/stats", etag) {
 		return
 	}
 
+	// Execute business logic, unless the result is cached
+	statsResult, _ := cachedResult("/stats", etag, statsResponseSize, func() (types.StatsResponse, error) {
+		return booking.CalculateOverallStats(domainBookings), nil
+	})
+
+	w.Header().Set("ETag", etag)
 	respondJSON(w, http.StatusOK, statsResult)
 }
 
+// ToMaximizeResponse applies rounding for presentation, as every endpoint
+// returning a schedule does.
+func ToMaximizeResponse(scheduleResult booking.ScheduleResult) types.MaximizeResponse {
+	return types.MaximizeResponse{
+		RequestIDs:  requestIDsOf(scheduleResult.OptimalSchedule),
+		TotalProfit: roundToCents(scheduleResult.TotalProfit),
+		AvgNight:    roundToCents(scheduleResult.AvgProfitPerNight),
+		MinNight:    roundToCents(scheduleResult.MinProfitPerNight),
+		MaxNight:    roundToCents(scheduleResult.MaxProfitPerNight),
+	}
+}
+
 var ErrValidation = errors.New("validation error")
 
-func validateAndMapBookings(requestItems []types.BookingRequest) ([]booking.Booking, error) {
+// ValidateAndMapBookings checks booking requests against DefaultLimits and
+// maps them to domain bookings. Errors wrap ErrValidation, ErrTooManyBookings
+// or ErrLimitExceeded and name the offending item.
+func ValidateAndMapBookings(requestItems []types.BookingRequest) ([]booking.Booking, error) {
+	return DefaultLimits.validateAndMapBookings(requestItems)
+}
+
+// validateAndMapBookings does ValidateAndMapBookings against l, such as the
+// limits of the requesting tenant.
+func (l Limits) validateAndMapBookings(requestItems []types.BookingRequest) ([]booking.Booking, error) {
+	if err := l.checkBookingCount(len(requestItems)); err != nil {
+		return nil, err
+	}
 	domainBookings := make([]booking.Booking, 0, len(requestItems))
 	for i, item := range requestItems {
 		if item.RequestID == "" {
-			return nil, fmt.Errorf("%w: request_id missing on item %d", ErrValidation, i)
+			return nil, validationError("request_id", "request_id missing on item %d", i)
 		}
 		checkinDate, err := time.Parse(booking.DateLayout, item.Checkin)
 		if err != nil {
-			return nil, fmt.Errorf("%w: check_in format error on item %d: %w", ErrValidation, i, err)
+			return nil, validationError("check_in", "check_in format error on item %d: %w", i, err)
 		}
 		if item.Nights <= 0 {
-			return nil, fmt.Errorf("%w: nights must be positive on item %d", ErrValidation, i)
+			return nil, validationError("nights", "nights must be positive on item %d", i)
 		}
 		if item.SellingRate <= 0 {
-			return nil, fmt.Errorf("%w: selling rate must be positive on item %d", ErrValidation, i)
+			return nil, validationError("selling_rate", "selling rate must be positive on item %d", i)
 		}
 		if item.Margin <= 0 {
-			return nil, fmt.Errorf("%w: margin must be positive on item %d", ErrValidation, i)
+			return nil, validationError("margin", "margin must be positive on item %d", i)
 		}
-		domainBookings = append(domainBookings, booking.Booking{
-			RequestID:   item.RequestID,
-			Checkin:     checkinDate,
-			Nights:      item.Nights,
-			SellingRate: item.SellingRate,
-			Margin:      item.Margin,
-		})
+		if item.CancelProbability < 0 || item.CancelProbability > 1 {
+			return nil, validationError("cancel_probability", "cancel_probability must be between 0 and 1 on item %d", i)
+		}
+		domainBooking := booking.Booking{
+			RequestID:         item.RequestID,
+			Checkin:           checkinDate,
+			Nights:            item.Nights,
+			SellingRate:       item.SellingRate,
+			Margin:            item.Margin,
+			CancelProbability: item.CancelProbability,
+		}
+		if err := l.checkBooking(domainBooking, fmt.Sprintf("item %d", i)); err != nil {
+			return nil, err
+		}
+		domainBookings = append(domainBookings, domainBooking)
+	}
+	if err := l.checkDateSpan(domainBookings); err != nil {
+		return nil, err
 	}
 	return domainBookings, nil
 }
\ No newline at end of file
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+
+	"rental-profit-api/internal/buildinfo"
+	"rental-profit-api/internal/types"
+)
+
+// Health serves the liveness and readiness probes. Readiness fails once
+// shutdown has started or when any registered dependency check fails.
+type Health struct {
+	shuttingDown atomic.Bool
+	checks       map[string]func(context.Context) error
+}
+
+func NewHealth() *Health {
+	return &Health{checks: make(map[string]func(context.Context) error)}
+}
+
+// AddCheck registers a dependency, such as a storage backend, that must be
+// reachable for the server to be ready. Register checks before serving.
+func (h *Health) AddCheck(name string, check func(context.Context) error) {
+	h.checks[name] = check
+}
+
+// SetShuttingDown makes readiness fail so load balancers stop routing new
+// traffic while in-flight requests drain.
+func (h *Health) SetShuttingDown() {
+	h.shuttingDown.Store(true)
+}
+
+// Liveness handles GET /healthz. It only proves the process is serving.
+func (h *Health) Liveness(w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, http.StatusOK, types.HealthResponse{Status: "ok"})
+}
+
+// Readiness handles GET /readyz.
+func (h *Health) Readiness(w http.ResponseWriter, r *http.Request) {
+	if h.shuttingDown.Load() {
+		respondJSON(w, http.StatusServiceUnavailable, types.HealthResponse{Status: "shutting_down"})
+		return
+	}
+
+	response := types.HealthResponse{Status: "ready", Checks: make(map[string]string, len(h.checks))}
+	code := http.StatusOK
+	for name, check := range h.checks {
+		if err := check(r.Context()); err != nil {
+			response.Checks[name] = err.Error()
+			response.Status = "not_ready"
+			code = http.StatusServiceUnavailable
+		} else {
+			response.Checks[name] = "ok"
+		}
+	}
+	respondJSON(w, code, response)
+}
+
+// VersionHandler handles GET /version.
+func VersionHandler(w http.ResponseWriter, r *http.Request) {
+	info := buildinfo.Read()
+	respondJSON(w, http.StatusOK, types.VersionResponse{
+		Version:    info.Version,
+		Revision:   info.Revision,
+		CommitTime: info.CommitTime,
+		BuildTime:  info.BuildTime,
+		Modified:   info.Modified,
+		GoVersion:  info.GoVersion,
+	})
+}
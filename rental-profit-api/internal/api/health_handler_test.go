@@ -0,0 +1,106 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"rental-profit-api/internal/testutil"
+)
+
+func TestHealth(t *testing.T) {
+	// --- Define Test Scenarios ---
+	testCases := []struct {
+		name                 string
+		setup                func(*Health)
+		handler              func(*Health) http.HandlerFunc
+		expectedStatus       int
+		expectedBodyContains string
+	}{
+		{
+			name:                 "Liveness",
+			handler:              func(h *Health) http.HandlerFunc { return h.Liveness },
+			expectedStatus:       http.StatusOK,
+			expectedBodyContains: `{"status":"ok"}`,
+		},
+		{
+			name:                 "Liveness While Shutting Down",
+			setup:                func(h *Health) { h.SetShuttingDown() },
+			handler:              func(h *Health) http.HandlerFunc { return h.Liveness },
+			expectedStatus:       http.StatusOK,
+			expectedBodyContains: `{"status":"ok"}`,
+		},
+		{
+			name:                 "Ready Without Checks",
+			handler:              func(h *Health) http.HandlerFunc { return h.Readiness },
+			expectedStatus:       http.StatusOK,
+			expectedBodyContains: `{"status":"ready"}`,
+		},
+		{
+			name: "Ready With Passing Check",
+			setup: func(h *Health) {
+				h.AddCheck("calendar_store", func(ctx context.Context) error { return nil })
+			},
+			handler:              func(h *Health) http.HandlerFunc { return h.Readiness },
+			expectedStatus:       http.StatusOK,
+			expectedBodyContains: `{"status":"ready","checks":{"calendar_store":"ok"}}`,
+		},
+		{
+			name: "Not Ready With Failing Check",
+			setup: func(h *Health) {
+				h.AddCheck("calendar_store", func(ctx context.Context) error { return nil })
+				h.AddCheck("database", func(ctx context.Context) error { return errors.New("connection refused") })
+			},
+			handler:              func(h *Health) http.HandlerFunc { return h.Readiness },
+			expectedStatus:       http.StatusServiceUnavailable,
+			expectedBodyContains: `{"status":"not_ready","checks":{"calendar_store":"ok","database":"connection refused"}}`,
+		},
+		{
+			name:                 "Not Ready While Shutting Down",
+			setup:                func(h *Health) { h.SetShuttingDown() },
+			handler:              func(h *Health) http.HandlerFunc { return h.Readiness },
+			expectedStatus:       http.StatusServiceUnavailable,
+			expectedBodyContains: `{"status":"shutting_down"}`,
+		},
+	}
+
+	// --- Execute Scenarios ---
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			health := NewHealth()
+			if tt.setup != nil {
+				tt.setup(health)
+			}
+			req := testutil.NewTestRequest(t, http.MethodGet, "/", nil)
+			recorder := httptest.NewRecorder()
+
+			tt.handler(health)(recorder, req)
+
+			if status := recorder.Code; status != tt.expectedStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v", status, tt.expectedStatus)
+				t.Logf("Response Body: %s", recorder.Body.String())
+			}
+
+			if !strings.Contains(recorder.Body.String(), tt.expectedBodyContains) {
+				t.Errorf("handler returned unexpected body: got %q want substring %q", recorder.Body.String(), tt.expectedBodyContains)
+			}
+		})
+	}
+}
+
+func TestVersionHandler(t *testing.T) {
+	req := testutil.NewTestRequest(t, http.MethodGet, "/version", nil)
+	recorder := httptest.NewRecorder()
+
+	VersionHandler(recorder, req)
+
+	if status := recorder.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+	if !strings.Contains(recorder.Body.String(), `"go_version":"go`) {
+		t.Errorf("handler returned unexpected body: got %q want the Go version", recorder.Body.String())
+	}
+}
@@ -0,0 +1,137 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"net/http"
+
+	"rental-profit-api/internal/idempotency"
+)
+
+const (
+	// IdempotencyKeyHeader lets a client retry a request without repeating
+	// its effect.
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader marks a response replayed for a retry.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+)
+
+// maxIdempotencyKeyLength bounds idempotency keys, which are kept in memory.
+const maxIdempotencyKeyLength = 255
+
+// replayedHeaders are the response headers stored along with the body. The
+// rest, such as X-Request-ID, belong to the retry rather than the original.
+var replayedHeaders = []string{"Content-Type", "Location"}
+
+// Idempotent lets clients send an Idempotency-Key with POST, PUT, PATCH and
+// DELETE requests. The first request with a key is served and its response
+// stored for the tenant; retries with the same key, method, path and body
+// get that response back, marked with Idempotent-Replayed, instead of being
+// served again. Reusing a key for a different request, or while its first
+// request is still being served, gets 409. Server errors and 429s are not
+// stored, so those requests can be retried for real.
+//
+// It needs the tenant, so it has to run after Authenticate. With a nil store
+// every request is served as is.
+func Idempotent(store *idempotency.Store) Middleware {
+	return func(next http.Handler) http.Handler {
+		if store == nil {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			switch r.Method {
+			case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+			default:
+				key = ""
+			}
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxIdempotencyKeyLength {
+				respondInputError(w, validationError("idempotency_key", "%s must be at most %d characters", IdempotencyKeyHeader, maxIdempotencyKeyLength))
+				return
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				respondDecodeError(w, err)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			tenant := TenantFromContext(r.Context())
+			stored, err := store.Begin(tenant, key, requestFingerprint(r, body))
+			switch {
+			case errors.Is(err, idempotency.ErrMismatch):
+				idempotentRequests.Inc("mismatch")
+				respondError(w, http.StatusConflict, IdempotencyKeyHeader+" was already used for a different request")
+				return
+			case errors.Is(err, idempotency.ErrInProgress):
+				idempotentRequests.Inc("in_progress")
+				w.Header().Set("Retry-After", "1")
+				respondError(w, http.StatusConflict, "A request with this "+IdempotencyKeyHeader+" is still being processed")
+				return
+			case stored != nil:
+				idempotentRequests.Inc("replayed")
+				replay(w, stored)
+				return
+			}
+
+			// Release the key if next panics, so a retry is served
+			completed := false
+			defer func() {
+				if !completed {
+					store.Abandon(tenant, key)
+				}
+			}()
+			capture := &responseCapture{statusRecorder: &statusRecorder{ResponseWriter: w, status: http.StatusOK}}
+			next.ServeHTTP(capture, r)
+
+			if capture.status >= http.StatusInternalServerError || capture.status == http.StatusTooManyRequests {
+				return
+			}
+			header := make(http.Header)
+			for _, name := range replayedHeaders {
+				if value := w.Header().Get(name); value != "" {
+					header.Set(name, value)
+				}
+			}
+			store.Complete(tenant, key, idempotency.Response{Status: capture.status, Header: header, Body: capture.body.Bytes()})
+			completed = true
+			idempotentRequests.Inc("stored")
+		})
+	}
+}
+
+// requestFingerprint covers everything that makes a retry the same request.
+func requestFingerprint(r *http.Request, body []byte) idempotency.Fingerprint {
+	digest := sha256.New()
+	io.WriteString(digest, r.Method+" "+r.URL.Path+"\n")
+	digest.Write(body)
+	return idempotency.Fingerprint(digest.Sum(nil))
+}
+
+func replay(w http.ResponseWriter, response *idempotency.Response) {
+	for name, values := range response.Header {
+		w.Header()[name] = values
+	}
+	w.Header().Set(IdempotentReplayedHeader, "true")
+	w.WriteHeader(response.Status)
+	w.Write(response.Body)
+}
+
+// responseCapture keeps a copy of the body written through it.
+type responseCapture struct {
+	*statusRecorder
+	body bytes.Buffer
+}
+
+func (c *responseCapture) Write(body []byte) (int, error) {
+	n, err := c.statusRecorder.Write(body)
+	c.body.Write(body[:n])
+	return n, err
+}
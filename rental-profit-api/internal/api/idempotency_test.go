@@ -0,0 +1,96 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"rental-profit-api/internal/idempotency"
+	"rental-profit-api/internal/testutil"
+)
+
+func TestIdempotent(t *testing.T) {
+	// The stub numbers the requests it serves, so a replay is told apart
+	// from a request served again
+	served := 0
+	stub := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		served++
+		if r.URL.Path == "/fail" {
+			respondError(w, http.StatusServiceUnavailable, "try again")
+			return
+		}
+		w.Header().Set("Location", "/calendars/c1")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"served":%d}`, served)
+	})
+	handler := Authenticate(testAPIKeys)(Idempotent(idempotency.NewStore(time.Hour, 1<<20))(stub))
+
+	const acme, globex = "acme-0123456789abcdef", "globex-0123456789abcdef"
+
+	// --- Define Test Scenarios ---
+	testCases := []struct {
+		name             string
+		apiKey           string
+		method           string
+		path             string
+		idempotencyKey   string
+		body             string
+		expectedStatus   int
+		expectedBody     string
+		expectedReplayed bool
+	}{
+		{name: "First request", apiKey: acme, method: http.MethodPost, path: "/calendars", idempotencyKey: "k1", body: "[]",
+			expectedStatus: http.StatusCreated, expectedBody: `{"served":1}`},
+		{name: "Retry replays the response", apiKey: acme, method: http.MethodPost, path: "/calendars", idempotencyKey: "k1", body: "[]",
+			expectedStatus: http.StatusCreated, expectedBody: `{"served":1}`, expectedReplayed: true},
+		{name: "Key reused with another body", apiKey: acme, method: http.MethodPost, path: "/calendars", idempotencyKey: "k1", body: "[{}]",
+			expectedStatus: http.StatusConflict, expectedBody: "already used for a different request"},
+		{name: "Key reused on another path", apiKey: acme, method: http.MethodPost, path: "/webhooks", idempotencyKey: "k1", body: "[]",
+			expectedStatus: http.StatusConflict, expectedBody: "already used for a different request"},
+		{name: "Same key of another tenant", apiKey: globex, method: http.MethodPost, path: "/calendars", idempotencyKey: "k1", body: "[]",
+			expectedStatus: http.StatusCreated, expectedBody: `{"served":2}`},
+		{name: "No key", apiKey: acme, method: http.MethodPost, path: "/calendars", body: "[]",
+			expectedStatus: http.StatusCreated, expectedBody: `{"served":3}`},
+		{name: "Safe method ignores the key", apiKey: acme, method: http.MethodGet, path: "/calendars", idempotencyKey: "k1",
+			expectedStatus: http.StatusCreated, expectedBody: `{"served":4}`},
+		{name: "Server error is not stored", apiKey: acme, method: http.MethodDelete, path: "/fail", idempotencyKey: "k2",
+			expectedStatus: http.StatusServiceUnavailable, expectedBody: "try again"},
+		{name: "Retry after a server error is served", apiKey: acme, method: http.MethodDelete, path: "/fail", idempotencyKey: "k2",
+			expectedStatus: http.StatusServiceUnavailable, expectedBody: "try again"},
+		{name: "Key too long", apiKey: acme, method: http.MethodPost, path: "/calendars", idempotencyKey: strings.Repeat("k", maxIdempotencyKeyLength+1), body: "[]",
+			expectedStatus: http.StatusBadRequest, expectedBody: "must be at most 255 characters"},
+	}
+
+	// --- Execute Scenarios ---
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := testutil.NewRawTestRequest(t, tc.method, tc.path, tc.body)
+			req.Header.Set(APIKeyHeader, tc.apiKey)
+			if tc.idempotencyKey != "" {
+				req.Header.Set(IdempotencyKeyHeader, tc.idempotencyKey)
+			}
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			if recorder.Code != tc.expectedStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v. Body: %s", recorder.Code, tc.expectedStatus, recorder.Body.String())
+			}
+			if !strings.Contains(recorder.Body.String(), tc.expectedBody) {
+				t.Errorf("handler returned unexpected body: got %q want substring %q", recorder.Body.String(), tc.expectedBody)
+			}
+			if replayed := recorder.Header().Get(IdempotentReplayedHeader) == "true"; replayed != tc.expectedReplayed {
+				t.Errorf("%s = %v, want %v", IdempotentReplayedHeader, replayed, tc.expectedReplayed)
+			}
+			if tc.expectedReplayed && recorder.Header().Get("Location") != "/calendars/c1" {
+				t.Errorf("replay lost the Location header: %v", recorder.Header())
+			}
+		})
+	}
+	if served != 6 {
+		t.Errorf("handler served %d requests, want 6", served)
+	}
+}
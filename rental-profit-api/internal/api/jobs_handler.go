@@ -0,0 +1,139 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"rental-profit-api/internal/booking"
+	"rental-profit-api/internal/jobs"
+	"rental-profit-api/internal/store"
+	"rental-profit-api/internal/types"
+)
+
+// jobRetryAfter is the Retry-After hint, in seconds, sent when the job
+// queue is full.
+const jobRetryAfter = 30
+
+// JobHandler runs optimizations too large to answer within a request's
+// lifetime in the background, for clients to poll or to be notified of
+// through a job.completed event.
+type JobHandler struct {
+	jobs *jobs.Manager[types.MaximizeResponse]
+}
+
+// NewJobHandler returns a handler submitting to manager. events may be nil
+// when nobody subscribes to job completions.
+func NewJobHandler(manager *jobs.Manager[types.MaximizeResponse], events EventPublisher) *JobHandler {
+	if events != nil {
+		manager.OnDone(func(job jobs.Job[types.MaximizeResponse]) {
+			events.Publish(job.Owner, EventJobCompleted, toJobResponse(job))
+		})
+	}
+	return &JobHandler{jobs: manager}
+}
+
+// SubmitMaximize handles POST /jobs/maximize with the same payload as
+// /maximize. The payload is validated up front; the optimization itself is
+// queued and the response points at the job to poll.
+func (h *JobHandler) SubmitMaximize(w http.ResponseWriter, r *http.Request) {
+	var bookingRequest []types.BookingRequest
+	err := decodeJSONList(r, &bookingRequest)
+	if err != nil {
+		respondDecodeError(w, err)
+		return
+	}
+	defer r.Body.Close()
+	observeInputBookings(r, "/jobs/maximize", len(bookingRequest))
+
+	// Validate the request content and format
+	domainBookings, err := limitsFor(r.Context()).validateAndMapBookings(bookingRequest)
+	if err != nil {
+		respondInputError(w, err)
+		return
+	}
+
+	job, err := h.jobs.Submit(TenantFromContext(r.Context()), func(ctx context.Context, report func(float64)) (types.MaximizeResponse, error) {
+		ctx = withBackgroundCompute(ctx)
+		ctx = booking.WithProgress(ctx, func(done, total int) {
+			report(float64(done) / float64(total))
+		})
+		scheduleResult, err := findMaxProfit(ctx, domainBookings)
+		if err != nil {
+			return types.MaximizeResponse{}, err
+		}
+		return ToMaximizeResponse(scheduleResult), nil
+	})
+	switch {
+	case errors.Is(err, jobs.ErrQueueFull):
+		w.Header().Set("Retry-After", strconv.Itoa(jobRetryAfter))
+		respondError(w, http.StatusServiceUnavailable, "Job queue is full, retry later")
+		return
+	case errors.Is(err, jobs.ErrClosed):
+		respondError(w, http.StatusServiceUnavailable, "Server is shutting down")
+		return
+	case err != nil:
+		respondError(w, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	w.Header().Set("Location", "/jobs/"+job.ID)
+	respondJSON(w, http.StatusAccepted, toJobResponse(job))
+}
+
+// Get handles GET /jobs/{id}.
+func (h *JobHandler) Get(w http.ResponseWriter, r *http.Request) {
+	job, err := h.jobs.Get(TenantFromContext(r.Context()), r.PathValue("id"))
+	if errors.Is(err, store.ErrNotFound) {
+		respondError(w, http.StatusNotFound, "Job not found")
+		return
+	}
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+	respondJSON(w, http.StatusOK, toJobResponse(job))
+}
+
+func toJobResponse(job jobs.Job[types.MaximizeResponse]) types.JobResponse {
+	response := types.JobResponse{
+		JobID:     job.ID,
+		Status:    string(job.Status),
+		Progress:  job.Progress,
+		CreatedAt: jsonTimestamp(job.CreatedAt),
+	}
+	if !job.StartedAt.IsZero() {
+		startedAt := jsonTimestamp(job.StartedAt)
+		response.StartedAt = &startedAt
+	}
+	if !job.FinishedAt.IsZero() {
+		finishedAt := jsonTimestamp(job.FinishedAt)
+		response.FinishedAt = &finishedAt
+	}
+	switch job.Status {
+	case jobs.StatusSucceeded:
+		response.Result = &job.Result
+	case jobs.StatusFailed:
+		response.Error = jobErrorMessage(job.Err)
+	}
+	return response
+}
+
+// jobErrorMessage explains a failed job without leaking internals.
+func jobErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return "Job exceeded its time limit"
+	case errors.Is(err, context.Canceled):
+		return "Job was cancelled because the server shut down"
+	default:
+		return "Internal Server Error"
+	}
+}
+
+// jsonTimestamp keeps timestamps in JSON responses at a readable precision.
+func jsonTimestamp(t time.Time) time.Time {
+	return t.UTC().Truncate(time.Millisecond)
+}
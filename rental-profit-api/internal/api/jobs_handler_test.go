@@ -0,0 +1,132 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"rental-profit-api/internal/jobs"
+	"rental-profit-api/internal/testutil"
+	"rental-profit-api/internal/types"
+)
+
+func newJobMux(t *testing.T, options jobs.Options) *http.ServeMux {
+	t.Helper()
+	manager := jobs.NewManager[types.MaximizeResponse](options)
+	t.Cleanup(manager.Close)
+	jobHandler := NewJobHandler(manager, nil)
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /jobs/maximize", jobHandler.SubmitMaximize)
+	mux.HandleFunc("GET /jobs/{id}", jobHandler.Get)
+	return mux
+}
+
+func TestJobHandler(t *testing.T) {
+	mux := newJobMux(t, jobs.Options{Workers: 1, QueueSize: 1, TTL: time.Hour})
+
+	req := testutil.NewTestRequest(t, http.MethodPost, "/jobs/maximize", []types.BookingRequest{
+		{RequestID: "B1", Checkin: "2024-01-01", Nights: 4, SellingRate: 100, Margin: 10},
+		{RequestID: "B2", Checkin: "2024-01-02", Nights: 4, SellingRate: 100, Margin: 5},
+		{RequestID: "B3", Checkin: "2024-01-06", Nights: 2, SellingRate: 150, Margin: 20},
+	})
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, req)
+	if recorder.Code != http.StatusAccepted {
+		t.Fatalf("submit returned wrong status code: got %v want %v. Body: %s", recorder.Code, http.StatusAccepted, recorder.Body.String())
+	}
+	var submitted types.JobResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &submitted); err != nil {
+		t.Fatalf("submit returned invalid JSON: %v", err)
+	}
+	if location := recorder.Header().Get("Location"); location != "/jobs/"+submitted.JobID {
+		t.Errorf("submit returned Location %q, want /jobs/%s", location, submitted.JobID)
+	}
+
+	// Poll until the job has finished
+	var polled types.JobResponse
+	deadline := time.Now().Add(5 * time.Second)
+	for polled.Status != string(jobs.StatusSucceeded) {
+		if time.Now().After(deadline) {
+			t.Fatalf("job did not succeed in time, last status %q", polled.Status)
+		}
+		recorder = httptest.NewRecorder()
+		mux.ServeHTTP(recorder, testutil.NewTestRequest(t, http.MethodGet, "/jobs/"+submitted.JobID, nil))
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("get returned wrong status code: got %v want %v", recorder.Code, http.StatusOK)
+		}
+		if err := json.Unmarshal(recorder.Body.Bytes(), &polled); err != nil {
+			t.Fatalf("get returned invalid JSON: %v", err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if polled.Progress != 1 || polled.StartedAt == nil || polled.FinishedAt == nil {
+		t.Errorf("finished job = %+v, want full progress and timestamps", polled)
+	}
+	if want := `"result":{"request_ids":["B1","B3"],"total_profit":40`; !strings.Contains(recorder.Body.String(), want) {
+		t.Errorf("get returned unexpected body: got %q want substring %q", recorder.Body.String(), want)
+	}
+}
+
+func TestJobHandlerErrors(t *testing.T) {
+	mux := newJobMux(t, jobs.Options{Workers: 1, TTL: time.Hour})
+
+	// --- Define Test Scenarios ---
+	testCases := []struct {
+		name                 string
+		requestMethod        string
+		requestPath          string
+		requestBody          interface{}
+		expectedStatus       int
+		expectedBodyContains string
+	}{
+		{
+			name:                 "Validation Error Is Reported Synchronously",
+			requestMethod:        http.MethodPost,
+			requestPath:          "/jobs/maximize",
+			requestBody:          []types.BookingRequest{{RequestID: "B1", Checkin: "2024-01-01", Nights: 0, SellingRate: 100, Margin: 10}},
+			expectedStatus:       http.StatusBadRequest,
+			expectedBodyContains: "nights must be positive on item 0",
+		},
+		{
+			name:                 "Unknown Job",
+			requestMethod:        http.MethodGet,
+			requestPath:          "/jobs/does-not-exist",
+			expectedStatus:       http.StatusNotFound,
+			expectedBodyContains: "Job not found",
+		},
+	}
+
+	// --- Execute Scenarios ---
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			req := testutil.NewTestRequest(t, tt.requestMethod, tt.requestPath, tt.requestBody)
+			recorder := httptest.NewRecorder()
+
+			mux.ServeHTTP(recorder, req)
+
+			if status := recorder.Code; status != tt.expectedStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v", status, tt.expectedStatus)
+				t.Logf("Response Body: %s", recorder.Body.String())
+			}
+
+			if !strings.Contains(recorder.Body.String(), tt.expectedBodyContains) {
+				t.Errorf("handler returned unexpected body: got %q want substring %q", recorder.Body.String(), tt.expectedBodyContains)
+			}
+		})
+	}
+}
+
+func TestToJobResponseHidesFailureDetails(t *testing.T) {
+	response := toJobResponse(jobs.Job[types.MaximizeResponse]{
+		ID:     "job-1",
+		Status: jobs.StatusFailed,
+		Err:    &json.SyntaxError{},
+	})
+	if response.Error != "Internal Server Error" || response.Result != nil {
+		t.Errorf("toJobResponse() = %+v, want a generic error and no result", response)
+	}
+}
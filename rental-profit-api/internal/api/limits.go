@@ -0,0 +1,107 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"rental-profit-api/internal/booking"
+)
+
+var (
+	// ErrTooManyBookings rejects payloads with more items than allowed.
+	ErrTooManyBookings = errors.New("too many bookings")
+	// ErrLimitExceeded rejects well-formed values that are out of bounds.
+	ErrLimitExceeded = errors.New("limit exceeded")
+)
+
+// Limits bounds how much work a single request may ask for. A zero field
+// disables that bound. The body size itself is capped by the server before
+// any handler runs.
+type Limits struct {
+	MaxBookings     int     // Items per request
+	MaxNights       int     // Nights per booking
+	MaxDateSpanDays int     // Days between the earliest check-in and the latest checkout
+	MaxSellingRate  float64 // Selling rate per booking
+}
+
+// DefaultLimits applies to every endpoint that accepts bookings. It is set
+// from the server configuration at startup.
+var DefaultLimits Limits
+
+func (l Limits) checkBookingCount(count int) error {
+	if l.MaxBookings > 0 && count > l.MaxBookings {
+		validationErrors.Inc("bookings")
+		return fmt.Errorf("%w: %d bookings exceed the maximum of %d per request", ErrTooManyBookings, count, l.MaxBookings)
+	}
+	return nil
+}
+
+// checkBooking bounds one booking; where names it in error messages.
+func (l Limits) checkBooking(b booking.Booking, where string) error {
+	if l.MaxNights > 0 && b.Nights > l.MaxNights {
+		return limitError("nights", "nights %d exceed the maximum of %d on %s", b.Nights, l.MaxNights, where)
+	}
+	if l.MaxSellingRate > 0 && b.SellingRate > l.MaxSellingRate {
+		return limitError("selling_rate", "selling rate %v exceeds the maximum of %v on %s", b.SellingRate, l.MaxSellingRate, where)
+	}
+	return nil
+}
+
+func (l Limits) checkDateSpan(bookings []booking.Booking) error {
+	if l.MaxDateSpanDays <= 0 || len(bookings) == 0 {
+		return nil
+	}
+	first, last := bookings[0].Checkin, booking.CalculateCheckout(bookings[0].Checkin, bookings[0].Nights)
+	for _, b := range bookings[1:] {
+		if b.Checkin.Before(first) {
+			first = b.Checkin
+		}
+		if checkout := booking.CalculateCheckout(b.Checkin, b.Nights); checkout.After(last) {
+			last = checkout
+		}
+	}
+	if span := int(last.Sub(first) / (24 * time.Hour)); span > l.MaxDateSpanDays {
+		return limitError("date_span", "bookings span %d days from %s to %s, more than the maximum of %d",
+			span, first.Format(booking.DateLayout), last.Format(booking.DateLayout), l.MaxDateSpanDays)
+	}
+	return nil
+}
+
+// limitError builds an ErrLimitExceeded for field and counts it alongside
+// the validation errors.
+func limitError(field string, format string, args ...any) error {
+	validationErrors.Inc(field)
+	return fmt.Errorf("%w: "+format, append([]any{ErrLimitExceeded}, args...)...)
+}
+
+// respondInputError maps a rejected payload to its status code: 413 when it
+// is too large, 422 when a value is out of bounds and 400 when it is invalid.
+func respondInputError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrTooManyBookings):
+		respondError(w, http.StatusRequestEntityTooLarge, err.Error())
+	case errors.Is(err, ErrLimitExceeded):
+		respondError(w, http.StatusUnprocessableEntity, err.Error())
+	case errors.Is(err, ErrValidation):
+		respondError(w, http.StatusBadRequest, err.Error())
+	default:
+		respondError(w, http.StatusInternalServerError, "Internal Server Error")
+	}
+}
+
+// respondDecodeError reports a request body that could not be decoded,
+// telling an oversized body and unknown fields apart from malformed JSON.
+func respondDecodeError(w http.ResponseWriter, err error) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		respondError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("Request body exceeds the maximum of %d bytes", maxBytesErr.Limit))
+		return
+	}
+	if errors.Is(err, ErrValidation) {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	respondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid JSON format: %v", err))
+}
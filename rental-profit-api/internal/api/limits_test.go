@@ -0,0 +1,124 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"rental-profit-api/internal/testutil"
+	"rental-profit-api/internal/types"
+)
+
+func TestInputLimits(t *testing.T) {
+	previous := DefaultLimits
+	DefaultLimits = Limits{MaxBookings: 3, MaxNights: 30, MaxDateSpanDays: 60, MaxSellingRate: 1000}
+	t.Cleanup(func() { DefaultLimits = previous })
+
+	tooMany := make([]types.BookingRequest, 4)
+	for i := range tooMany {
+		tooMany[i] = types.BookingRequest{RequestID: fmt.Sprintf("B%d", i), Checkin: "2024-01-01", Nights: 1, SellingRate: 100, Margin: 10}
+	}
+
+	// --- Define Test Scenarios ---
+	testCases := []struct {
+		name                 string
+		handler              http.HandlerFunc
+		requestBody          interface{}
+		expectedStatus       int
+		expectedBodyContains string
+	}{
+		{
+			name:                 "Too Many Bookings",
+			handler:              MaximizeProfitHandler,
+			requestBody:          tooMany,
+			expectedStatus:       http.StatusRequestEntityTooLarge,
+			expectedBodyContains: "too many bookings: 4 bookings exceed the maximum of 3 per request",
+		},
+		{
+			name:    "Too Many Nights",
+			handler: StatsHandler,
+			requestBody: []types.BookingRequest{
+				{RequestID: "B1", Checkin: "2024-01-01", Nights: 31, SellingRate: 100, Margin: 10},
+			},
+			expectedStatus:       http.StatusUnprocessableEntity,
+			expectedBodyContains: "limit exceeded: nights 31 exceed the maximum of 30 on item 0",
+		},
+		{
+			name:    "Selling Rate Too High",
+			handler: MaximizeProfitHandler,
+			requestBody: []types.BookingRequest{
+				{RequestID: "B1", Checkin: "2024-01-01", Nights: 2, SellingRate: 100, Margin: 10},
+				{RequestID: "B2", Checkin: "2024-01-05", Nights: 2, SellingRate: 1000.5, Margin: 10},
+			},
+			expectedStatus:       http.StatusUnprocessableEntity,
+			expectedBodyContains: "limit exceeded: selling rate 1000.5 exceeds the maximum of 1000 on item 1",
+		},
+		{
+			name:    "Date Span Too Wide",
+			handler: MaximizeProfitHandler,
+			requestBody: []types.BookingRequest{
+				{RequestID: "B1", Checkin: "2024-03-01", Nights: 2, SellingRate: 100, Margin: 10},
+				{RequestID: "B2", Checkin: "2024-01-01", Nights: 2, SellingRate: 100, Margin: 10},
+			},
+			expectedStatus:       http.StatusUnprocessableEntity,
+			expectedBodyContains: "limit exceeded: bookings span 62 days from 2024-01-01 to 2024-03-03, more than the maximum of 60",
+		},
+		{
+			name:    "Stay Too Long",
+			handler: QuoteHandler,
+			requestBody: types.QuoteRequest{
+				Bookings: []types.BookingRequest{},
+				Stay:     types.QuoteStay{Checkin: "2024-01-01", Nights: 45, Margin: 10},
+			},
+			expectedStatus:       http.StatusUnprocessableEntity,
+			expectedBodyContains: "nights 45 exceed the maximum of 30 on stay",
+		},
+		{
+			name:    "Within Limits",
+			handler: MaximizeProfitHandler,
+			requestBody: []types.BookingRequest{
+				{RequestID: "B1", Checkin: "2024-01-01", Nights: 30, SellingRate: 1000, Margin: 10},
+				{RequestID: "B2", Checkin: "2024-02-01", Nights: 29, SellingRate: 100, Margin: 10},
+			},
+			expectedStatus:       http.StatusOK,
+			expectedBodyContains: `"request_ids":["B1","B2"]`,
+		},
+	}
+
+	// --- Execute Scenarios ---
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			req := testutil.NewTestRequest(t, http.MethodPost, "/", tt.requestBody)
+			recorder := httptest.NewRecorder()
+
+			tt.handler(recorder, req)
+
+			if status := recorder.Code; status != tt.expectedStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v", status, tt.expectedStatus)
+				t.Logf("Response Body: %s", recorder.Body.String())
+			}
+
+			if !strings.Contains(recorder.Body.String(), tt.expectedBodyContains) {
+				t.Errorf("handler returned unexpected body: got %q want substring %q", recorder.Body.String(), tt.expectedBodyContains)
+			}
+		})
+	}
+}
+
+func TestBodySizeLimit(t *testing.T) {
+	handler := http.MaxBytesHandler(http.HandlerFunc(MaximizeProfitHandler), 64)
+	body := `[{"request_id":"B1","check_in":"2024-01-01","nights":2,"selling_rate":100,"margin":10}]`
+	req := testutil.NewRawTestRequest(t, http.MethodPost, "/maximize", body)
+	recorder := httptest.NewRecorder()
+
+	handler.ServeHTTP(recorder, req)
+
+	if status := recorder.Code; status != http.StatusRequestEntityTooLarge {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusRequestEntityTooLarge)
+	}
+	if want := "Request body exceeds the maximum of 64 bytes"; !strings.Contains(recorder.Body.String(), want) {
+		t.Errorf("handler returned unexpected body: got %q want substring %q", recorder.Body.String(), want)
+	}
+}
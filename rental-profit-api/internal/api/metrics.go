@@ -0,0 +1,74 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"rental-profit-api/internal/metrics"
+)
+
+// inputSizeBuckets cover request payloads from a handful of bookings up to
+// bulk optimization runs.
+var inputSizeBuckets = []float64{0, 1, 5, 10, 50, 100, 500, 1000, 5000, 10000, 50000}
+
+var (
+	httpRequests = metrics.NewCounterVec("rental_http_requests_total",
+		"HTTP requests served, by route pattern and status code.", "route", "status")
+	httpRequestDuration = metrics.NewHistogramVec("rental_http_request_duration_seconds",
+		"HTTP request latency, by route pattern and status code.", metrics.DefBuckets, "route", "status")
+	grpcRequests = metrics.NewCounterVec("rental_grpc_requests_total",
+		"gRPC calls served, by full method name and status code.", "method", "code")
+	grpcRequestDuration = metrics.NewHistogramVec("rental_grpc_request_duration_seconds",
+		"gRPC call latency, by full method name and status code.", metrics.DefBuckets, "method", "code")
+	inputBookings = metrics.NewHistogramVec("rental_input_bookings",
+		"Number of bookings per request body.", inputSizeBuckets, "route")
+	findMaxProfitDuration = metrics.NewHistogramVec("rental_find_max_profit_duration_seconds",
+		"Time spent in the profit maximization.", metrics.DefBuckets)
+	findMaxProfitAborted = metrics.NewCounterVec("rental_find_max_profit_aborted_total",
+		"Profit maximizations abandoned before finishing, by reason.", "reason")
+	scheduledBookings = metrics.NewCounterVec("rental_scheduled_bookings_total",
+		"Bookings evaluated by /maximize, by whether they made it into the optimal schedule.", "outcome")
+	validationErrors = metrics.NewCounterVec("rental_validation_errors_total",
+		"Rejected request payloads, by offending field.", "field")
+	throttledRequests = metrics.NewCounterVec("rental_throttled_requests_total",
+		"Requests answered with 429, by whether the client's rate limit or the compute queue was exhausted.", "reason")
+	computeQueueWait = metrics.NewHistogramVec("rental_compute_queue_wait_seconds",
+		"Time optimizations waited for a compute slot.", metrics.DefBuckets)
+	resultCacheRequests = metrics.NewCounterVec("rental_result_cache_requests_total",
+		"/maximize and /stats results, by route and whether they were served from the cache (hit), computed (miss) or answered with 304 (not_modified).", "route", "outcome")
+	idempotentRequests = metrics.NewCounterVec("rental_idempotent_requests_total",
+		"Requests carrying an Idempotency-Key, by whether their response was stored, replayed or refused.", "outcome")
+	authFailures = metrics.NewCounterVec("rental_auth_failures_total",
+		"Requests rejected for a missing or unknown API key, by reason.", "reason")
+)
+
+// MetricsHandler handles GET /metrics in the Prometheus text format.
+var MetricsHandler = metrics.Default.Handler().ServeHTTP
+
+// InstrumentHandler counts and times every request served by next. Routes
+// are labelled with the matched ServeMux pattern rather than the raw path,
+// so IDs in paths do not blow up the number of series; see Chain for where
+// it has to sit.
+func InstrumentHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+
+		route := r.Pattern
+		if route == "" {
+			route = "unmatched"
+		}
+		status := strconv.Itoa(recorder.status)
+		httpRequests.Inc(route, status)
+		httpRequestDuration.Observe(time.Since(start).Seconds(), route, status)
+	})
+}
+
+// validationError builds an ErrValidation for field and counts it.
+func validationError(field string, format string, args ...any) error {
+	validationErrors.Inc(field)
+	return fmt.Errorf("%w: "+format, append([]any{ErrValidation}, args...)...)
+}
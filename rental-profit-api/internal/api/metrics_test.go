@@ -0,0 +1,122 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"rental-profit-api/internal/testutil"
+	"rental-profit-api/internal/types"
+)
+
+func TestInstrumentHandler(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /calendars/{id}", func(w http.ResponseWriter, r *http.Request) {
+		respondError(w, http.StatusNotFound, "Calendar not found")
+	})
+	mux.HandleFunc("/maximize", MaximizeProfitHandler)
+	handler := InstrumentHandler(mux)
+
+	// --- Define Test Scenarios ---
+	testCases := []struct {
+		name          string
+		requestMethod string
+		requestPath   string
+		requestBody   interface{}
+		expectedRoute string
+		expectedCode  string
+	}{
+		{
+			name:          "Path Values Collapse Into The Pattern",
+			requestMethod: http.MethodGet,
+			requestPath:   "/calendars/abc123",
+			expectedRoute: "GET /calendars/{id}",
+			expectedCode:  "404",
+		},
+		{
+			name:          "Implicit OK Status",
+			requestMethod: http.MethodPost,
+			requestPath:   "/maximize",
+			requestBody:   []types.BookingRequest{},
+			expectedRoute: "/maximize",
+			expectedCode:  "200",
+		},
+		{
+			name:          "Unmatched Path",
+			requestMethod: http.MethodGet,
+			requestPath:   "/does/not/exist",
+			expectedRoute: "unmatched",
+			expectedCode:  "404",
+		},
+	}
+
+	// --- Execute Scenarios ---
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			requestsBefore := httpRequests.Value(tt.expectedRoute, tt.expectedCode)
+			observationsBefore := httpRequestDuration.Count(tt.expectedRoute, tt.expectedCode)
+
+			req := testutil.NewTestRequest(t, tt.requestMethod, tt.requestPath, tt.requestBody)
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			if got := httpRequests.Value(tt.expectedRoute, tt.expectedCode) - requestsBefore; got != 1 {
+				t.Errorf("request counter grew by %v, want 1", got)
+			}
+			if got := httpRequestDuration.Count(tt.expectedRoute, tt.expectedCode) - observationsBefore; got != 1 {
+				t.Errorf("latency histogram grew by %v, want 1", got)
+			}
+		})
+	}
+}
+
+func TestMaximizeMetrics(t *testing.T) {
+	bookings := []types.BookingRequest{
+		{RequestID: "B1", Checkin: "2024-01-01", Nights: 4, SellingRate: 100, Margin: 10},
+		{RequestID: "B2", Checkin: "2024-01-02", Nights: 4, SellingRate: 100, Margin: 10},
+		{RequestID: "B3", Checkin: "2024-01-06", Nights: 2, SellingRate: 150, Margin: 20},
+	}
+	inputsBefore := inputBookings.Count("/maximize")
+	runsBefore := findMaxProfitDuration.Count()
+	selectedBefore := scheduledBookings.Value("selected")
+	rejectedBefore := scheduledBookings.Value("rejected")
+
+	req := testutil.NewTestRequest(t, http.MethodPost, "/maximize", bookings)
+	MaximizeProfitHandler(httptest.NewRecorder(), req)
+
+	if got := inputBookings.Count("/maximize") - inputsBefore; got != 1 {
+		t.Errorf("input size histogram grew by %v, want 1", got)
+	}
+	if got := findMaxProfitDuration.Count() - runsBefore; got != 1 {
+		t.Errorf("FindMaxProfit duration histogram grew by %v, want 1", got)
+	}
+	if got := scheduledBookings.Value("selected") - selectedBefore; got != 2 {
+		t.Errorf("selected bookings grew by %v, want 2", got)
+	}
+	if got := scheduledBookings.Value("rejected") - rejectedBefore; got != 1 {
+		t.Errorf("rejected bookings grew by %v, want 1", got)
+	}
+}
+
+func TestValidationErrorMetrics(t *testing.T) {
+	before := validationErrors.Value("selling_rate")
+
+	req := testutil.NewTestRequest(t, http.MethodPost, "/stats", []types.BookingRequest{
+		{RequestID: "B1", Checkin: "2024-01-01", Nights: 4, SellingRate: -1, Margin: 10},
+	})
+	recorder := httptest.NewRecorder()
+	StatsHandler(recorder, req)
+
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("handler returned wrong status code: got %v want %v", recorder.Code, http.StatusBadRequest)
+	}
+	if got := validationErrors.Value("selling_rate") - before; got != 1 {
+		t.Errorf("selling_rate validation errors grew by %v, want 1", got)
+	}
+
+	metricsRecorder := httptest.NewRecorder()
+	MetricsHandler(metricsRecorder, testutil.NewTestRequest(t, http.MethodGet, "/metrics", nil))
+	if !strings.Contains(metricsRecorder.Body.String(), `rental_validation_errors_total{field="selling_rate"}`) {
+		t.Errorf("metrics output misses the validation error series:\n%s", metricsRecorder.Body.String())
+	}
+}
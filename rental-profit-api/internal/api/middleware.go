@@ -0,0 +1,165 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"time"
+)
+
+// RequestIDHeader carries the request ID in both directions: a caller may
+// set it to correlate our logs with theirs, and every response echoes it.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds caller supplied request IDs, which end up in logs.
+const maxRequestIDLength = 128
+
+// Middleware decorates an http.Handler.
+type Middleware func(http.Handler) http.Handler
+
+// Chain wraps h so that a request passes through middlewares in the given
+// order before reaching h. Middlewares that label by route read the pattern
+// the ServeMux stores on the request, so they must come after any middleware
+// that replaces the request, such as RequestID.
+func Chain(h http.Handler, middlewares ...Middleware) http.Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		h = middlewares[i](h)
+	}
+	return h
+}
+
+type requestIDKey struct{}
+
+// RequestIDFromContext returns the ID assigned by RequestID, or "" outside
+// of a request.
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// RequestID assigns every request an ID, reusing the caller's X-Request-ID
+// when it is well formed, stores it in the request context and sets it on
+// the response before any handler writes to it.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = newRequestID()
+		}
+		w.Header().Set(RequestIDHeader, requestID)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey{}, requestID)))
+	})
+}
+
+// validRequestID accepts short IDs of printable ASCII without spaces, so a
+// caller cannot forge log lines through the header.
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(requestID); i++ {
+		if requestID[i] <= ' ' || requestID[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		// Only reachable if the OS entropy source fails; an ID is not worth
+		// failing the request over
+		return "unknown"
+	}
+	return hex.EncodeToString(buf)
+}
+
+// AccessLog logs one structured line per request once it has been served.
+func AccessLog(logger *slog.Logger) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(recorder, r)
+
+			level := slog.LevelInfo
+			if recorder.status >= http.StatusInternalServerError {
+				level = slog.LevelError
+			}
+			logger.LogAttrs(r.Context(), level, "HTTP request",
+				slog.String("request_id", RequestIDFromContext(r.Context())),
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.String("route", r.Pattern),
+				slog.Int("status", recorder.status),
+				slog.Int64("bytes", recorder.bytes),
+				slog.Duration("duration", time.Since(start)),
+				slog.String("remote_addr", r.RemoteAddr),
+			)
+		})
+	}
+}
+
+// Recover turns a panicking handler into a 500 response and logs the panic
+// with its stack trace, so a bug in one request does not take the server
+// down or go unnoticed.
+func Recover(logger *slog.Logger) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			defer func() {
+				panicValue := recover()
+				if panicValue == nil {
+					return
+				}
+				// http.Server handles this sentinel itself by aborting the response
+				if panicValue == http.ErrAbortHandler {
+					panic(panicValue)
+				}
+				logger.ErrorContext(r.Context(), "Panic while serving request",
+					"request_id", RequestIDFromContext(r.Context()),
+					"method", r.Method,
+					"path", r.URL.Path,
+					"panic", panicValue,
+					"stack", string(debug.Stack()),
+				)
+				if !recorder.wroteHeader {
+					respondError(recorder, http.StatusInternalServerError, "Internal Server Error")
+				}
+			}()
+			next.ServeHTTP(recorder, r)
+		})
+	}
+}
+
+// statusRecorder remembers the status code and body size written through it.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+func (s *statusRecorder) WriteHeader(code int) {
+	if !s.wroteHeader {
+		s.status = code
+		s.wroteHeader = true
+	}
+	s.ResponseWriter.WriteHeader(code)
+}
+
+func (s *statusRecorder) Write(body []byte) (int, error) {
+	s.wroteHeader = true
+	n, err := s.ResponseWriter.Write(body)
+	s.bytes += int64(n)
+	return n, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (s *statusRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
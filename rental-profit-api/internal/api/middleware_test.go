@@ -0,0 +1,169 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"rental-profit-api/internal/testutil"
+)
+
+func TestRequestID(t *testing.T) {
+	// --- Define Test Scenarios ---
+	testCases := []struct {
+		name            string
+		incomingID      string
+		expectGenerated bool
+	}{
+		{name: "Caller ID Is Honored", incomingID: "trace-42:abc"},
+		{name: "Missing ID Is Generated", expectGenerated: true},
+		{name: "ID With Spaces Is Replaced", incomingID: "forged log line", expectGenerated: true},
+		{name: "Overlong ID Is Replaced", incomingID: strings.Repeat("x", maxRequestIDLength+1), expectGenerated: true},
+	}
+
+	// --- Execute Scenarios ---
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			var seenID string
+			handler := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				seenID = RequestIDFromContext(r.Context())
+				respondError(w, http.StatusBadRequest, "validation error: margin must be positive on item 0")
+			}))
+
+			req := testutil.NewTestRequest(t, http.MethodPost, "/maximize", nil)
+			if tt.incomingID != "" {
+				req.Header.Set(RequestIDHeader, tt.incomingID)
+			}
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			responseID := recorder.Header().Get(RequestIDHeader)
+			if tt.expectGenerated {
+				if responseID == tt.incomingID || len(responseID) != 32 {
+					t.Errorf("expected a generated request ID, got %q", responseID)
+				}
+			} else if responseID != tt.incomingID {
+				t.Errorf("response request ID = %q, want %q", responseID, tt.incomingID)
+			}
+			if seenID != responseID {
+				t.Errorf("context request ID = %q, want %q", seenID, responseID)
+			}
+
+			var body struct {
+				RequestID string `json:"request_id"`
+			}
+			if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+				t.Fatalf("error body is not JSON: %v", err)
+			}
+			if body.RequestID != responseID {
+				t.Errorf("error body request ID = %q, want %q", body.RequestID, responseID)
+			}
+		})
+	}
+}
+
+func TestAccessLog(t *testing.T) {
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&logs, nil))
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /calendars/{id}", func(w http.ResponseWriter, r *http.Request) {
+		respondError(w, http.StatusNotFound, "Calendar not found")
+	})
+	handler := Chain(mux, RequestID, AccessLog(logger))
+
+	req := testutil.NewTestRequest(t, http.MethodGet, "/calendars/abc", nil)
+	req.Header.Set(RequestIDHeader, "req-1")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	var entry map[string]any
+	if err := json.Unmarshal(logs.Bytes(), &entry); err != nil {
+		t.Fatalf("access log is not a single JSON line: %v\n%s", err, logs.String())
+	}
+	expected := map[string]any{
+		"msg":        "HTTP request",
+		"level":      "INFO",
+		"request_id": "req-1",
+		"method":     "GET",
+		"path":       "/calendars/abc",
+		"route":      "GET /calendars/{id}",
+		"status":     float64(http.StatusNotFound),
+	}
+	for key, want := range expected {
+		if entry[key] != want {
+			t.Errorf("access log %s = %v, want %v", key, entry[key], want)
+		}
+	}
+	if bytesWritten, _ := entry["bytes"].(float64); bytesWritten <= 0 {
+		t.Errorf("access log bytes = %v, want the body size", entry["bytes"])
+	}
+}
+
+func TestRecover(t *testing.T) {
+	// --- Define Test Scenarios ---
+	testCases := []struct {
+		name                 string
+		handler              http.HandlerFunc
+		expectedStatus       int
+		expectedBodyContains string
+	}{
+		{
+			name: "Panic Before Writing",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				var bookings []int
+				_ = bookings[3]
+			},
+			expectedStatus:       http.StatusInternalServerError,
+			expectedBodyContains: `{"message":"Internal Server Error","request_id":"req-1"}`,
+		},
+		{
+			name: "Panic After Writing Keeps The Response",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusAccepted)
+				panic("late failure")
+			},
+			expectedStatus: http.StatusAccepted,
+		},
+	}
+
+	// --- Execute Scenarios ---
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			logger := slog.New(slog.NewJSONHandler(&logs, nil))
+			handler := Chain(tt.handler, RequestID, Recover(logger))
+
+			req := testutil.NewTestRequest(t, http.MethodPost, "/maximize", nil)
+			req.Header.Set(RequestIDHeader, "req-1")
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			if status := recorder.Code; status != tt.expectedStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v", status, tt.expectedStatus)
+			}
+			if !strings.Contains(recorder.Body.String(), tt.expectedBodyContains) {
+				t.Errorf("handler returned unexpected body: got %q want substring %q", recorder.Body.String(), tt.expectedBodyContains)
+			}
+			for _, want := range []string{`"msg":"Panic while serving request"`, `"request_id":"req-1"`, `"stack":"goroutine `} {
+				if !strings.Contains(logs.String(), want) {
+					t.Errorf("panic log misses %s:\n%s", want, logs.String())
+				}
+			}
+		})
+	}
+}
+
+func TestRecoverRepanicsAbortHandler(t *testing.T) {
+	handler := Recover(slog.Default())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+	defer func() {
+		if recovered := recover(); recovered != http.ErrAbortHandler {
+			t.Errorf("expected http.ErrAbortHandler to propagate, got %v", recovered)
+		}
+	}()
+	handler.ServeHTTP(httptest.NewRecorder(), testutil.NewTestRequest(t, http.MethodGet, "/", nil))
+}
@@ -0,0 +1,22 @@
+package api
+
+import (
+	_ "embed"
+	"log/slog"
+	"net/http"
+)
+
+// openAPISpec is the OpenAPI 3 document for /maximize and /stats, in all
+// versions. Keep it in sync with internal/types; TestOpenAPISpec checks
+// handler responses against it.
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+// OpenAPIHandler handles GET /openapi.json.
+func OpenAPIHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(openAPISpec); err != nil {
+		slog.Warn("Error writing response", "error", err, "request_id", w.Header().Get(RequestIDHeader))
+	}
+}
@@ -0,0 +1,300 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"rental-profit-api/internal/testutil"
+	"rental-profit-api/internal/types"
+)
+
+func TestOpenAPIHandler(t *testing.T) {
+	req := testutil.NewTestRequest(t, http.MethodGet, "/openapi.json", nil)
+	recorder := httptest.NewRecorder()
+	OpenAPIHandler(recorder, req)
+
+	if status := recorder.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+	if got := recorder.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q, want %q", got, "application/json")
+	}
+	var spec struct {
+		OpenAPI string                    `json:"openapi"`
+		Paths   map[string]map[string]any `json:"paths"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &spec); err != nil {
+		t.Fatalf("handler returned invalid JSON: %v", err)
+	}
+	if !strings.HasPrefix(spec.OpenAPI, "3.") {
+		t.Errorf("openapi = %q, want a 3.x version", spec.OpenAPI)
+	}
+	for _, path := range []string{"/maximize", "/stats", "/v2/maximize", "/v2/stats"} {
+		if _, ok := spec.Paths[path]["post"]; !ok {
+			t.Errorf("spec does not describe POST %s", path)
+		}
+	}
+}
+
+// TestOpenAPISpec sends requests to the handlers and checks that both the
+// requests and the responses match what the spec documents for them.
+func TestOpenAPISpec(t *testing.T) {
+	previous := DefaultLimits
+	DefaultLimits = Limits{MaxBookings: 3, MaxNights: 30}
+	t.Cleanup(func() { DefaultLimits = previous })
+
+	spec := loadOpenAPISpec(t)
+	bookings := []types.BookingRequest{
+		{RequestID: "B1", Checkin: "2024-01-01", Nights: 5, SellingRate: 1000, Margin: 10},
+		{RequestID: "B2", Checkin: "2024-01-04", Nights: 4, SellingRate: 1200, Margin: 15, CancelProbability: 0.4},
+		{RequestID: "B3", Checkin: "2024-01-10", Nights: 2, SellingRate: 300, Margin: 20},
+	}
+
+	// --- Define Test Scenarios ---
+	testCases := []struct {
+		name           string
+		handler        http.HandlerFunc
+		path           string
+		query          string
+		requestBody    any
+		rawBody        string
+		expectedStatus int
+	}{
+		{name: "Maximize", handler: MaximizeProfitHandler, path: "/maximize", requestBody: bookings, expectedStatus: http.StatusOK},
+		{name: "Maximize Empty", handler: MaximizeProfitHandler, path: "/maximize", requestBody: []types.BookingRequest{}, expectedStatus: http.StatusOK},
+		{name: "Maximize Stochastic", handler: MaximizeProfitHandler, path: "/maximize", query: "?mode=stochastic&max_walk_probability=0.5", requestBody: bookings, expectedStatus: http.StatusOK},
+		{name: "Maximize Invalid JSON", handler: MaximizeProfitHandler, path: "/maximize", rawBody: `[{"request_id":`, expectedStatus: http.StatusBadRequest},
+		{name: "Maximize Invalid Mode", handler: MaximizeProfitHandler, path: "/maximize", query: "?mode=optimistic", requestBody: bookings, expectedStatus: http.StatusBadRequest},
+		{name: "Maximize Too Many Bookings", handler: MaximizeProfitHandler, path: "/maximize", requestBody: append(bookings, bookings[0]), expectedStatus: http.StatusRequestEntityTooLarge},
+		{name: "Stats", handler: StatsHandler, path: "/stats", requestBody: bookings, expectedStatus: http.StatusOK},
+		{name: "Stats Empty", handler: StatsHandler, path: "/stats", requestBody: []types.BookingRequest{}, expectedStatus: http.StatusOK},
+		{name: "Stats Missing Request ID", handler: StatsHandler, path: "/stats", requestBody: []types.BookingRequest{{Checkin: "2024-01-01", Nights: 1, SellingRate: 100, Margin: 10}}, expectedStatus: http.StatusBadRequest},
+		{name: "Stats Too Many Nights", handler: StatsHandler, path: "/stats", requestBody: []types.BookingRequest{{RequestID: "B1", Checkin: "2024-01-01", Nights: 31, SellingRate: 100, Margin: 10}}, expectedStatus: http.StatusUnprocessableEntity},
+		{name: "V1 Maximize", handler: MaximizeProfitHandler, path: "/v1/maximize", requestBody: bookings, expectedStatus: http.StatusOK},
+		{name: "V2 Maximize", handler: Envelope(http.HandlerFunc(MaximizeProfitHandler)).ServeHTTP, path: "/v2/maximize", requestBody: bookings, expectedStatus: http.StatusOK},
+		{name: "V2 Maximize Stochastic", handler: Envelope(http.HandlerFunc(MaximizeProfitHandler)).ServeHTTP, path: "/v2/maximize", query: "?mode=stochastic", requestBody: bookings, expectedStatus: http.StatusOK},
+		{name: "V2 Maximize Invalid JSON", handler: Envelope(http.HandlerFunc(MaximizeProfitHandler)).ServeHTTP, path: "/v2/maximize", rawBody: `[{"request_id":`, expectedStatus: http.StatusBadRequest},
+		{name: "V2 Stats", handler: Envelope(http.HandlerFunc(StatsHandler)).ServeHTTP, path: "/v2/stats", requestBody: bookings, expectedStatus: http.StatusOK},
+		{name: "V2 Stats Too Many Nights", handler: Envelope(http.HandlerFunc(StatsHandler)).ServeHTTP, path: "/v2/stats", requestBody: []types.BookingRequest{{RequestID: "B1", Checkin: "2024-01-01", Nights: 31, SellingRate: 100, Margin: 10}}, expectedStatus: http.StatusUnprocessableEntity},
+	}
+
+	// --- Execute Scenarios ---
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			operation := spec.operation(t, tc.path, http.MethodPost)
+
+			var req *http.Request
+			if tc.rawBody != "" {
+				req = testutil.NewRawTestRequest(t, http.MethodPost, tc.path+tc.query, tc.rawBody)
+			} else {
+				req = testutil.NewTestRequest(t, http.MethodPost, tc.path+tc.query, tc.requestBody)
+				if tc.expectedStatus == http.StatusOK {
+					requestSchema := spec.resolve(t, operation["requestBody"])
+					spec.checkJSON(t, "request", jsonSchemaOf(t, requestSchema), tc.requestBody)
+				}
+			}
+			recorder := httptest.NewRecorder()
+			tc.handler(recorder, req)
+
+			if recorder.Code != tc.expectedStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v. Body: %s", recorder.Code, tc.expectedStatus, recorder.Body.String())
+			}
+			responses, _ := operation["responses"].(map[string]any)
+			documented, ok := responses[strconv.Itoa(recorder.Code)]
+			if !ok {
+				documented, ok = responses["default"]
+			}
+			if !ok {
+				t.Fatalf("status %d is not documented for POST %s", recorder.Code, tc.path)
+			}
+			spec.checkJSON(t, "response", jsonSchemaOf(t, spec.resolve(t, documented)), json.RawMessage(recorder.Body.Bytes()))
+		})
+	}
+}
+
+// openAPISpecDoc is the decoded spec, with just enough of JSON Schema
+// implemented to check the payloads the API exchanges.
+type openAPISpecDoc map[string]any
+
+func loadOpenAPISpec(t *testing.T) openAPISpecDoc {
+	t.Helper()
+	var spec openAPISpecDoc
+	if err := json.Unmarshal(openAPISpec, &spec); err != nil {
+		t.Fatalf("openapi.json is not valid JSON: %v", err)
+	}
+	return spec
+}
+
+func (s openAPISpecDoc) operation(t *testing.T, path, method string) map[string]any {
+	t.Helper()
+	paths, _ := s["paths"].(map[string]any)
+	if _, ok := paths[path]; !ok {
+		t.Fatalf("spec does not describe %s", path)
+	}
+	item := s.resolve(t, paths[path])
+	operation, ok := item[strings.ToLower(method)].(map[string]any)
+	if !ok {
+		t.Fatalf("spec does not describe %s %s", method, path)
+	}
+	return operation
+}
+
+// resolve follows a local "$ref" such as "#/components/schemas/BookingRequest"
+// or "#/paths/~1maximize".
+func (s openAPISpecDoc) resolve(t *testing.T, node any) map[string]any {
+	t.Helper()
+	object, ok := node.(map[string]any)
+	if !ok {
+		t.Fatalf("spec node %v is not an object", node)
+	}
+	ref, ok := object["$ref"].(string)
+	if !ok {
+		return object
+	}
+	var target any = map[string]any(s)
+	unescape := strings.NewReplacer("~1", "/", "~0", "~")
+	for _, part := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		parent, _ := target.(map[string]any)
+		if target, ok = parent[unescape.Replace(part)]; !ok {
+			t.Fatalf("spec reference %q does not resolve", ref)
+		}
+	}
+	return s.resolve(t, target)
+}
+
+// jsonSchemaOf returns the application/json schema of a request body or
+// response object.
+func jsonSchemaOf(t *testing.T, object map[string]any) map[string]any {
+	t.Helper()
+	content, _ := object["content"].(map[string]any)
+	media, _ := content["application/json"].(map[string]any)
+	schema, ok := media["schema"].(map[string]any)
+	if !ok {
+		t.Fatalf("spec object %v has no application/json schema", object)
+	}
+	return schema
+}
+
+// checkJSON marshals payload, unless it already is JSON, and reports where it
+// does not match schema.
+func (s openAPISpecDoc) checkJSON(t *testing.T, what string, schema map[string]any, payload any) {
+	t.Helper()
+	data, ok := payload.(json.RawMessage)
+	if !ok {
+		var err error
+		if data, err = json.Marshal(payload); err != nil {
+			t.Fatalf("could not marshal %s: %v", what, err)
+		}
+	}
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		t.Fatalf("%s is not valid JSON: %v", what, err)
+	}
+	if err := s.validate(t, schema, value, "$"); err != nil {
+		t.Errorf("%s does not match the spec: %v. Body: %s", what, err, data)
+	}
+}
+
+func (s openAPISpecDoc) validate(t *testing.T, schema map[string]any, value any, at string) error {
+	schema = s.resolve(t, schema)
+	if value == nil && schema["nullable"] == true {
+		return nil
+	}
+
+	if options, ok := schema["oneOf"].([]any); ok {
+		matches := 0
+		for _, option := range options {
+			if s.validate(t, option.(map[string]any), value, at) == nil {
+				matches++
+			}
+		}
+		if matches != 1 {
+			return fmt.Errorf("%s matches %d of the oneOf schemas, want exactly 1", at, matches)
+		}
+		return nil
+	}
+
+	if enum, ok := schema["enum"].([]any); ok && !slices.Contains(enum, value) {
+		return fmt.Errorf("%s = %v is not one of %v", at, value, enum)
+	}
+
+	switch schema["type"] {
+	case "object":
+		object, ok := value.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s is %T, want an object", at, value)
+		}
+		properties, _ := schema["properties"].(map[string]any)
+		required, _ := schema["required"].([]any)
+		for _, name := range required {
+			if _, ok := object[name.(string)]; !ok {
+				return fmt.Errorf("%s is missing required property %q", at, name)
+			}
+		}
+		for name, field := range object {
+			property, ok := properties[name].(map[string]any)
+			if !ok {
+				if schema["additionalProperties"] == false {
+					return fmt.Errorf("%s has undocumented property %q", at, name)
+				}
+				continue
+			}
+			if err := s.validate(t, property, field, at+"."+name); err != nil {
+				return err
+			}
+		}
+	case "array":
+		array, ok := value.([]any)
+		if !ok {
+			return fmt.Errorf("%s is %T, want an array", at, value)
+		}
+		if minItems, ok := schema["minItems"].(float64); ok && float64(len(array)) < minItems {
+			return fmt.Errorf("%s has fewer than %v items", at, minItems)
+		}
+		if maxItems, ok := schema["maxItems"].(float64); ok && float64(len(array)) > maxItems {
+			return fmt.Errorf("%s has more than %v items", at, maxItems)
+		}
+		items, _ := schema["items"].(map[string]any)
+		for i, item := range array {
+			if err := s.validate(t, items, item, fmt.Sprintf("%s[%d]", at, i)); err != nil {
+				return err
+			}
+		}
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("%s is %T, want a string", at, value)
+		}
+		if minLength, ok := schema["minLength"].(float64); ok && float64(len(str)) < minLength {
+			return fmt.Errorf("%s is shorter than %v characters", at, minLength)
+		}
+	case "number", "integer":
+		number, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("%s is %T, want a number", at, value)
+		}
+		if schema["type"] == "integer" && number != math.Trunc(number) {
+			return fmt.Errorf("%s = %v, want an integer", at, number)
+		}
+		if minimum, ok := schema["minimum"].(float64); ok {
+			if number < minimum || (schema["exclusiveMinimum"] == true && number == minimum) {
+				return fmt.Errorf("%s = %v is below the minimum of %v", at, number, minimum)
+			}
+		}
+		if maximum, ok := schema["maximum"].(float64); ok && number > maximum {
+			return fmt.Errorf("%s = %v is above the maximum of %v", at, number, maximum)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%s is %T, want a boolean", at, value)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"rental-profit-api/internal/booking"
+	"rental-profit-api/internal/types"
+)
+
+const (
+	deterministicMode = "deterministic"
+	stochasticMode    = "stochastic"
+)
+
+// DefaultOverbookingPolicy applies to stochastic /maximize requests that do
+// not set max_walk_probability or walk_penalty. By default no overlap is
+// allowed, so stochastic mode only weighs bookings by their show-up chance.
+var DefaultOverbookingPolicy = booking.OverbookingPolicy{
+	MaxWalkProbability: 0,
+	WalkPenalty:        0,
+}
+
+// parseOverbookingPolicy reads the /maximize mode and overbooking query
+// parameters, which override those of policy. It reports whether stochastic
+// mode was requested.
+func parseOverbookingPolicy(query url.Values, policy booking.OverbookingPolicy) (booking.OverbookingPolicy, bool, error) {
+
+	switch mode := query.Get("mode"); mode {
+	case "", deterministicMode:
+		return policy, false, nil
+	case stochasticMode:
+	default:
+		return policy, false, validationError("mode", "mode must be %q or %q, got %q", deterministicMode, stochasticMode, mode)
+	}
+
+	if value := query.Get("max_walk_probability"); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil || parsed < 0 || parsed > 1 {
+			return policy, true, validationError("max_walk_probability", "max_walk_probability must be between 0 and 1")
+		}
+		policy.MaxWalkProbability = parsed
+	}
+	if value := query.Get("walk_penalty"); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil || parsed < 0 {
+			return policy, true, validationError("walk_penalty", "walk_penalty must be a non-negative number")
+		}
+		policy.WalkPenalty = parsed
+	}
+	return policy, true, nil
+}
+
+func respondExpectedProfit(w http.ResponseWriter, r *http.Request, domainBookings []booking.Booking, policy booking.OverbookingPolicy) {
+	// Execute business logic
+	ctx, cancel := withComputeBudget(r.Context())
+	defer cancel()
+	expectedResult, err := runComputation(ctx, func(ctx context.Context) (booking.ExpectedScheduleResult, error) {
+		return booking.FindMaxExpectedProfitContext(ctx, domainBookings, policy)
+	})
+	if err != nil {
+		respondComputeError(w, err)
+		return
+	}
+
+	overbooked := make([]types.OverbookedPair, len(expectedResult.Overbooked))
+	for i, overlap := range expectedResult.Overbooked {
+		overbooked[i] = types.OverbookedPair{
+			First:           overlap.First.RequestID,
+			Second:          overlap.Second.RequestID,
+			WalkProbability: booking.WalkProbability(overlap.First, overlap.Second),
+		}
+	}
+
+	respondJSON(w, http.StatusOK, types.ExpectedProfitResponse{
+		RequestIDs:     requestIDsOf(expectedResult.Schedule),
+		ExpectedProfit: roundToCents(expectedResult.ExpectedProfit),
+		ExpectedWalks:  expectedResult.ExpectedWalks,
+		Overbooked:     overbooked,
+	})
+}
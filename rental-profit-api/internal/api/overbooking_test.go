@@ -0,0 +1,105 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"rental-profit-api/internal/testutil"
+	"rental-profit-api/internal/types"
+)
+
+func TestMaximizeProfitHandlerStochastic(t *testing.T) {
+	bookings := []types.BookingRequest{
+		{RequestID: "A", Checkin: "2024-01-01", Nights: 4, SellingRate: 1000, Margin: 10, CancelProbability: 0.5},
+		{RequestID: "B", Checkin: "2024-01-03", Nights: 4, SellingRate: 1000, Margin: 10, CancelProbability: 0.5},
+	}
+
+	// --- Define Test Scenarios ---
+	testCases := []struct {
+		name                 string
+		query                string
+		requestBody          interface{}
+		expectedStatus       int
+		expectedBodyContains string
+	}{
+		{
+			name:                 "Unknown Mode",
+			query:                "?mode=optimistic",
+			requestBody:          bookings,
+			expectedStatus:       http.StatusBadRequest,
+			expectedBodyContains: "mode must be",
+		},
+		{
+			name:                 "Risk Limit Out Of Range",
+			query:                "?mode=stochastic&max_walk_probability=2",
+			requestBody:          bookings,
+			expectedStatus:       http.StatusBadRequest,
+			expectedBodyContains: "max_walk_probability must be between 0 and 1",
+		},
+		{
+			name:                 "Negative Penalty",
+			query:                "?mode=stochastic&walk_penalty=-1",
+			requestBody:          bookings,
+			expectedStatus:       http.StatusBadRequest,
+			expectedBodyContains: "walk_penalty must be a non-negative number",
+		},
+		{
+			name:                 "Cancel Probability Out Of Range",
+			query:                "?mode=stochastic",
+			requestBody:          []types.BookingRequest{{RequestID: "A", Checkin: "2024-01-01", Nights: 4, SellingRate: 1000, Margin: 10, CancelProbability: 1.5}},
+			expectedStatus:       http.StatusBadRequest,
+			expectedBodyContains: "cancel_probability must be between 0 and 1 on item 0",
+		},
+		{
+			name:                 "Empty Input List",
+			query:                "?mode=stochastic",
+			requestBody:          []types.BookingRequest{},
+			expectedStatus:       http.StatusOK,
+			expectedBodyContains: `{"request_ids":[],"expected_profit":0,"expected_walks":0,"overbooked":[]}`,
+		},
+		{
+			name:                 "No Overbooking By Default",
+			query:                "?mode=stochastic",
+			requestBody:          bookings,
+			expectedStatus:       http.StatusOK,
+			expectedBodyContains: `{"request_ids":["A"],"expected_profit":50,"expected_walks":0,"overbooked":[]}`,
+		},
+		{
+			name:                 "Overbooking Within Risk Limit",
+			query:                "?mode=stochastic&max_walk_probability=0.3&walk_penalty=20",
+			requestBody:          bookings,
+			expectedStatus:       http.StatusOK,
+			expectedBodyContains: `{"request_ids":["A","B"],"expected_profit":70,"expected_walks":0.25,"overbooked":[{"first":"A","second":"B","walk_probability":0.25}]}`,
+		},
+		{
+			name:                 "Deterministic Mode Ignores Cancellations",
+			query:                "?mode=deterministic",
+			requestBody:          bookings,
+			expectedStatus:       http.StatusOK,
+			expectedBodyContains: `"request_ids":["A"],"total_profit":100`,
+		},
+	}
+
+	// --- Execute Scenarios ---
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			req := testutil.NewTestRequest(t, http.MethodPost, "/maximize"+tt.query, tt.requestBody)
+			recorder := httptest.NewRecorder()
+
+			MaximizeProfitHandler(recorder, req)
+
+			if status := recorder.Code; status != tt.expectedStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v", status, tt.expectedStatus)
+				t.Logf("Response Body: %s", recorder.Body.String())
+			}
+
+			if tt.expectedBodyContains != "" {
+				if !strings.Contains(recorder.Body.String(), tt.expectedBodyContains) {
+					t.Errorf("handler returned unexpected body: got %q want substring %q", recorder.Body.String(), tt.expectedBodyContains)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"rental-profit-api/internal/booking"
+	"rental-profit-api/internal/types"
+)
+
+// defaultQuoteRequestID names the prospective stay when the client does not.
+// A suffix is added when a booking already uses it, see quoteRequestID.
+const defaultQuoteRequestID = "quote"
+
+func QuoteHandler(w http.ResponseWriter, r *http.Request) {
+	var quoteRequest types.QuoteRequest
+	err := decodeJSON(r, &quoteRequest)
+	if err != nil {
+		respondDecodeError(w, err)
+		return
+	}
+	defer r.Body.Close()
+
+	// Validate the request content and format
+	limits := limitsFor(r.Context())
+	domainBookings, err := limits.validateAndMapBookings(quoteRequest.Bookings)
+	var stay booking.Booking
+	if err == nil {
+		stay, err = validateAndMapStay(quoteRequest.Stay, limits, domainBookings)
+	}
+	if err != nil {
+		respondInputError(w, err)
+		return
+	}
+
+	// Execute business logic
+	ctx, cancel := withComputeBudget(r.Context())
+	defer cancel()
+	quote, err := runComputation(ctx, func(ctx context.Context) (booking.Quote, error) {
+		return booking.QuoteStayContext(ctx, domainBookings, stay, limits.MaxSellingRate)
+	})
+	if err != nil {
+		if errors.Is(err, booking.ErrDuplicateRequestID) {
+			respondError(w, http.StatusBadRequest, fmt.Sprintf("%v: %v", ErrValidation, err))
+		} else if errors.Is(err, booking.ErrNoAcceptableRate) {
+			respondError(w, http.StatusUnprocessableEntity, err.Error())
+		} else {
+			respondComputeError(w, err)
+		}
+		return
+	}
+
+	response := types.QuoteResponse{
+		MinSellingRate: quote.MinSellingRate,
+		Displaced:      requestIDsOf(quote.Displaced),
+		Schedule:       ToMaximizeResponse(quote.Schedule),
+	}
+
+	respondJSON(w, http.StatusOK, response)
+}
+
+func validateAndMapStay(stay types.QuoteStay, limits Limits, domainBookings []booking.Booking) (booking.Booking, error) {
+	requestID := stay.RequestID
+	if requestID == "" {
+		requestID = quoteRequestID(domainBookings)
+	}
+	checkinDate, err := time.Parse(booking.DateLayout, stay.Checkin)
+	if err != nil {
+		return booking.Booking{}, validationError("check_in", "check_in format error on stay: %w", err)
+	}
+	if stay.Nights <= 0 {
+		return booking.Booking{}, validationError("nights", "nights must be positive on stay")
+	}
+	if stay.Margin <= 0 {
+		return booking.Booking{}, validationError("margin", "margin must be positive on stay")
+	}
+	domainStay := booking.Booking{
+		RequestID: requestID,
+		Checkin:   checkinDate,
+		Nights:    stay.Nights,
+		Margin:    stay.Margin,
+	}
+	if err := limits.checkBooking(domainStay, "stay"); err != nil {
+		return booking.Booking{}, err
+	}
+	return domainStay, nil
+}
+
+// quoteRequestID returns defaultQuoteRequestID, or "quote-2", "quote-3" and
+// so on when the bookings already use it, so a stay without a request_id
+// never clashes with them.
+func quoteRequestID(domainBookings []booking.Booking) string {
+	used := make(map[string]bool, len(domainBookings))
+	for _, b := range domainBookings {
+		used[b.RequestID] = true
+	}
+	requestID := defaultQuoteRequestID
+	for n := 2; used[requestID]; n++ {
+		requestID = fmt.Sprintf("%s-%d", defaultQuoteRequestID, n)
+	}
+	return requestID
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"rental-profit-api/internal/testutil"
+	"rental-profit-api/internal/types"
+)
+
+func TestQuoteHandler(t *testing.T) {
+	bookings := []types.BookingRequest{
+		{RequestID: "B1", Checkin: "2024-01-01", Nights: 4, SellingRate: 100, Margin: 20},
+		{RequestID: "B3", Checkin: "2024-01-06", Nights: 2, SellingRate: 100, Margin: 25},
+	}
+	previous := DefaultLimits
+	DefaultLimits = Limits{MaxSellingRate: 1000}
+	t.Cleanup(func() { DefaultLimits = previous })
+
+	// --- Define Test Scenarios ---
+	testCases := []struct {
+		name                 string
+		requestMethod        string
+		requestBody          interface{}
+		expectedStatus       int
+		expectedBodyContains string
+	}{
+		{
+			name:                 "Validation Error (Stay Without Margin)",
+			requestMethod:        http.MethodPost,
+			requestBody:          types.QuoteRequest{Bookings: bookings, Stay: types.QuoteStay{Checkin: "2024-01-03", Nights: 5}},
+			expectedStatus:       http.StatusBadRequest,
+			expectedBodyContains: "margin must be positive on stay",
+		},
+		{
+			name:                 "Validation Error (Clashing Request ID)",
+			requestMethod:        http.MethodPost,
+			requestBody:          types.QuoteRequest{Bookings: bookings, Stay: types.QuoteStay{RequestID: "B1", Checkin: "2024-01-03", Nights: 5, Margin: 50}},
+			expectedStatus:       http.StatusBadRequest,
+			expectedBodyContains: "duplicate request_id: B1",
+		},
+		{
+			name:                 "Quote Displacing Bookings",
+			requestMethod:        http.MethodPost,
+			requestBody:          types.QuoteRequest{Bookings: bookings, Stay: types.QuoteStay{Checkin: "2024-01-03", Nights: 5, Margin: 50}},
+			expectedStatus:       http.StatusOK,
+			expectedBodyContains: `{"min_selling_rate":90,"displaced":["B1","B3"],"schedule":{"request_ids":["quote"]`,
+		},
+		{
+			name:          "Quote Next To A Booking Named quote",
+			requestMethod: http.MethodPost,
+			requestBody: types.QuoteRequest{
+				Bookings: append(bookings,
+					types.BookingRequest{RequestID: "quote", Checkin: "2024-02-01", Nights: 2, SellingRate: 100, Margin: 10},
+					types.BookingRequest{RequestID: "quote-2", Checkin: "2024-02-05", Nights: 2, SellingRate: 100, Margin: 10}),
+				Stay: types.QuoteStay{Checkin: "2024-01-03", Nights: 5, Margin: 50},
+			},
+			expectedStatus:       http.StatusOK,
+			expectedBodyContains: `{"min_selling_rate":90,"displaced":["B1","B3"],"schedule":{"request_ids":["quote-3","quote","quote-2"]`,
+		},
+		{
+			name:                 "Quote Above Maximum Selling Rate",
+			requestMethod:        http.MethodPost,
+			requestBody:          types.QuoteRequest{Bookings: bookings, Stay: types.QuoteStay{Checkin: "2024-01-03", Nights: 5, Margin: 1}},
+			expectedStatus:       http.StatusUnprocessableEntity,
+			expectedBodyContains: "no acceptable selling rate: the stay needs more than the maximum of 1000.00",
+		},
+	}
+
+	// --- Execute Scenarios ---
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			req := testutil.NewTestRequest(t, tt.requestMethod, "/quote", tt.requestBody)
+			recorder := httptest.NewRecorder()
+
+			QuoteHandler(recorder, req)
+
+			if status := recorder.Code; status != tt.expectedStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v", status, tt.expectedStatus)
+				t.Logf("Response Body: %s", recorder.Body.String())
+			}
+
+			if tt.expectedBodyContains != "" {
+				if !strings.Contains(recorder.Body.String(), tt.expectedBodyContains) {
+					t.Errorf("handler returned unexpected body: got %q want substring %q", recorder.Body.String(), tt.expectedBodyContains)
+				}
+			}
+		})
+	}
+}
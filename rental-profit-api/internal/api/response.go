@@ -2,7 +2,8 @@ package api
 
 import (
 	"encoding/json"
-	"log"
+	"log/slog"
+	"math"
 	"net/http"
 
 	"rental-profit-api/internal/types"
@@ -13,7 +14,7 @@ func respondJSON(w http.ResponseWriter, code int, payload interface{}) {
 	response, err := json.Marshal(payload)
 	if err != nil {
 		// Log the marshalling error, which is an internal server issue
-		log.Printf("Error marshalling JSON response: %v", err)
+		slog.Error("Error marshalling JSON response", "error", err, "request_id", w.Header().Get(RequestIDHeader))
 		// Send a generic internal server error response
 		respondError(w, http.StatusInternalServerError, "Internal Server Error")
 		return
@@ -22,10 +23,20 @@ func respondJSON(w http.ResponseWriter, code int, payload interface{}) {
 	w.WriteHeader(code)
 	_, err = w.Write(response)
     if err != nil {
-        log.Printf("Error writing response: %v", err)
+        slog.Warn("Error writing response", "error", err, "request_id", w.Header().Get(RequestIDHeader))
     }
 }
 
+// respondError writes an error body carrying the request ID that RequestID
+// set on the response, so callers can quote it when reporting a problem.
 func respondError(w http.ResponseWriter, code int, message string) {
-	respondJSON(w, code, types.ErrorResponse{Message: message})
-}
\ No newline at end of file
+	respondJSON(w, code, types.ErrorResponse{
+		Message:   message,
+		RequestID: w.Header().Get(RequestIDHeader),
+	})
+}
+
+// roundToCents rounds a monetary amount for presentation.
+func roundToCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"unsafe"
+
+	"rental-profit-api/internal/booking"
+	"rental-profit-api/internal/cache"
+	"rental-profit-api/internal/types"
+)
+
+// ResultCache keeps /maximize and /stats results by the hash of their input
+// and tenant, so clients re-posting the same bookings are answered without
+// optimizing again. It is nil, caching nothing, unless set from the server
+// configuration; ETags are sent either way.
+var ResultCache *cache.LRU[string, any]
+
+// resultEntryOverhead approximates the memory a cached result takes besides
+// its own fields: the key, the map slot and the list element.
+const resultEntryOverhead = 256
+
+// resultETag identifies the result of route for bookings. A result depends
+// on nothing but the validated bookings, so the hash of those serves as both
+// the ETag and the cache key, and bodies that differ only in formatting or
+// field order share it. The tenant of ctx is hashed too, so tenants never
+// share results or ETags and cannot tell what bookings another one posted.
+func resultETag(ctx context.Context, route string, bookings []booking.Booking) string {
+	digest := sha256.New()
+	fmt.Fprintf(digest, "%q %s\n", TenantFromContext(ctx), route)
+	for _, b := range bookings {
+		fmt.Fprintf(digest, "%q %s %d %v %v %v\n", b.RequestID, b.Checkin.Format(booking.DateLayout), b.Nights, b.SellingRate, b.Margin, b.CancelProbability)
+	}
+	return `"` + hex.EncodeToString(digest.Sum(nil)[:16]) + `"`
+}
+
+// respondNotModified answers with 304 if the request's If-None-Match names
+// etag, in which case the caller is done.
+func respondNotModified(w http.ResponseWriter, r *http.Request, route, etag string) bool {
+	if !etagMatches(r.Header.Get("If-None-Match"), etag) {
+		return false
+	}
+	resultCacheRequests.Inc(route, "not_modified")
+	w.Header().Set("ETag", etag)
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// etagMatches compares etag with each entry of an If-None-Match header,
+// ignoring weakness as RFC 9110 asks for If-None-Match.
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// cachedResult returns the result kept under etag, or computes and keeps
+// it. Errors are not kept.
+func cachedResult[T any](route, etag string, size func(T) int64, compute func() (T, error)) (T, error) {
+	if ResultCache == nil {
+		return compute()
+	}
+	if cached, ok := ResultCache.Get(etag); ok {
+		if result, ok := cached.(T); ok {
+			resultCacheRequests.Inc(route, "hit")
+			return result, nil
+		}
+	}
+	resultCacheRequests.Inc(route, "miss")
+	result, err := compute()
+	if err == nil {
+		ResultCache.Add(etag, result, resultEntryOverhead+size(result))
+	}
+	return result, err
+}
+
+func scheduleResultSize(result booking.ScheduleResult) int64 {
+	size := int64(unsafe.Sizeof(result)) + int64(len(result.OptimalSchedule))*int64(unsafe.Sizeof(booking.Booking{}))
+	for _, b := range result.OptimalSchedule {
+		size += int64(len(b.RequestID))
+	}
+	return size
+}
+
+func statsResponseSize(result types.StatsResponse) int64 {
+	return int64(unsafe.Sizeof(result))
+}
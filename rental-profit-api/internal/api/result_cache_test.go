@@ -0,0 +1,218 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"rental-profit-api/internal/cache"
+	"rental-profit-api/internal/testutil"
+)
+
+func TestResultCache(t *testing.T) {
+	previous := ResultCache
+	ResultCache = cache.NewLRU[string, any](1 << 20)
+	t.Cleanup(func() { ResultCache = previous })
+
+	const payload = `[{"request_id":"B1","check_in":"2024-01-01","nights":4,"selling_rate":100,"margin":10}]`
+	const reformatted = `[ {"margin":10, "selling_rate":100, "nights":4, "check_in":"2024-01-01", "request_id":"B1"} ]`
+	const other = `[{"request_id":"B2","check_in":"2024-01-01","nights":4,"selling_rate":100,"margin":10}]`
+
+	// Outcomes are counted per route across the whole test, so each case
+	// states the running totals it expects afterwards
+	type counts struct{ hit, miss, notModified float64 }
+	var etag string
+
+	authenticated := Authenticate(testAPIKeys)(http.HandlerFunc(MaximizeProfitHandler))
+
+	// --- Define Test Scenarios ---
+	testCases := []struct {
+		name                 string
+		handler              http.Handler
+		route                string
+		apiKey               string
+		body                 string
+		ifNoneMatch          func() string // Evaluated when the case runs, after etag is known
+		expectedStatus       int
+		expectedBodyContains string
+		expectedCounts       counts
+	}{
+		{
+			name:                 "First request computes",
+			handler:              http.HandlerFunc(MaximizeProfitHandler),
+			route:                "/maximize",
+			body:                 payload,
+			expectedStatus:       http.StatusOK,
+			expectedBodyContains: `"request_ids":["B1"]`,
+			expectedCounts:       counts{miss: 1},
+		},
+		{
+			name:                 "Same bookings formatted differently hit",
+			handler:              http.HandlerFunc(MaximizeProfitHandler),
+			route:                "/maximize",
+			body:                 reformatted,
+			expectedStatus:       http.StatusOK,
+			expectedBodyContains: `"request_ids":["B1"]`,
+			expectedCounts:       counts{hit: 1, miss: 1},
+		},
+		{
+			name:                 "Other bookings miss",
+			handler:              http.HandlerFunc(MaximizeProfitHandler),
+			route:                "/maximize",
+			body:                 other,
+			expectedStatus:       http.StatusOK,
+			expectedBodyContains: `"request_ids":["B2"]`,
+			expectedCounts:       counts{hit: 1, miss: 2},
+		},
+		{
+			name:           "Matching If-None-Match",
+			handler:        http.HandlerFunc(MaximizeProfitHandler),
+			route:          "/maximize",
+			body:           payload,
+			ifNoneMatch:    func() string { return `"other", W/` + etag },
+			expectedStatus: http.StatusNotModified,
+			expectedCounts: counts{hit: 1, miss: 2, notModified: 1},
+		},
+		{
+			name:                 "Stale If-None-Match",
+			handler:              http.HandlerFunc(MaximizeProfitHandler),
+			route:                "/maximize",
+			body:                 other,
+			ifNoneMatch:          func() string { return etag },
+			expectedStatus:       http.StatusOK,
+			expectedBodyContains: `"request_ids":["B2"]`,
+			expectedCounts:       counts{hit: 2, miss: 2, notModified: 1},
+		},
+		{
+			name:                 "Invalid bookings are not cached",
+			handler:              http.HandlerFunc(MaximizeProfitHandler),
+			route:                "/maximize",
+			body:                 `[{"request_id":"B1","check_in":"2024-01-01","nights":0,"selling_rate":100,"margin":10}]`,
+			expectedStatus:       http.StatusBadRequest,
+			expectedBodyContains: "nights",
+			expectedCounts:       counts{hit: 2, miss: 2, notModified: 1},
+		},
+		{
+			name:                 "Stats are cached apart from schedules",
+			handler:              http.HandlerFunc(StatsHandler),
+			route:                "/stats",
+			body:                 payload,
+			expectedStatus:       http.StatusOK,
+			expectedBodyContains: `"avg_night":2.5`,
+			expectedCounts:       counts{miss: 1},
+		},
+		{
+			name:                 "Repeated stats hit",
+			handler:              http.HandlerFunc(StatsHandler),
+			route:                "/stats",
+			body:                 payload,
+			expectedStatus:       http.StatusOK,
+			expectedBodyContains: `"avg_night":2.5`,
+			expectedCounts:       counts{hit: 1, miss: 1},
+		},
+		{
+			name:           "Envelope passes 304 through",
+			handler:        Envelope(http.HandlerFunc(StatsHandler)),
+			route:          "/stats",
+			body:           payload,
+			ifNoneMatch:    func() string { return "*" },
+			expectedStatus: http.StatusNotModified,
+			expectedCounts: counts{hit: 1, miss: 1, notModified: 1},
+		},
+		{
+			name:                 "Another tenant neither matches nor hits",
+			handler:              authenticated,
+			route:                "/maximize",
+			apiKey:               "acme-0123456789abcdef",
+			body:                 payload,
+			ifNoneMatch:          func() string { return etag },
+			expectedStatus:       http.StatusOK,
+			expectedBodyContains: `"request_ids":["B1"]`,
+			expectedCounts:       counts{hit: 2, miss: 3, notModified: 1},
+		},
+		{
+			name:                 "The same tenant hits",
+			handler:              authenticated,
+			route:                "/maximize",
+			apiKey:               "acme-0123456789abcdef",
+			body:                 payload,
+			expectedStatus:       http.StatusOK,
+			expectedBodyContains: `"request_ids":["B1"]`,
+			expectedCounts:       counts{hit: 3, miss: 3, notModified: 1},
+		},
+		{
+			name:                 "A third tenant misses",
+			handler:              authenticated,
+			route:                "/maximize",
+			apiKey:               "globex-0123456789abcdef",
+			body:                 payload,
+			expectedStatus:       http.StatusOK,
+			expectedBodyContains: `"request_ids":["B1"]`,
+			expectedCounts:       counts{hit: 3, miss: 4, notModified: 1},
+		},
+	}
+
+	// --- Execute Scenarios ---
+	before := map[string]counts{}
+	for _, route := range []string{"/maximize", "/stats"} {
+		before[route] = counts{
+			hit:         resultCacheRequests.Value(route, "hit"),
+			miss:        resultCacheRequests.Value(route, "miss"),
+			notModified: resultCacheRequests.Value(route, "not_modified"),
+		}
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := testutil.NewRawTestRequest(t, http.MethodPost, tc.route, tc.body)
+			if tc.ifNoneMatch != nil {
+				req.Header.Set("If-None-Match", tc.ifNoneMatch())
+			}
+			if tc.apiKey != "" {
+				req.Header.Set(APIKeyHeader, tc.apiKey)
+			}
+			recorder := httptest.NewRecorder()
+			tc.handler.ServeHTTP(recorder, req)
+
+			if recorder.Code != tc.expectedStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v. Body: %s", recorder.Code, tc.expectedStatus, recorder.Body.String())
+			}
+			if !strings.Contains(recorder.Body.String(), tc.expectedBodyContains) {
+				t.Errorf("handler returned unexpected body: got %q want substring %q", recorder.Body.String(), tc.expectedBodyContains)
+			}
+			if tc.expectedStatus == http.StatusNotModified && recorder.Body.Len() != 0 {
+				t.Errorf("304 carries a body: %q", recorder.Body.String())
+			}
+			got := recorder.Header().Get("ETag")
+			if (got != "") != (tc.expectedStatus < http.StatusBadRequest) {
+				t.Errorf("ETag = %q on a %d response", got, recorder.Code)
+			}
+			if etag == "" {
+				etag = got
+			}
+
+			start := before[tc.route]
+			counted := counts{
+				hit:         resultCacheRequests.Value(tc.route, "hit") - start.hit,
+				miss:        resultCacheRequests.Value(tc.route, "miss") - start.miss,
+				notModified: resultCacheRequests.Value(tc.route, "not_modified") - start.notModified,
+			}
+			if counted != tc.expectedCounts {
+				t.Errorf("cache outcomes = %+v, want %+v", counted, tc.expectedCounts)
+			}
+		})
+	}
+}
+
+func TestEnvelopeWeakensETag(t *testing.T) {
+	req := testutil.NewRawTestRequest(t, http.MethodPost, "/v2/stats", "[]")
+	recorder := httptest.NewRecorder()
+	Envelope(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"abc"`)
+		respondJSON(w, http.StatusOK, struct{}{})
+	})).ServeHTTP(recorder, req)
+
+	if got := recorder.Header().Get("ETag"); got != `W/"abc"` {
+		t.Errorf("ETag = %q, want %q", got, `W/"abc"`)
+	}
+}
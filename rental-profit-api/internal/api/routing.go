@@ -0,0 +1,56 @@
+package api
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+)
+
+// jsonMediaType is the only media type the API accepts in request bodies.
+const jsonMediaType = "application/json"
+
+// RouteErrors serves mux, but answers requests for unknown routes with a JSON
+// 404, and requests for a known route with the wrong method with a JSON 405
+// whose Allow header lists the methods the route takes. The mux itself would
+// answer both in plain text.
+func RouteErrors(mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, pattern := mux.Handler(r); pattern != "" {
+			mux.ServeHTTP(w, r)
+			return
+		}
+
+		// Only the mux knows whether another method would have matched, so
+		// let it answer and keep just its verdict and Allow header
+		buffer := &bufferedResponse{header: make(http.Header), status: http.StatusOK}
+		mux.ServeHTTP(buffer, r)
+		if buffer.status == http.StatusMethodNotAllowed {
+			w.Header().Set("Allow", buffer.header.Get("Allow"))
+			respondError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+			return
+		}
+		respondError(w, http.StatusNotFound, "Not Found")
+	})
+}
+
+// RequireJSON rejects POST, PUT and PATCH requests whose body is declared as
+// anything but JSON with 415. A missing Content-Type is taken to mean JSON.
+func RequireJSON(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch:
+		default:
+			next.ServeHTTP(w, r)
+			return
+		}
+		if contentType := r.Header.Get("Content-Type"); contentType != "" {
+			mediaType, _, err := mime.ParseMediaType(contentType)
+			if err != nil || mediaType != jsonMediaType {
+				w.Header().Set("Accept", jsonMediaType)
+				respondError(w, http.StatusUnsupportedMediaType, fmt.Sprintf("Unsupported Content-Type %q, send %s", contentType, jsonMediaType))
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,154 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"rental-profit-api/internal/testutil"
+	"rental-profit-api/internal/types"
+)
+
+func TestRouteErrors(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /maximize", MaximizeProfitHandler)
+	mux.HandleFunc("POST /compare", CompareHandler)
+	mux.HandleFunc("POST /quote", QuoteHandler)
+	mux.HandleFunc("POST /schedule/validate", ValidateScheduleHandler)
+	mux.HandleFunc("GET /calendars/{id}", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("DELETE /calendars/{id}", func(w http.ResponseWriter, r *http.Request) {})
+	handler := RouteErrors(mux)
+
+	// --- Define Test Scenarios ---
+	testCases := []struct {
+		name                 string
+		requestMethod        string
+		path                 string
+		requestBody          any
+		expectedStatus       int
+		expectedAllow        string
+		expectedBodyContains string
+	}{
+		{
+			name:           "Matching Route",
+			requestMethod:  http.MethodPost,
+			path:           "/maximize",
+			requestBody:    []types.BookingRequest{},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:                 "Maximize Wrong Method",
+			requestMethod:        http.MethodGet,
+			path:                 "/maximize",
+			expectedStatus:       http.StatusMethodNotAllowed,
+			expectedAllow:        "POST",
+			expectedBodyContains: `"message":"Method Not Allowed"`,
+		},
+		{
+			name:                 "Compare Wrong Method",
+			requestMethod:        http.MethodGet,
+			path:                 "/compare",
+			expectedStatus:       http.StatusMethodNotAllowed,
+			expectedAllow:        "POST",
+			expectedBodyContains: `"message":"Method Not Allowed"`,
+		},
+		{
+			name:                 "Quote Wrong Method",
+			requestMethod:        http.MethodGet,
+			path:                 "/quote",
+			expectedStatus:       http.StatusMethodNotAllowed,
+			expectedAllow:        "POST",
+			expectedBodyContains: `"message":"Method Not Allowed"`,
+		},
+		{
+			name:                 "Schedule Validate Wrong Method",
+			requestMethod:        http.MethodPut,
+			path:                 "/schedule/validate",
+			expectedStatus:       http.StatusMethodNotAllowed,
+			expectedAllow:        "POST",
+			expectedBodyContains: `"message":"Method Not Allowed"`,
+		},
+		{
+			name:                 "Several Allowed Methods",
+			requestMethod:        http.MethodPost,
+			path:                 "/calendars/abc",
+			expectedStatus:       http.StatusMethodNotAllowed,
+			expectedAllow:        "DELETE, GET, HEAD",
+			expectedBodyContains: `"message":"Method Not Allowed"`,
+		},
+		{
+			name:                 "Unknown Route",
+			requestMethod:        http.MethodGet,
+			path:                 "/maximise",
+			expectedStatus:       http.StatusNotFound,
+			expectedBodyContains: `"message":"Not Found"`,
+		},
+	}
+
+	// --- Execute Scenarios ---
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := testutil.NewTestRequest(t, tc.requestMethod, tc.path, tc.requestBody)
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			if recorder.Code != tc.expectedStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v. Body: %s", recorder.Code, tc.expectedStatus, recorder.Body.String())
+			}
+			if got := recorder.Header().Get("Allow"); got != tc.expectedAllow {
+				t.Errorf("Allow = %q, want %q", got, tc.expectedAllow)
+			}
+			if got := recorder.Header().Get("Content-Type"); got != "application/json" {
+				t.Errorf("Content-Type = %q, want %q", got, "application/json")
+			}
+			if !strings.Contains(recorder.Body.String(), tc.expectedBodyContains) {
+				t.Errorf("handler returned unexpected body: got %q want substring %q", recorder.Body.String(), tc.expectedBodyContains)
+			}
+		})
+	}
+}
+
+func TestRequireJSON(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	handler := RequireJSON(ok)
+
+	// --- Define Test Scenarios ---
+	testCases := []struct {
+		name           string
+		requestMethod  string
+		contentType    string
+		expectedStatus int
+	}{
+		{name: "JSON", requestMethod: http.MethodPost, contentType: "application/json", expectedStatus: http.StatusOK},
+		{name: "JSON With Charset", requestMethod: http.MethodPost, contentType: "application/json; charset=utf-8", expectedStatus: http.StatusOK},
+		{name: "Missing Content-Type", requestMethod: http.MethodPost, contentType: "", expectedStatus: http.StatusOK},
+		{name: "Form", requestMethod: http.MethodPost, contentType: "application/x-www-form-urlencoded", expectedStatus: http.StatusUnsupportedMediaType},
+		{name: "Plain Text Update", requestMethod: http.MethodPut, contentType: "text/plain", expectedStatus: http.StatusUnsupportedMediaType},
+		{name: "Malformed", requestMethod: http.MethodPost, contentType: "application/", expectedStatus: http.StatusUnsupportedMediaType},
+		{name: "Ignored Without Body", requestMethod: http.MethodDelete, contentType: "text/plain", expectedStatus: http.StatusOK},
+	}
+
+	// --- Execute Scenarios ---
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := testutil.NewRawTestRequest(t, tc.requestMethod, "/maximize", "[]")
+			req.Header.Set("Content-Type", tc.contentType)
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			if recorder.Code != tc.expectedStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v. Body: %s", recorder.Code, tc.expectedStatus, recorder.Body.String())
+			}
+			if tc.expectedStatus != http.StatusUnsupportedMediaType {
+				return
+			}
+			if got := recorder.Header().Get("Accept"); got != "application/json" {
+				t.Errorf("Accept = %q, want %q", got, "application/json")
+			}
+			if want := "Unsupported Content-Type"; !strings.Contains(recorder.Body.String(), want) {
+				t.Errorf("handler returned unexpected body: got %q want substring %q", recorder.Body.String(), want)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"rental-profit-api/internal/booking"
+	"rental-profit-api/internal/types"
+)
+
+func ValidateScheduleHandler(w http.ResponseWriter, r *http.Request) {
+	var validateRequest types.ValidateScheduleRequest
+	err := decodeJSON(r, &validateRequest)
+	if err != nil {
+		respondDecodeError(w, err)
+		return
+	}
+	defer r.Body.Close()
+
+	// Validate the request content and format
+	domainBookings, err := limitsFor(r.Context()).validateAndMapBookings(validateRequest.Bookings)
+	if err != nil {
+		respondInputError(w, err)
+		return
+	}
+
+	selected, err := booking.SelectBookings(domainBookings, validateRequest.RequestIDs)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, fmt.Sprintf("%v: %v", ErrValidation, err))
+		return
+	}
+
+	// Execute business logic
+	ctx, cancel := withComputeBudget(r.Context())
+	defer cancel()
+	validation, err := runComputation(ctx, func(ctx context.Context) (booking.ScheduleValidation, error) {
+		return booking.ValidateScheduleContext(ctx, selected)
+	})
+	if err != nil {
+		respondComputeError(w, err)
+		return
+	}
+
+	overlaps := make([]types.OverlapPair, len(validation.Overlaps))
+	for i, overlap := range validation.Overlaps {
+		overlaps[i] = types.OverlapPair{First: overlap.First.RequestID, Second: overlap.Second.RequestID}
+	}
+
+	response := types.ValidateScheduleResponse{
+		Valid:             validation.Feasible(),
+		Overlaps:          overlaps,
+		OverlapCount:      validation.OverlapCount,
+		OverlapsTruncated: validation.Truncated(),
+		TotalProfit:       roundToCents(validation.Schedule.TotalProfit),
+	}
+
+	respondJSON(w, http.StatusOK, response)
+}
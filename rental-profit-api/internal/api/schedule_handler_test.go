@@ -0,0 +1,93 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"rental-profit-api/internal/testutil"
+	"rental-profit-api/internal/types"
+)
+
+func TestValidateScheduleHandler(t *testing.T) {
+	bookings := []types.BookingRequest{
+		{RequestID: "B1", Checkin: "2024-01-01", Nights: 5, SellingRate: 100, Margin: 10},
+		{RequestID: "B2", Checkin: "2024-01-04", Nights: 4, SellingRate: 150, Margin: 20},
+		{RequestID: "B3", Checkin: "2024-01-07", Nights: 2, SellingRate: 100, Margin: 10},
+	}
+	// 20 stays on the same dates overlap in 190 pairs, more than are listed
+	sameDates := make([]types.BookingRequest, 20)
+	sameDateIDs := make([]string, len(sameDates))
+	for i := range sameDates {
+		sameDateIDs[i] = fmt.Sprintf("S%d", i)
+		sameDates[i] = types.BookingRequest{RequestID: sameDateIDs[i], Checkin: "2024-01-01", Nights: 3, SellingRate: 100, Margin: 10}
+	}
+
+	// --- Define Test Scenarios ---
+	testCases := []struct {
+		name                 string
+		requestMethod        string
+		requestBody          interface{}
+		expectedStatus       int
+		expectedBodyContains string
+	}{
+		{
+			name:                 "Validation Error (Bad Booking)",
+			requestMethod:        http.MethodPost,
+			requestBody:          types.ValidateScheduleRequest{Bookings: []types.BookingRequest{{RequestID: "B1", Checkin: "2024-01-01", Nights: 0, SellingRate: 10, Margin: 10}}},
+			expectedStatus:       http.StatusBadRequest,
+			expectedBodyContains: "nights must be positive",
+		},
+		{
+			name:                 "Unknown Request ID",
+			requestMethod:        http.MethodPost,
+			requestBody:          types.ValidateScheduleRequest{Bookings: bookings, RequestIDs: []string{"B1", "B9"}},
+			expectedStatus:       http.StatusBadRequest,
+			expectedBodyContains: "unknown request_id: B9",
+		},
+		{
+			name:                 "Feasible Schedule",
+			requestMethod:        http.MethodPost,
+			requestBody:          types.ValidateScheduleRequest{Bookings: bookings, RequestIDs: []string{"B1", "B3"}},
+			expectedStatus:       http.StatusOK,
+			expectedBodyContains: `{"valid":true,"overlaps":[],"overlap_count":0,"overlaps_truncated":false,"total_profit":20}`,
+		},
+		{
+			name:                 "Overlapping Schedule",
+			requestMethod:        http.MethodPost,
+			requestBody:          types.ValidateScheduleRequest{Bookings: bookings, RequestIDs: []string{"B3", "B2", "B1"}},
+			expectedStatus:       http.StatusOK,
+			expectedBodyContains: `{"valid":false,"overlaps":[{"first":"B1","second":"B2"},{"first":"B2","second":"B3"}],"overlap_count":2,"overlaps_truncated":false,"total_profit":50}`,
+		},
+		{
+			name:                 "Overlaps Are Truncated",
+			requestMethod:        http.MethodPost,
+			requestBody:          types.ValidateScheduleRequest{Bookings: sameDates, RequestIDs: sameDateIDs},
+			expectedStatus:       http.StatusOK,
+			expectedBodyContains: `"overlap_count":190,"overlaps_truncated":true`,
+		},
+	}
+
+	// --- Execute Scenarios ---
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			req := testutil.NewTestRequest(t, tt.requestMethod, "/schedule/validate", tt.requestBody)
+			recorder := httptest.NewRecorder()
+
+			ValidateScheduleHandler(recorder, req)
+
+			if status := recorder.Code; status != tt.expectedStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v", status, tt.expectedStatus)
+				t.Logf("Response Body: %s", recorder.Body.String())
+			}
+
+			if tt.expectedBodyContains != "" {
+				if !strings.Contains(recorder.Body.String(), tt.expectedBodyContains) {
+					t.Errorf("handler returned unexpected body: got %q want substring %q", recorder.Body.String(), tt.expectedBodyContains)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"context"
+
+	"rental-profit-api/internal/booking"
+	"rental-profit-api/internal/ratelimit"
+)
+
+// TenantSettings are the settings a tenant is served with when they differ
+// from the deployment's.
+type TenantSettings struct {
+	Limits            Limits
+	OverbookingPolicy booking.OverbookingPolicy
+	StrictJSON        bool
+	RateLimiter       *ratelimit.Limiter // Nil for no rate limit
+}
+
+// Tenants holds the settings of the tenants the keys file overrides settings
+// for. Every other tenant is served with DefaultLimits,
+// DefaultOverbookingPolicy, DefaultStrictJSON and the limiter given to
+// RateLimit. It is set from the keys file at startup.
+var Tenants map[string]TenantSettings
+
+func tenantSettings(ctx context.Context) (TenantSettings, bool) {
+	settings, ok := Tenants[TenantFromContext(ctx)]
+	return settings, ok
+}
+
+// limitsFor returns the limits of the tenant ctx was authenticated for.
+func limitsFor(ctx context.Context) Limits {
+	if settings, ok := tenantSettings(ctx); ok {
+		return settings.Limits
+	}
+	return DefaultLimits
+}
+
+// overbookingPolicyFor returns the default overbooking policy of the tenant
+// ctx was authenticated for.
+func overbookingPolicyFor(ctx context.Context) booking.OverbookingPolicy {
+	if settings, ok := tenantSettings(ctx); ok {
+		return settings.OverbookingPolicy
+	}
+	return DefaultOverbookingPolicy
+}
+
+// rateLimiterFor returns the limiter of the tenant ctx was authenticated
+// for, or fallback for tenants without their own rate limit settings.
+func rateLimiterFor(ctx context.Context, fallback *ratelimit.Limiter) *ratelimit.Limiter {
+	if settings, ok := tenantSettings(ctx); ok {
+		return settings.RateLimiter
+	}
+	return fallback
+}
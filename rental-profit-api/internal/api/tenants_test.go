@@ -0,0 +1,141 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"rental-profit-api/internal/booking"
+	"rental-profit-api/internal/ratelimit"
+	"rental-profit-api/internal/testutil"
+)
+
+func TestTenantSettings(t *testing.T) {
+	previousLimits, previousTenants := DefaultLimits, Tenants
+	DefaultLimits = Limits{MaxBookings: 3}
+	Tenants = map[string]TenantSettings{
+		"acme": {
+			Limits:      Limits{MaxBookings: 1},
+			StrictJSON:  true,
+			RateLimiter: ratelimit.NewLimiter(0.001, 3),
+		},
+	}
+	t.Cleanup(func() { DefaultLimits, Tenants = previousLimits, previousTenants })
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /maximize", MaximizeProfitHandler)
+	handler := Authenticate(testAPIKeys)(RateLimit(nil)(mux))
+
+	const (
+		acmeKey   = "acme-0123456789abcdef"
+		globexKey = "globex-0123456789abcdef"
+		one       = `[{"request_id":"B1","check_in":"2024-01-01","nights":2,"selling_rate":100,"margin":10}]`
+		two       = `[{"request_id":"B1","check_in":"2024-01-01","nights":2,"selling_rate":100,"margin":10},` +
+			`{"request_id":"B2","check_in":"2024-01-05","nights":2,"selling_rate":100,"margin":10}]`
+		extraField = `[{"request_id":"B1","check_in":"2024-01-01","nights":2,"selling_rate":100,"margin":10,"note":"x"}]`
+	)
+
+	// --- Define Test Scenarios ---
+	testCases := []struct {
+		name                 string
+		key                  string
+		body                 string
+		expectedStatus       int
+		expectedBodyContains string
+	}{
+		{
+			name:                 "Tenant Limits",
+			key:                  acmeKey,
+			body:                 two,
+			expectedStatus:       http.StatusRequestEntityTooLarge,
+			expectedBodyContains: "2 bookings exceed the maximum of 1 per request",
+		},
+		{
+			name:           "Default Limits",
+			key:            globexKey,
+			body:           two,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:                 "Tenant Strict JSON",
+			key:                  acmeKey,
+			body:                 extraField,
+			expectedStatus:       http.StatusBadRequest,
+			expectedBodyContains: `unknown field \"note\" on item 0`,
+		},
+		{
+			name:           "Default Lenient JSON",
+			key:            globexKey,
+			body:           extraField,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Last Request Within Tenant Rate Limit",
+			key:            acmeKey,
+			body:           one,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:                 "Tenant Rate Limit",
+			key:                  acmeKey,
+			body:                 one,
+			expectedStatus:       http.StatusTooManyRequests,
+			expectedBodyContains: "Rate limit exceeded",
+		},
+		{
+			name:           "No Default Rate Limit",
+			key:            globexKey,
+			body:           one,
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	// --- Execute Scenarios ---
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := testutil.NewRawTestRequest(t, http.MethodPost, "/maximize", tc.body)
+			req.Header.Set(APIKeyHeader, tc.key)
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			if recorder.Code != tc.expectedStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v. Body: %s", recorder.Code, tc.expectedStatus, recorder.Body.String())
+			}
+			if !strings.Contains(recorder.Body.String(), tc.expectedBodyContains) {
+				t.Errorf("handler returned unexpected body: got %q want substring %q", recorder.Body.String(), tc.expectedBodyContains)
+			}
+		})
+	}
+}
+
+func TestTenantOverbookingPolicy(t *testing.T) {
+	previousPolicy, previousTenants := DefaultOverbookingPolicy, Tenants
+	DefaultOverbookingPolicy = booking.OverbookingPolicy{}
+	Tenants = map[string]TenantSettings{
+		"acme": {OverbookingPolicy: booking.OverbookingPolicy{MaxWalkProbability: 0.5, WalkPenalty: 10}},
+	}
+	t.Cleanup(func() { DefaultOverbookingPolicy, Tenants = previousPolicy, previousTenants })
+
+	// --- Define Test Scenarios ---
+	testCases := []struct {
+		tenant   string
+		expected booking.OverbookingPolicy
+	}{
+		{tenant: "acme", expected: booking.OverbookingPolicy{MaxWalkProbability: 0.5, WalkPenalty: 10}},
+		{tenant: "globex", expected: booking.OverbookingPolicy{}},
+	}
+
+	// --- Execute Scenarios ---
+	for _, tc := range testCases {
+		req := testutil.NewRawTestRequest(t, http.MethodPost, "/maximize?mode=stochastic", "[]")
+		req.Header.Set(APIKeyHeader, tc.tenant+"-0123456789abcdef")
+		var policy booking.OverbookingPolicy
+		Authenticate(testAPIKeys)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			policy, _, _ = parseOverbookingPolicy(r.URL.Query(), overbookingPolicyFor(r.Context()))
+		})).ServeHTTP(httptest.NewRecorder(), req)
+		if policy != tc.expected {
+			t.Errorf("%s: policy = %+v, want %+v", tc.tenant, policy, tc.expected)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"context"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"rental-profit-api/internal/ratelimit"
+)
+
+// computeRetryAfter is the Retry-After hint sent when too many
+// optimizations are already waiting for a slot.
+const computeRetryAfter = time.Second
+
+// ComputeSlots caps how many optimizations run at once across all requests
+// and queues a bounded number of others. Nil lifts the cap. It is set from
+// the server configuration at startup.
+var ComputeSlots *ratelimit.Semaphore
+
+type backgroundComputeKey struct{}
+
+// withBackgroundCompute marks ctx as belonging to a background job. Jobs
+// are already bounded by their own worker pool, so they wait for a compute
+// slot however long the queue is instead of failing.
+func withBackgroundCompute(ctx context.Context) context.Context {
+	return context.WithValue(ctx, backgroundComputeKey{}, true)
+}
+
+// acquireComputeSlot takes one of the ComputeSlots and records how long it
+// waited for it.
+func acquireComputeSlot(ctx context.Context) (release func(), err error) {
+	if ComputeSlots == nil {
+		return func() {}, nil
+	}
+	start := time.Now()
+	if background, _ := ctx.Value(backgroundComputeKey{}).(bool); background {
+		release, err = ComputeSlots.Wait(ctx)
+	} else {
+		release, err = ComputeSlots.Acquire(ctx)
+	}
+	if err == nil {
+		computeQueueWait.Observe(time.Since(start).Seconds())
+	}
+	return release, err
+}
+
+// RateLimit throttles each client to the limiter's rate, answering 429 with
+// Retry-After once a client runs out of tokens. Clients are told apart by
+// API key, or by IP address while the API is open, so it has to run after
+// Authenticate. Tenants in Tenants are throttled by their own limiter
+// instead. A nil limiter lets every request through.
+func RateLimit(limiter *ratelimit.Limiter) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			limiter := rateLimiterFor(r.Context(), limiter)
+			if limiter == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if allowed, wait := limiter.Allow(clientID(r)); !allowed {
+				throttledRequests.Inc("rate_limit")
+				respondTooManyRequests(w, wait, "Rate limit exceeded, retry later")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// clientID names the client for rate limiting: its API key if it sent one,
+// otherwise its IP address.
+func clientID(r *http.Request) string {
+	if keyID, ok := r.Context().Value(apiKeyIDKey{}).(string); ok {
+		return "key:" + keyID
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// respondTooManyRequests answers 429 with a Retry-After of whole seconds,
+// rounded up so a client retrying on time is let in.
+func respondTooManyRequests(w http.ResponseWriter, retryAfter time.Duration, message string) {
+	seconds := max(1, int(math.Ceil(retryAfter.Seconds())))
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	respondError(w, http.StatusTooManyRequests, message)
+}
@@ -0,0 +1,183 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"rental-profit-api/internal/booking"
+	"rental-profit-api/internal/ratelimit"
+	"rental-profit-api/internal/store"
+	"rental-profit-api/internal/testutil"
+	"rental-profit-api/internal/types"
+)
+
+func TestRateLimit(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	// A burst of one at a negligible rate: every client gets exactly one
+	// request through during the test
+	handler := Authenticate(testAPIKeys)(RateLimit(ratelimit.NewLimiter(0.001, 1))(ok))
+	openHandler := RateLimit(ratelimit.NewLimiter(0.001, 1))(ok)
+
+	// --- Define Test Scenarios ---
+	testCases := []struct {
+		name           string
+		handler        http.Handler
+		key            string
+		remoteAddr     string
+		expectedStatus int
+	}{
+		{name: "First request of a key", handler: handler, key: "acme-0123456789abcdef", remoteAddr: "192.0.2.1:1000", expectedStatus: http.StatusOK},
+		{name: "Same key from another address", handler: handler, key: "acme-0123456789abcdef", remoteAddr: "192.0.2.2:1000", expectedStatus: http.StatusTooManyRequests},
+		{name: "Other key from the same address", handler: handler, key: "globex-0123456789abcdef", remoteAddr: "192.0.2.1:1000", expectedStatus: http.StatusOK},
+		{name: "First request of an address", handler: openHandler, remoteAddr: "192.0.2.1:1000", expectedStatus: http.StatusOK},
+		{name: "Same address on another port", handler: openHandler, remoteAddr: "192.0.2.1:2000", expectedStatus: http.StatusTooManyRequests},
+		{name: "Other address", handler: openHandler, remoteAddr: "192.0.2.2:1000", expectedStatus: http.StatusOK},
+	}
+
+	// --- Execute Scenarios ---
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := testutil.NewTestRequest(t, http.MethodGet, "/", nil)
+			req.RemoteAddr = tc.remoteAddr
+			if tc.key != "" {
+				req.Header.Set(APIKeyHeader, tc.key)
+			}
+			recorder := httptest.NewRecorder()
+			tc.handler.ServeHTTP(recorder, req)
+
+			if recorder.Code != tc.expectedStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v. Body: %s", recorder.Code, tc.expectedStatus, recorder.Body.String())
+			}
+			if tc.expectedStatus != http.StatusTooManyRequests {
+				return
+			}
+			// The next token is 1000s away at 0.001 requests per second
+			if got := recorder.Header().Get("Retry-After"); got != "1000" {
+				t.Errorf("Retry-After = %q, want %q", got, "1000")
+			}
+			if want := `"message":"Rate limit exceeded, retry later"`; !strings.Contains(recorder.Body.String(), want) {
+				t.Errorf("handler returned unexpected body: got %q want substring %q", recorder.Body.String(), want)
+			}
+		})
+	}
+}
+
+func TestComputeQueueFull(t *testing.T) {
+	previous := ComputeSlots
+	ComputeSlots = ratelimit.NewSemaphore(1, 0)
+	t.Cleanup(func() { ComputeSlots = previous })
+
+	// Occupy the only slot
+	release, err := ComputeSlots.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Acquire() unexpected error: %v", err)
+	}
+	defer func() { release() }()
+
+	req := testutil.NewTestRequest(t, http.MethodPost, "/maximize", []types.BookingRequest{
+		{RequestID: "B1", Checkin: "2024-01-01", Nights: 1, SellingRate: 100, Margin: 10},
+	})
+	recorder := httptest.NewRecorder()
+	MaximizeProfitHandler(recorder, req)
+
+	if status := recorder.Code; status != http.StatusTooManyRequests {
+		t.Errorf("handler returned wrong status code: got %v want %v. Body: %s", status, http.StatusTooManyRequests, recorder.Body.String())
+	}
+	if got := recorder.Header().Get("Retry-After"); got != "1" {
+		t.Errorf("Retry-After = %q, want %q", got, "1")
+	}
+	if want := "Too many optimizations in progress"; !strings.Contains(recorder.Body.String(), want) {
+		t.Errorf("handler returned unexpected body: got %q want substring %q", recorder.Body.String(), want)
+	}
+
+	// Background jobs wait for the slot instead of being turned away
+	done := make(chan error, 1)
+	go func() {
+		_, err := findMaxProfit(withBackgroundCompute(context.Background()), []booking.Booking{
+			{RequestID: "B1", Checkin: time.Now(), Nights: 1, SellingRate: 100, Margin: 10},
+		})
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		t.Fatalf("background optimization finished while the slot was taken: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	release()
+	release = func() {}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("background optimization unexpected error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("background optimization did not get the released slot")
+	}
+}
+
+func TestComputeQueueFullOnEveryRoute(t *testing.T) {
+	previous := ComputeSlots
+	ComputeSlots = ratelimit.NewSemaphore(1, 0)
+	t.Cleanup(func() { ComputeSlots = previous })
+
+	bookings := []types.BookingRequest{
+		{RequestID: "B1", Checkin: "2024-01-01", Nights: 4, SellingRate: 100, Margin: 10},
+		{RequestID: "B2", Checkin: "2024-01-03", Nights: 2, SellingRate: 150, Margin: 20},
+	}
+	calendarStore := store.NewCalendarStore()
+	calendar, err := booking.NewCalendar(nil)
+	if err != nil {
+		t.Fatalf("NewCalendar() unexpected error: %v", err)
+	}
+	calendarID, err := calendarStore.Create("", calendar)
+	if err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+	calendars := NewCalendarHandler(calendarStore, nil)
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /maximize", MaximizeProfitHandler)
+	mux.HandleFunc("POST /quote", QuoteHandler)
+	mux.HandleFunc("POST /schedule/validate", ValidateScheduleHandler)
+	mux.HandleFunc("POST /calendars", calendars.Create)
+	mux.HandleFunc("POST /calendars/{id}/bookings", calendars.AddBooking)
+
+	// --- Define Test Scenarios ---
+	testCases := []struct {
+		name        string
+		path        string
+		requestBody interface{}
+	}{
+		{"Stochastic Maximize", "/maximize?mode=stochastic", bookings},
+		{"Quote", "/quote", types.QuoteRequest{Bookings: bookings, Stay: types.QuoteStay{Checkin: "2024-01-02", Nights: 2, Margin: 10}}},
+		{"Schedule Validation", "/schedule/validate", types.ValidateScheduleRequest{Bookings: bookings, RequestIDs: []string{"B1", "B2"}}},
+		{"Calendar Creation", "/calendars", bookings},
+		{"Calendar Change", "/calendars/" + calendarID + "/bookings", bookings[0]},
+	}
+
+	// Occupy the only slot
+	release, err := ComputeSlots.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Acquire() unexpected error: %v", err)
+	}
+	defer release()
+
+	// --- Execute Scenarios ---
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			req := testutil.NewTestRequest(t, http.MethodPost, tt.path, tt.requestBody)
+			recorder := httptest.NewRecorder()
+			mux.ServeHTTP(recorder, req)
+
+			if status := recorder.Code; status != http.StatusTooManyRequests {
+				t.Errorf("handler returned wrong status code: got %v want %v. Body: %s", status, http.StatusTooManyRequests, recorder.Body.String())
+			}
+		})
+	}
+	if calendar.Len() != 0 {
+		t.Errorf("calendar has %d bookings, want the refused change not applied", calendar.Len())
+	}
+}
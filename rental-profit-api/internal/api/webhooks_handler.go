@@ -0,0 +1,165 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+
+	"rental-profit-api/internal/store"
+	"rental-profit-api/internal/types"
+	"rental-profit-api/internal/webhooks"
+)
+
+// Webhook event types.
+const (
+	EventJobCompleted    = "job.completed"    // An async optimization finished, successfully or not
+	EventCalendarChanged = "calendar.changed" // A booking was added to, updated in or cancelled from a calendar
+)
+
+var webhookEvents = []string{EventJobCompleted, EventCalendarChanged}
+
+// EventPublisher notifies a tenant's subscribers of server-side events.
+// Publish must not block the request that triggered the event.
+type EventPublisher interface {
+	Publish(tenant, eventType string, data any)
+}
+
+// noEvents is the publisher used while webhooks are disabled.
+type noEvents struct{}
+
+func (noEvents) Publish(string, string, any) {}
+
+// WebhookHandler manages webhook registrations and exposes their delivery
+// log.
+type WebhookHandler struct {
+	webhooks *webhooks.Service
+}
+
+func NewWebhookHandler(service *webhooks.Service) *WebhookHandler {
+	return &WebhookHandler{webhooks: service}
+}
+
+// Create handles POST /webhooks. The response is the only place the signing
+// secret is ever returned.
+func (h *WebhookHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var webhookRequest types.WebhookRequest
+	err := decodeJSON(r, &webhookRequest)
+	if err != nil {
+		respondDecodeError(w, err)
+		return
+	}
+	defer r.Body.Close()
+
+	events, err := validateWebhookEvents(webhookRequest.Events)
+	if err != nil {
+		respondInputError(w, err)
+		return
+	}
+
+	webhook, err := h.webhooks.Register(TenantFromContext(r.Context()), webhookRequest.URL, events, webhookRequest.Secret)
+	if errors.Is(err, webhooks.ErrInvalidURL) || errors.Is(err, webhooks.ErrPrivateAddress) {
+		respondInputError(w, validationError("url", "%v, got %q", err, webhookRequest.URL))
+		return
+	}
+	if errors.Is(err, webhooks.ErrTooManyWebhooks) {
+		respondInputError(w, limitError("webhooks", "%v", err))
+		return
+	}
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	response := toWebhookResponse(webhook)
+	response.Secret = webhook.Secret
+	w.Header().Set("Location", "/webhooks/"+webhook.ID)
+	respondJSON(w, http.StatusCreated, response)
+}
+
+// List handles GET /webhooks.
+func (h *WebhookHandler) List(w http.ResponseWriter, r *http.Request) {
+	response := []types.WebhookResponse{}
+	for _, webhook := range h.webhooks.List(TenantFromContext(r.Context())) {
+		response = append(response, toWebhookResponse(webhook))
+	}
+	respondJSON(w, http.StatusOK, response)
+}
+
+// Get handles GET /webhooks/{id}.
+func (h *WebhookHandler) Get(w http.ResponseWriter, r *http.Request) {
+	webhook, err := h.webhooks.Get(TenantFromContext(r.Context()), r.PathValue("id"))
+	if err != nil {
+		respondWebhookError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, toWebhookResponse(webhook))
+}
+
+// Delete handles DELETE /webhooks/{id}.
+func (h *WebhookHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if err := h.webhooks.Delete(TenantFromContext(r.Context()), r.PathValue("id")); err != nil {
+		respondWebhookError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Deliveries handles GET /webhooks/{id}/deliveries, listing the most recent
+// delivery attempts first.
+func (h *WebhookHandler) Deliveries(w http.ResponseWriter, r *http.Request) {
+	deliveries, err := h.webhooks.Deliveries(TenantFromContext(r.Context()), r.PathValue("id"))
+	if err != nil {
+		respondWebhookError(w, err)
+		return
+	}
+	response := make([]types.WebhookDeliveryResponse, 0, len(deliveries))
+	for _, d := range deliveries {
+		response = append(response, types.WebhookDeliveryResponse{
+			DeliveryID:  d.ID,
+			EventID:     d.EventID,
+			Event:       d.EventType,
+			Attempt:     d.Attempt,
+			StatusCode:  d.StatusCode,
+			Succeeded:   d.Succeeded,
+			Error:       d.Error,
+			DurationMs:  float64(d.Duration.Microseconds()) / 1000,
+			DeliveredAt: jsonTimestamp(d.At),
+		})
+	}
+	respondJSON(w, http.StatusOK, response)
+}
+
+// validateWebhookEvents requires at least one known event type and drops
+// duplicates.
+func validateWebhookEvents(events []string) ([]string, error) {
+	if len(events) == 0 {
+		return nil, validationError("events", "events must list at least one of %v", webhookEvents)
+	}
+	var unique []string
+	for _, event := range events {
+		if !slices.Contains(webhookEvents, event) {
+			return nil, validationError("events", "unknown event %q, expected one of %v", event, webhookEvents)
+		}
+		if !slices.Contains(unique, event) {
+			unique = append(unique, event)
+		}
+	}
+	return unique, nil
+}
+
+func respondWebhookError(w http.ResponseWriter, err error) {
+	if errors.Is(err, store.ErrNotFound) {
+		respondError(w, http.StatusNotFound, "Webhook not found")
+		return
+	}
+	respondError(w, http.StatusInternalServerError, "Internal Server Error")
+}
+
+func toWebhookResponse(webhook webhooks.Webhook) types.WebhookResponse {
+	return types.WebhookResponse{
+		WebhookID: webhook.ID,
+		URL:       webhook.URL,
+		Events:    webhook.Events,
+		CreatedAt: jsonTimestamp(webhook.CreatedAt),
+	}
+}
@@ -0,0 +1,303 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"rental-profit-api/internal/jobs"
+	"rental-profit-api/internal/store"
+	"rental-profit-api/internal/testutil"
+	"rental-profit-api/internal/types"
+	"rental-profit-api/internal/webhooks"
+)
+
+func newWebhookService(t *testing.T) *webhooks.Service {
+	t.Helper()
+	service := webhooks.NewService(webhooks.Options{
+		Workers:        1,
+		QueueSize:      16,
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     time.Millisecond,
+		Timeout:        time.Second,
+		LogSize:        10,
+		// The receivers listen on loopback
+		AllowPrivateNetworks: true,
+	})
+	t.Cleanup(service.Close)
+	return service
+}
+
+func registerWebhookRoutes(mux *http.ServeMux, service *webhooks.Service) {
+	webhookHandler := NewWebhookHandler(service)
+	mux.HandleFunc("POST /webhooks", webhookHandler.Create)
+	mux.HandleFunc("GET /webhooks", webhookHandler.List)
+	mux.HandleFunc("GET /webhooks/{id}", webhookHandler.Get)
+	mux.HandleFunc("DELETE /webhooks/{id}", webhookHandler.Delete)
+	mux.HandleFunc("GET /webhooks/{id}/deliveries", webhookHandler.Deliveries)
+}
+
+func TestWebhookHandler(t *testing.T) {
+	mux := http.NewServeMux()
+	registerWebhookRoutes(mux, newWebhookService(t))
+
+	req := testutil.NewTestRequest(t, http.MethodPost, "/webhooks", types.WebhookRequest{
+		URL:    "https://example.com/hooks",
+		Events: []string{EventJobCompleted, EventJobCompleted},
+	})
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, req)
+	if recorder.Code != http.StatusCreated {
+		t.Fatalf("create returned wrong status code: got %v want %v. Body: %s", recorder.Code, http.StatusCreated, recorder.Body.String())
+	}
+	var created types.WebhookResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &created); err != nil {
+		t.Fatalf("create returned invalid JSON: %v", err)
+	}
+	if created.Secret == "" || len(created.Events) != 1 {
+		t.Errorf("create = %+v, want a generated secret and deduplicated events", created)
+	}
+	if location := recorder.Header().Get("Location"); location != "/webhooks/"+created.WebhookID {
+		t.Errorf("create returned Location %q, want /webhooks/%s", location, created.WebhookID)
+	}
+
+	// --- Define Test Scenarios ---
+	testCases := []struct {
+		name                 string
+		requestMethod        string
+		requestPath          string
+		expectedStatus       int
+		expectedBodyContains string
+	}{
+		{
+			name:                 "Get hides the secret",
+			requestMethod:        http.MethodGet,
+			requestPath:          "/webhooks/" + created.WebhookID,
+			expectedStatus:       http.StatusOK,
+			expectedBodyContains: `"url":"https://example.com/hooks","events":["job.completed"],"created_at"`,
+		},
+		{
+			name:                 "List",
+			requestMethod:        http.MethodGet,
+			requestPath:          "/webhooks",
+			expectedStatus:       http.StatusOK,
+			expectedBodyContains: `[{"webhook_id":"` + created.WebhookID + `"`,
+		},
+		{
+			name:                 "No deliveries yet",
+			requestMethod:        http.MethodGet,
+			requestPath:          "/webhooks/" + created.WebhookID + "/deliveries",
+			expectedStatus:       http.StatusOK,
+			expectedBodyContains: `[]`,
+		},
+		{
+			name:           "Delete",
+			requestMethod:  http.MethodDelete,
+			requestPath:    "/webhooks/" + created.WebhookID,
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:                 "Get after delete",
+			requestMethod:        http.MethodGet,
+			requestPath:          "/webhooks/" + created.WebhookID,
+			expectedStatus:       http.StatusNotFound,
+			expectedBodyContains: `"message":"Webhook not found"`,
+		},
+		{
+			name:                 "Deliveries after delete",
+			requestMethod:        http.MethodGet,
+			requestPath:          "/webhooks/" + created.WebhookID + "/deliveries",
+			expectedStatus:       http.StatusNotFound,
+			expectedBodyContains: `"message":"Webhook not found"`,
+		},
+		{
+			name:                 "Delete unknown",
+			requestMethod:        http.MethodDelete,
+			requestPath:          "/webhooks/unknown",
+			expectedStatus:       http.StatusNotFound,
+			expectedBodyContains: `"message":"Webhook not found"`,
+		},
+	}
+
+	// --- Execute Scenarios ---
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			mux.ServeHTTP(recorder, testutil.NewTestRequest(t, tc.requestMethod, tc.requestPath, nil))
+
+			if recorder.Code != tc.expectedStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v. Body: %s", recorder.Code, tc.expectedStatus, recorder.Body.String())
+			}
+			if tc.expectedBodyContains != "" && !strings.Contains(recorder.Body.String(), tc.expectedBodyContains) {
+				t.Errorf("handler returned unexpected body: got %q want substring %q", recorder.Body.String(), tc.expectedBodyContains)
+			}
+		})
+	}
+}
+
+func TestWebhookHandlerCreateErrors(t *testing.T) {
+	service := webhooks.NewService(webhooks.Options{MaxPerTenant: 1, AllowPrivateNetworks: true})
+	t.Cleanup(service.Close)
+	if _, err := service.Register("", "https://example.com/hooks", []string{EventJobCompleted}, ""); err != nil {
+		t.Fatalf("Register() unexpected error: %v", err)
+	}
+	mux := http.NewServeMux()
+	registerWebhookRoutes(mux, service)
+
+	// --- Define Test Scenarios ---
+	testCases := []struct {
+		name                 string
+		rawBody              string
+		expectedStatus       int
+		expectedBodyContains string
+	}{
+		{
+			name:                 "Invalid JSON",
+			rawBody:              `{"url":`,
+			expectedStatus:       http.StatusBadRequest,
+			expectedBodyContains: "Invalid JSON format",
+		},
+		{
+			name:                 "No events",
+			rawBody:              `{"url":"https://example.com/hooks"}`,
+			expectedStatus:       http.StatusBadRequest,
+			expectedBodyContains: "events must list at least one of",
+		},
+		{
+			name:                 "Unknown event",
+			rawBody:              `{"url":"https://example.com/hooks","events":["job.started"]}`,
+			expectedStatus:       http.StatusBadRequest,
+			expectedBodyContains: `unknown event \"job.started\"`,
+		},
+		{
+			name:                 "Relative URL",
+			rawBody:              `{"url":"/hooks","events":["job.completed"]}`,
+			expectedStatus:       http.StatusBadRequest,
+			expectedBodyContains: "webhook url must be an absolute http or https URL",
+		},
+		{
+			name:                 "Too many webhooks",
+			rawBody:              `{"url":"https://example.com/hooks","events":["job.completed"]}`,
+			expectedStatus:       http.StatusUnprocessableEntity,
+			expectedBodyContains: "too many webhooks: at most 1 per tenant",
+		},
+	}
+
+	// --- Execute Scenarios ---
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			mux.ServeHTTP(recorder, testutil.NewRawTestRequest(t, http.MethodPost, "/webhooks", tc.rawBody))
+
+			if recorder.Code != tc.expectedStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v. Body: %s", recorder.Code, tc.expectedStatus, recorder.Body.String())
+			}
+			if !strings.Contains(recorder.Body.String(), tc.expectedBodyContains) {
+				t.Errorf("handler returned unexpected body: got %q want substring %q", recorder.Body.String(), tc.expectedBodyContains)
+			}
+		})
+	}
+}
+
+// TestWebhookEvents registers an httptest receiver and checks that job
+// completions and committed calendar changes reach it, signed, while dry
+// runs do not.
+func TestWebhookEvents(t *testing.T) {
+	type received struct {
+		event struct {
+			Type string          `json:"type"`
+			Data json.RawMessage `json:"data"`
+		}
+		signature string
+	}
+	deliveries := make(chan received, 16)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		d := received{signature: r.Header.Get(webhooks.SignatureHeader)}
+		if err := json.Unmarshal(body, &d.event); err != nil {
+			t.Errorf("receiver got invalid JSON: %v", err)
+		}
+		deliveries <- d
+	}))
+	defer receiver.Close()
+
+	service := newWebhookService(t)
+	manager := jobs.NewManager[types.MaximizeResponse](jobs.Options{Workers: 1, TTL: time.Hour})
+	t.Cleanup(manager.Close)
+	calendars := NewCalendarHandler(store.NewCalendarStore(), service)
+	jobHandler := NewJobHandler(manager, service)
+
+	mux := http.NewServeMux()
+	registerWebhookRoutes(mux, service)
+	mux.HandleFunc("POST /calendars", calendars.Create)
+	mux.HandleFunc("POST /calendars/{id}/bookings", calendars.AddBooking)
+	mux.HandleFunc("POST /jobs/maximize", jobHandler.SubmitMaximize)
+
+	serve := func(method, path string, payload any) *httptest.ResponseRecorder {
+		t.Helper()
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, testutil.NewTestRequest(t, method, path, payload))
+		if recorder.Code >= 300 {
+			t.Fatalf("%s %s returned %v. Body: %s", method, path, recorder.Code, recorder.Body.String())
+		}
+		return recorder
+	}
+	next := func() received {
+		t.Helper()
+		select {
+		case d := <-deliveries:
+			return d
+		case <-time.After(5 * time.Second):
+			t.Fatal("receiver got no delivery in time")
+			return received{}
+		}
+	}
+
+	var webhook types.WebhookResponse
+	json.Unmarshal(serve(http.MethodPost, "/webhooks", types.WebhookRequest{
+		URL:    receiver.URL,
+		Events: []string{EventJobCompleted, EventCalendarChanged},
+		Secret: "s3cret",
+	}).Body.Bytes(), &webhook)
+
+	var calendar types.CalendarResponse
+	json.Unmarshal(serve(http.MethodPost, "/calendars", []types.BookingRequest{
+		{RequestID: "B1", Checkin: "2024-01-01", Nights: 4, SellingRate: 100, Margin: 20},
+	}).Body.Bytes(), &calendar)
+	inquiry := types.BookingRequest{RequestID: "B2", Checkin: "2024-01-06", Nights: 2, SellingRate: 100, Margin: 25}
+	serve(http.MethodPost, "/calendars/"+calendar.CalendarID+"/bookings?dry_run=true", inquiry)
+	serve(http.MethodPost, "/calendars/"+calendar.CalendarID+"/bookings", inquiry)
+
+	// The dry run must not have produced an event, so this is the real add
+	changed := next()
+	if changed.event.Type != EventCalendarChanged || !strings.HasPrefix(changed.signature, "t=") {
+		t.Errorf("first delivery = %+v, want a signed %s", changed, EventCalendarChanged)
+	}
+	if data, want := string(changed.event.Data), `{"calendar_id":"`+calendar.CalendarID+`","action":"booking_added","decision":{"request_id":"B2","accepted":true`; !strings.HasPrefix(data, want) {
+		t.Errorf("calendar event data = %s, want prefix %s", data, want)
+	}
+
+	serve(http.MethodPost, "/jobs/maximize", []types.BookingRequest{
+		{RequestID: "B1", Checkin: "2024-01-01", Nights: 4, SellingRate: 100, Margin: 10},
+	})
+	completed := next()
+	if completed.event.Type != EventJobCompleted || !strings.Contains(string(completed.event.Data), `"status":"succeeded"`) {
+		t.Errorf("second delivery = %s %s, want a succeeded %s", completed.event.Type, completed.event.Data, EventJobCompleted)
+	}
+
+	// Both deliveries show up in the log once recorded
+	deadline := time.Now().Add(5 * time.Second)
+	var logged []types.WebhookDeliveryResponse
+	for len(logged) < 2 && time.Now().Before(deadline) {
+		json.Unmarshal(serve(http.MethodGet, "/webhooks/"+webhook.WebhookID+"/deliveries", nil).Body.Bytes(), &logged)
+		time.Sleep(time.Millisecond)
+	}
+	if len(logged) != 2 || logged[0].Event != EventJobCompleted || !logged[0].Succeeded || logged[0].StatusCode != http.StatusOK {
+		t.Errorf("delivery log = %+v, want the succeeded job delivery first of two", logged)
+	}
+}
@@ -10,13 +10,14 @@ import (
 const DateLayout = "2006-01-02"
 
 type Booking struct {
-	RequestID   string    
-	Checkin     time.Time 
-	Nights      int
-	SellingRate float64
-	Margin      float64
-	Checkout 	time.Time
-	Profit   	float64
+	RequestID         string
+	Checkin           time.Time
+	Nights            int
+	SellingRate       float64
+	Margin            float64
+	CancelProbability float64
+	Checkout          time.Time
+	Profit            float64
 }
 
 func CalculateCheckout(checkin time.Time, nights int) time.Time {
@@ -0,0 +1,180 @@
+package booking
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+// Calendar is a stored set of bookings that keeps the FindMaxProfit DP
+// tables around between changes. Bookings are kept sorted by checkout, and
+// a change at sorted position k only recomputes the predecessor and DP
+// entries from k onward, so late arrivals (the common case) are cheap.
+//
+// A Calendar is not safe for concurrent use.
+type Calendar struct {
+	bookings                     []Booking
+	latestCompatiblePredecessors []int
+	dp                           []float64
+}
+
+// CalendarChange describes the effect of adding, updating or cancelling a
+// booking on the optimal schedule.
+type CalendarChange struct {
+	Booking   Booking
+	Accepted  bool      // Booking is part of the new optimal schedule
+	Displaced []Booking // Bookings that dropped out of the optimal schedule
+	Schedule  ScheduleResult
+}
+
+func NewCalendar(bookings []Booking) (*Calendar, error) {
+	// Without a deadline the computation cannot be interrupted
+	return NewCalendarContext(context.Background(), bookings)
+}
+
+// NewCalendarContext is NewCalendar that gives up once ctx is done,
+// returning the context's cause like FindMaxProfitContext. Later changes
+// are incremental and always run to completion, since abandoning one would
+// leave the stored DP tables half updated.
+func NewCalendarContext(ctx context.Context, bookings []Booking) (*Calendar, error) {
+	if err := CheckUniqueRequestIDs(bookings); err != nil {
+		return nil, err
+	}
+	calendar := &Calendar{
+		bookings:                     prepareBookings(bookings),
+		latestCompatiblePredecessors: make([]int, len(bookings)),
+		dp:                           make([]float64, len(bookings)),
+	}
+	if err := sortByCheckoutContext(ctx, calendar.bookings); err != nil {
+		return nil, err
+	}
+	if err := updatePredecessors(ctx, calendar.bookings, calendar.latestCompatiblePredecessors, 0); err != nil {
+		return nil, err
+	}
+	if err := updateDP(ctx, calendar.bookings, calendar.latestCompatiblePredecessors, calendar.dp, 0); err != nil {
+		return nil, err
+	}
+	return calendar, nil
+}
+
+// Len returns the number of bookings stored in the calendar.
+func (c *Calendar) Len() int {
+	return len(c.bookings)
+}
+
+// Bookings returns a copy of the stored bookings ordered by checkout.
+func (c *Calendar) Bookings() []Booking {
+	return slices.Clone(c.bookings)
+}
+
+// Schedule returns the current optimal schedule, identical to running
+// FindMaxProfit over Bookings.
+func (c *Calendar) Schedule() ScheduleResult {
+	result := ScheduleResult{
+		OptimalSchedule: reconstructSchedule(c.bookings, c.latestCompatiblePredecessors, c.dp),
+	}
+	calculateScheduleStats(&result)
+	return result
+}
+
+// Add stores a new booking and reports whether it made it into the optimum.
+func (c *Calendar) Add(b Booking) (CalendarChange, error) {
+	if c.indexOf(b.RequestID) != -1 {
+		return CalendarChange{}, fmt.Errorf("%w: %s", ErrDuplicateRequestID, b.RequestID)
+	}
+	before := c.Schedule()
+	c.recompute(c.insert(b))
+	return c.changeFrom(before, b.RequestID), nil
+}
+
+// Inquire answers whether b would be accepted without storing it.
+func (c *Calendar) Inquire(b Booking) (CalendarChange, error) {
+	if c.indexOf(b.RequestID) != -1 {
+		return CalendarChange{}, fmt.Errorf("%w: %s", ErrDuplicateRequestID, b.RequestID)
+	}
+	before := c.Schedule()
+	k := c.insert(b)
+	c.recompute(k)
+	change := c.changeFrom(before, b.RequestID)
+	c.remove(k)
+	c.recompute(k)
+	return change, nil
+}
+
+// Update replaces the stored booking with the same request ID.
+func (c *Calendar) Update(b Booking) (CalendarChange, error) {
+	k := c.indexOf(b.RequestID)
+	if k == -1 {
+		return CalendarChange{}, fmt.Errorf("%w: %s", ErrUnknownRequestID, b.RequestID)
+	}
+	before := c.Schedule()
+	c.remove(k)
+	c.recompute(min(k, c.insert(b)))
+	return c.changeFrom(before, b.RequestID), nil
+}
+
+// Cancel removes the booking with the given request ID.
+func (c *Calendar) Cancel(requestID string) (CalendarChange, error) {
+	k := c.indexOf(requestID)
+	if k == -1 {
+		return CalendarChange{}, fmt.Errorf("%w: %s", ErrUnknownRequestID, requestID)
+	}
+	before := c.Schedule()
+	cancelled := c.remove(k)
+	c.recompute(k)
+	change := c.changeFrom(before, requestID)
+	change.Booking = cancelled
+	return change, nil
+}
+
+func (c *Calendar) indexOf(requestID string) int {
+	return slices.IndexFunc(c.bookings, func(b Booking) bool {
+		return b.RequestID == requestID
+	})
+}
+
+// insert places b at its sorted position without touching the DP tables
+// and returns that position.
+func (c *Calendar) insert(b Booking) int {
+	prepared := prepareBookings([]Booking{b})[0]
+	k, _ := slices.BinarySearchFunc(c.bookings, prepared, compareByCheckout)
+	c.bookings = slices.Insert(c.bookings, k, prepared)
+	c.latestCompatiblePredecessors = slices.Insert(c.latestCompatiblePredecessors, k, -1)
+	c.dp = slices.Insert(c.dp, k, 0)
+	return k
+}
+
+// remove deletes the booking at sorted position k without touching the DP
+// tables and returns it.
+func (c *Calendar) remove(k int) Booking {
+	removed := c.bookings[k]
+	c.bookings = slices.Delete(c.bookings, k, k+1)
+	c.latestCompatiblePredecessors = slices.Delete(c.latestCompatiblePredecessors, k, k+1)
+	c.dp = slices.Delete(c.dp, k, k+1)
+	return removed
+}
+
+// recompute refreshes the DP tables from sorted position "from" onward.
+// It is incremental and cheap, so it is not cancellable.
+func (c *Calendar) recompute(from int) {
+	updatePredecessors(context.Background(), c.bookings, c.latestCompatiblePredecessors, from)
+	updateDP(context.Background(), c.bookings, c.latestCompatiblePredecessors, c.dp, from)
+}
+
+func (c *Calendar) changeFrom(before ScheduleResult, requestID string) CalendarChange {
+	after := c.Schedule()
+	change := CalendarChange{Schedule: after}
+	if k := c.indexOf(requestID); k != -1 {
+		change.Booking = c.bookings[k]
+	}
+	for _, b := range after.OptimalSchedule {
+		if b.RequestID == requestID {
+			change.Accepted = true
+			break
+		}
+	}
+	change.Displaced = slices.DeleteFunc(bookingsMissingFrom(before.OptimalSchedule, after.OptimalSchedule), func(b Booking) bool {
+		return b.RequestID == requestID
+	})
+	return change
+}
@@ -0,0 +1,152 @@
+package booking
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func newTestCalendar(t *testing.T, bookings ...Booking) *Calendar {
+	t.Helper()
+	calendar, err := NewCalendar(bookings)
+	if err != nil {
+		t.Fatalf("NewCalendar() unexpected error: %v", err)
+	}
+	return calendar
+}
+
+func TestCalendarAdd(t *testing.T) {
+	calendar := newTestCalendar(t,
+		newTestBooking(t, "B1", "2024-01-01", 4, 100, 20),
+		newTestBooking(t, "B3", "2024-01-06", 2, 100, 25),
+	)
+
+	t.Run("Declined when it does not pay off", func(t *testing.T) {
+		change, err := calendar.Add(newTestBooking(t, "B2", "2024-01-03", 5, 100, 30))
+		if err != nil {
+			t.Fatalf("Add() unexpected error: %v", err)
+		}
+		if change.Accepted {
+			t.Errorf("Add() accepted a booking worth less than the ones it overlaps")
+		}
+		if len(change.Displaced) != 0 {
+			t.Errorf("Displaced = %v, want none", bookingIDs(change.Displaced))
+		}
+	})
+
+	t.Run("Accepted and displaces overlapping bookings", func(t *testing.T) {
+		change, err := calendar.Add(newTestBooking(t, "B4", "2024-01-02", 6, 100, 60))
+		if err != nil {
+			t.Fatalf("Add() unexpected error: %v", err)
+		}
+		if !change.Accepted {
+			t.Errorf("Add() declined a booking worth more than the ones it overlaps")
+		}
+		if !reflect.DeepEqual(bookingIDs(change.Displaced), []string{"B1", "B3"}) {
+			t.Errorf("Displaced = %v, want [B1 B3]", bookingIDs(change.Displaced))
+		}
+		assertFloatEquals(t, 60.0, change.Schedule.TotalProfit, 1e-9, "TotalProfit mismatch")
+	})
+
+	t.Run("Duplicate request ID", func(t *testing.T) {
+		_, err := calendar.Add(newTestBooking(t, "B1", "2024-02-01", 1, 100, 10))
+		if !errors.Is(err, ErrDuplicateRequestID) {
+			t.Errorf("Add() error = %v, want %v", err, ErrDuplicateRequestID)
+		}
+	})
+}
+
+func TestCalendarInquireDoesNotStore(t *testing.T) {
+	calendar := newTestCalendar(t, newTestBooking(t, "B1", "2024-01-01", 4, 100, 20))
+
+	change, err := calendar.Inquire(newTestBooking(t, "B2", "2024-01-05", 2, 100, 10))
+	if err != nil {
+		t.Fatalf("Inquire() unexpected error: %v", err)
+	}
+	if !change.Accepted {
+		t.Errorf("Inquire() declined a booking that fits in an empty slot")
+	}
+	if calendar.Len() != 1 {
+		t.Errorf("Len() = %d after Inquire(), want 1", calendar.Len())
+	}
+	assertFloatEquals(t, 20.0, calendar.Schedule().TotalProfit, 1e-9, "TotalProfit mismatch")
+}
+
+func TestCalendarUpdateAndCancel(t *testing.T) {
+	calendar := newTestCalendar(t,
+		newTestBooking(t, "B1", "2024-01-01", 4, 100, 20),
+		newTestBooking(t, "B2", "2024-01-03", 5, 100, 30),
+		newTestBooking(t, "B3", "2024-01-06", 2, 100, 25),
+	)
+
+	change, err := calendar.Update(newTestBooking(t, "B2", "2024-01-03", 5, 100, 90))
+	if err != nil {
+		t.Fatalf("Update() unexpected error: %v", err)
+	}
+	if !change.Accepted || !reflect.DeepEqual(bookingIDs(change.Displaced), []string{"B1", "B3"}) {
+		t.Errorf("Update() accepted = %v displaced = %v, want true [B1 B3]", change.Accepted, bookingIDs(change.Displaced))
+	}
+
+	change, err = calendar.Cancel("B2")
+	if err != nil {
+		t.Fatalf("Cancel() unexpected error: %v", err)
+	}
+	if change.Accepted || change.Booking.RequestID != "B2" {
+		t.Errorf("Cancel() = %+v, want the cancelled B2 not accepted", change)
+	}
+	if !reflect.DeepEqual(bookingIDs(change.Schedule.OptimalSchedule), []string{"B1", "B3"}) {
+		t.Errorf("Schedule after Cancel() = %v, want [B1 B3]", bookingIDs(change.Schedule.OptimalSchedule))
+	}
+
+	if _, err := calendar.Cancel("B2"); !errors.Is(err, ErrUnknownRequestID) {
+		t.Errorf("Cancel() error = %v, want %v", err, ErrUnknownRequestID)
+	}
+	if _, err := calendar.Update(newTestBooking(t, "B9", "2024-01-03", 5, 100, 90)); !errors.Is(err, ErrUnknownRequestID) {
+		t.Errorf("Update() error = %v, want %v", err, ErrUnknownRequestID)
+	}
+}
+
+func TestCalendarMatchesFindMaxProfit(t *testing.T) {
+	rng := rand.New(rand.NewSource(42))
+	start := parseTestDate(t, "2024-01-01")
+	randomBooking := func(id string) Booking {
+		return Booking{
+			RequestID:   id,
+			Checkin:     start.AddDate(0, 0, rng.Intn(60)),
+			Nights:      1 + rng.Intn(7),
+			SellingRate: float64(50 + rng.Intn(500)),
+			Margin:      float64(5 + rng.Intn(40)),
+		}
+	}
+
+	calendar := newTestCalendar(t)
+	ids := []string{}
+	for step := range 300 {
+		switch op := rng.Intn(4); {
+		case op < 2 || len(ids) == 0:
+			id := fmt.Sprintf("R%d", step)
+			if _, err := calendar.Add(randomBooking(id)); err != nil {
+				t.Fatalf("Add() unexpected error: %v", err)
+			}
+			ids = append(ids, id)
+		case op == 2:
+			id := ids[rng.Intn(len(ids))]
+			if _, err := calendar.Update(randomBooking(id)); err != nil {
+				t.Fatalf("Update() unexpected error: %v", err)
+			}
+		default:
+			k := rng.Intn(len(ids))
+			if _, err := calendar.Cancel(ids[k]); err != nil {
+				t.Fatalf("Cancel() unexpected error: %v", err)
+			}
+			ids = append(ids[:k], ids[k+1:]...)
+		}
+
+		assertScheduleResult(t, FindMaxProfit(calendar.Bookings()), calendar.Schedule())
+		if t.Failed() {
+			t.Fatalf("Calendar diverged from FindMaxProfit at step %d", step)
+		}
+	}
+}
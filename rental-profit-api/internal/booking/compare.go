@@ -0,0 +1,113 @@
+package booking
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrUnknownRequestID    = errors.New("unknown request_id")
+	ErrDuplicateRequestID  = errors.New("duplicate request_id")
+	ErrOverlappingBookings = errors.New("overlapping bookings")
+)
+
+// ScheduleComparison describes how the Right schedule differs from the Left
+// one. Deltas are always Right minus Left.
+type ScheduleComparison struct {
+	Left           ScheduleResult
+	Right          ScheduleResult
+	Added          []Booking // In Right but not in Left
+	Removed        []Booking // In Left but not in Right
+	ProfitDelta    float64
+	OccupancyDelta int
+}
+
+// CheckUniqueRequestIDs reports the first request ID used by more than one
+// booking. Comparing schedules by ID is meaningless without it.
+func CheckUniqueRequestIDs(bookings []Booking) error {
+	seen := make(map[string]bool, len(bookings))
+	for _, b := range bookings {
+		if seen[b.RequestID] {
+			return fmt.Errorf("%w: %s", ErrDuplicateRequestID, b.RequestID)
+		}
+		seen[b.RequestID] = true
+	}
+	return nil
+}
+
+// SelectBookings picks the bookings named by requestIDs, in the order given.
+// Request IDs must be unique both in bookings and in requestIDs.
+func SelectBookings(bookings []Booking, requestIDs []string) ([]Booking, error) {
+	if err := CheckUniqueRequestIDs(bookings); err != nil {
+		return nil, err
+	}
+	byID := make(map[string]Booking, len(bookings))
+	for _, b := range bookings {
+		byID[b.RequestID] = b
+	}
+
+	selected := make([]Booking, 0, len(requestIDs))
+	seen := make(map[string]bool, len(requestIDs))
+	for _, id := range requestIDs {
+		b, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownRequestID, id)
+		}
+		if seen[id] {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateRequestID, id)
+		}
+		seen[id] = true
+		selected = append(selected, b)
+	}
+	return selected, nil
+}
+
+// EvaluateSchedule computes the profit and per-night stats of a hand-picked
+// set of bookings, returning them ordered by checkout the same way
+// FindMaxProfit does. The set must not contain overlapping stays.
+func EvaluateSchedule(selected []Booking) (ScheduleResult, error) {
+	validation := ValidateSchedule(selected)
+	if !validation.Feasible() {
+		overlap := validation.Overlaps[0]
+		return ScheduleResult{}, fmt.Errorf("%w: %s and %s", ErrOverlappingBookings, overlap.First.RequestID, overlap.Second.RequestID)
+	}
+	return validation.Schedule, nil
+}
+
+// OccupiedNights returns the number of nights covered by a schedule.
+func OccupiedNights(schedule []Booking) int {
+	nights := 0
+	for _, b := range schedule {
+		nights += b.Nights
+	}
+	return nights
+}
+
+// CompareSchedules diffs two schedules built from the same booking payload.
+func CompareSchedules(left, right ScheduleResult) ScheduleComparison {
+	comparison := ScheduleComparison{
+		Left:           left,
+		Right:          right,
+		Added:          bookingsMissingFrom(right.OptimalSchedule, left.OptimalSchedule),
+		Removed:        bookingsMissingFrom(left.OptimalSchedule, right.OptimalSchedule),
+		ProfitDelta:    right.TotalProfit - left.TotalProfit,
+		OccupancyDelta: OccupiedNights(right.OptimalSchedule) - OccupiedNights(left.OptimalSchedule),
+	}
+	return comparison
+}
+
+// bookingsMissingFrom returns the bookings of from whose request ID does
+// not appear in other, preserving the order of from.
+func bookingsMissingFrom(from, other []Booking) []Booking {
+	present := make(map[string]bool, len(other))
+	for _, b := range other {
+		present[b.RequestID] = true
+	}
+	missing := []Booking{}
+	for _, b := range from {
+		if !present[b.RequestID] {
+			missing = append(missing, b)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,114 @@
+package booking
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func bookingIDs(bookings []Booking) []string {
+	ids := make([]string, len(bookings))
+	for i, b := range bookings {
+		ids[i] = b.RequestID
+	}
+	return ids
+}
+
+func TestSelectBookings(t *testing.T) {
+	bookings := []Booking{
+		newTestBooking(t, "B1", "2024-01-01", 4, 100, 10),
+		newTestBooking(t, "B2", "2024-01-06", 2, 150, 20),
+	}
+
+	testCases := []struct {
+		name       string
+		bookings   []Booking
+		requestIDs []string
+		wantIDs    []string
+		wantErr    error
+	}{
+		{"Keeps requested order", bookings, []string{"B2", "B1"}, []string{"B2", "B1"}, nil},
+		{"Empty selection", bookings, []string{}, []string{}, nil},
+		{"Unknown ID", bookings, []string{"B9"}, nil, ErrUnknownRequestID},
+		{"Repeated ID in selection", bookings, []string{"B1", "B1"}, nil, ErrDuplicateRequestID},
+		{"Repeated ID in payload", append([]Booking{bookings[0]}, bookings...), []string{"B1"}, nil, ErrDuplicateRequestID},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SelectBookings(tt.bookings, tt.requestIDs)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SelectBookings() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && !reflect.DeepEqual(bookingIDs(got), tt.wantIDs) {
+				t.Errorf("SelectBookings() = %v, want %v", bookingIDs(got), tt.wantIDs)
+			}
+		})
+	}
+}
+
+func TestEvaluateSchedule(t *testing.T) {
+	t.Run("Sorts and computes stats", func(t *testing.T) {
+		selected := []Booking{
+			{RequestID: "B2", Checkin: parseTestDate(t, "2024-01-06"), Nights: 2, SellingRate: 150, Margin: 20},
+			{RequestID: "B1", Checkin: parseTestDate(t, "2024-01-01"), Nights: 4, SellingRate: 100, Margin: 10},
+		}
+		got, err := EvaluateSchedule(selected)
+		if err != nil {
+			t.Fatalf("EvaluateSchedule() unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(bookingIDs(got.OptimalSchedule), []string{"B1", "B2"}) {
+			t.Errorf("EvaluateSchedule() order = %v, want [B1 B2]", bookingIDs(got.OptimalSchedule))
+		}
+		assertFloatEquals(t, 40.0, got.TotalProfit, 1e-9, "TotalProfit mismatch")
+		assertFloatEquals(t, 8.75, got.AvgProfitPerNight, 1e-9, "AvgProfitPerNight mismatch")
+		assertFloatEquals(t, 2.5, got.MinProfitPerNight, 1e-9, "MinProfitPerNight mismatch")
+		assertFloatEquals(t, 15.0, got.MaxProfitPerNight, 1e-9, "MaxProfitPerNight mismatch")
+	})
+
+	t.Run("Rejects overlaps", func(t *testing.T) {
+		selected := []Booking{
+			newTestBooking(t, "B1", "2024-01-01", 5, 100, 10),
+			newTestBooking(t, "B2", "2024-01-04", 4, 150, 20),
+		}
+		if _, err := EvaluateSchedule(selected); !errors.Is(err, ErrOverlappingBookings) {
+			t.Errorf("EvaluateSchedule() error = %v, want %v", err, ErrOverlappingBookings)
+		}
+	})
+
+	t.Run("Back-to-back stays are compatible", func(t *testing.T) {
+		selected := []Booking{
+			newTestBooking(t, "B1", "2024-01-01", 4, 100, 10),
+			newTestBooking(t, "B2", "2024-01-05", 1, 100, 10),
+		}
+		if _, err := EvaluateSchedule(selected); err != nil {
+			t.Errorf("EvaluateSchedule() unexpected error: %v", err)
+		}
+	})
+}
+
+func TestCompareSchedules(t *testing.T) {
+	bookings := []Booking{
+		newTestBooking(t, "B1", "2024-01-01", 4, 100, 20),
+		newTestBooking(t, "B2", "2024-01-03", 5, 100, 30),
+		newTestBooking(t, "B3", "2024-01-06", 2, 100, 25),
+	}
+	manual, err := EvaluateSchedule([]Booking{bookings[1]})
+	if err != nil {
+		t.Fatalf("EvaluateSchedule() unexpected error: %v", err)
+	}
+	optimal := FindMaxProfit(bookings)
+
+	got := CompareSchedules(manual, optimal)
+
+	if !reflect.DeepEqual(bookingIDs(got.Added), []string{"B1", "B3"}) {
+		t.Errorf("Added = %v, want [B1 B3]", bookingIDs(got.Added))
+	}
+	if !reflect.DeepEqual(bookingIDs(got.Removed), []string{"B2"}) {
+		t.Errorf("Removed = %v, want [B2]", bookingIDs(got.Removed))
+	}
+	assertFloatEquals(t, 15.0, got.ProfitDelta, 1e-9, "ProfitDelta mismatch")
+	if got.OccupancyDelta != 1 {
+		t.Errorf("OccupancyDelta = %d, want 1", got.OccupancyDelta)
+	}
+}
@@ -0,0 +1,152 @@
+package booking
+
+import (
+	"context"
+	"math"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// MaxOverbookingPartners bounds the bookings FindMaxExpectedProfit pairs
+// each booking with: only the ones checking in next are considered. Pairing
+// every overlapping pair would take quadratic time and memory when many
+// stays share the same dates.
+const MaxOverbookingPartners = 32
+
+// OverbookingPolicy bounds how aggressively FindMaxExpectedProfit may
+// accept overlapping bookings.
+type OverbookingPolicy struct {
+	MaxWalkProbability float64 // Highest accepted chance that both guests of an overbooked pair show up
+	WalkPenalty        float64 // Cost of relocating one walked guest
+}
+
+// ExpectedScheduleResult is a schedule chosen for expected rather than
+// nominal profit. Overbooked lists the accepted pairs that overlap.
+type ExpectedScheduleResult struct {
+	Schedule       []Booking
+	Overbooked     []Overlap
+	ExpectedProfit float64
+	ExpectedWalks  float64
+}
+
+// FindMaxExpectedProfit maximizes expected profit when guests may cancel.
+//
+// The schedule is built from non-overlapping units, each either a single
+// booking or a pair of overlapping bookings whose walk probability (both
+// guests showing up) is within the policy limit. When both guests of a pair
+// show up the more profitable one is hosted and the other is walked at the
+// policy penalty. Each booking is only paired with the next
+// MaxOverbookingPartners bookings to check in, and a MaxWalkProbability of 0
+// pairs none, even guests certain to cancel. Units are then picked with the
+// same weighted interval scheduling DP as FindMaxProfit, so with no
+// cancellation probabilities and no overbooking allowed it returns the
+// FindMaxProfit schedule.
+func FindMaxExpectedProfit(inputBookings []Booking, policy OverbookingPolicy) ExpectedScheduleResult {
+	// Without a deadline the computation cannot be interrupted
+	result, _ := FindMaxExpectedProfitContext(context.Background(), inputBookings, policy)
+	return result
+}
+
+// FindMaxExpectedProfitContext is FindMaxExpectedProfit that gives up once
+// ctx is done, returning the context's cause like FindMaxProfitContext.
+func FindMaxExpectedProfitContext(ctx context.Context, inputBookings []Booking, policy OverbookingPolicy) (ExpectedScheduleResult, error) {
+	result := ExpectedScheduleResult{
+		Schedule:   []Booking{},
+		Overbooked: []Overlap{},
+	}
+	if len(inputBookings) == 0 {
+		return result, nil
+	}
+
+	// 1.- Build the candidate units. Each unit is a synthetic booking spanning
+	// its members, whose RequestID indexes the members slice.
+	bookings := prepareBookings(inputBookings)
+	units := make([]Booking, 0, len(bookings))
+	members := make([][]Booking, 0, len(bookings))
+	addUnit := func(checkin, checkout time.Time, expectedProfit float64, unitMembers ...Booking) {
+		units = append(units, Booking{
+			RequestID: strconv.Itoa(len(members)),
+			Checkin:   checkin,
+			Checkout:  checkout,
+			Profit:    expectedProfit,
+		})
+		members = append(members, unitMembers)
+	}
+
+	for _, b := range bookings {
+		addUnit(b.Checkin, b.Checkout, showProbability(b)*b.Profit, b)
+	}
+	var byCheckin []Booking
+	if policy.MaxWalkProbability > 0 {
+		byCheckin = slices.Clone(bookings)
+		slices.SortStableFunc(byCheckin, func(a, b Booking) int {
+			return a.Checkin.Compare(b.Checkin)
+		})
+	}
+	for i, first := range byCheckin {
+		if i%cancelCheckInterval == 0 && ctx.Err() != nil {
+			return result, context.Cause(ctx)
+		}
+		partners := byCheckin[i+1 : min(i+1+MaxOverbookingPartners, len(byCheckin))]
+		for _, second := range partners {
+			if isCompatible(first, second) {
+				break
+			}
+			if WalkProbability(first, second) > policy.MaxWalkProbability {
+				continue
+			}
+			checkout := first.Checkout
+			if second.Checkout.After(checkout) {
+				checkout = second.Checkout
+			}
+			addUnit(first.Checkin, checkout, pairExpectedProfit(first, second, policy.WalkPenalty), first, second)
+		}
+	}
+
+	// 2.- Pick the best set of non-overlapping units
+	if err := sortByCheckoutContext(ctx, units); err != nil {
+		return result, err
+	}
+	latestCompatiblePredecessors := make([]int, len(units))
+	if err := updatePredecessors(ctx, units, latestCompatiblePredecessors, 0); err != nil {
+		return result, err
+	}
+	dp := make([]float64, len(units))
+	if err := updateDP(ctx, units, latestCompatiblePredecessors, dp, 0); err != nil {
+		return result, err
+	}
+
+	// 3.- Expand the chosen units back into bookings
+	for _, unit := range reconstructSchedule(units, latestCompatiblePredecessors, dp) {
+		index, _ := strconv.Atoi(unit.RequestID)
+		unitMembers := members[index]
+		result.Schedule = append(result.Schedule, unitMembers...)
+		result.ExpectedProfit += unit.Profit
+		if len(unitMembers) == 2 {
+			result.Overbooked = append(result.Overbooked, Overlap{First: unitMembers[0], Second: unitMembers[1]})
+			result.ExpectedWalks += WalkProbability(unitMembers[0], unitMembers[1])
+		}
+	}
+	sortByCheckout(result.Schedule)
+
+	return result, nil
+}
+
+func showProbability(b Booking) float64 {
+	return 1 - b.CancelProbability
+}
+
+// WalkProbability is the chance that both guests of an overbooked pair show
+// up, forcing one of them to be walked.
+func WalkProbability(a, b Booking) float64 {
+	return showProbability(a) * showProbability(b)
+}
+
+func pairExpectedProfit(a, b Booking, walkPenalty float64) float64 {
+	pA, pB := showProbability(a), showProbability(b)
+	onlyA := pA * (1 - pB) * a.Profit
+	onlyB := pB * (1 - pA) * b.Profit
+	both := pA * pB * (math.Max(a.Profit, b.Profit) - walkPenalty)
+	return onlyA + onlyB + both
+}
@@ -0,0 +1,156 @@
+package booking
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func withCancelProbability(b Booking, cancelProbability float64) Booking {
+	b.CancelProbability = cancelProbability
+	return b
+}
+
+func TestFindMaxExpectedProfitWithoutRisk(t *testing.T) {
+	bookings := []Booking{
+		newTestBooking(t, "B1", "2024-01-01", 4, 100, 20),
+		newTestBooking(t, "B2", "2024-01-03", 5, 100, 30),
+		newTestBooking(t, "B3", "2024-01-06", 2, 100, 25),
+	}
+
+	got := FindMaxExpectedProfit(bookings, OverbookingPolicy{})
+	want := FindMaxProfit(bookings)
+
+	if !reflect.DeepEqual(bookingIDs(got.Schedule), bookingIDs(want.OptimalSchedule)) {
+		t.Errorf("Schedule = %v, want the FindMaxProfit schedule %v", bookingIDs(got.Schedule), bookingIDs(want.OptimalSchedule))
+	}
+	assertFloatEquals(t, want.TotalProfit, got.ExpectedProfit, 1e-9, "ExpectedProfit mismatch")
+	if len(got.Overbooked) != 0 || got.ExpectedWalks != 0 {
+		t.Errorf("Overbooked = %v, ExpectedWalks = %v, want none", got.Overbooked, got.ExpectedWalks)
+	}
+}
+
+func TestFindMaxExpectedProfit(t *testing.T) {
+	likelyCancel := withCancelProbability(newTestBooking(t, "A", "2024-01-01", 4, 100, 20), 0.9)
+	certain := newTestBooking(t, "B", "2024-01-03", 2, 100, 15)
+	coinFlipA := withCancelProbability(newTestBooking(t, "A", "2024-01-01", 4, 1000, 10), 0.5)
+	coinFlipB := withCancelProbability(newTestBooking(t, "B", "2024-01-03", 4, 1000, 10), 0.5)
+	certainCancel := withCancelProbability(newTestBooking(t, "A", "2024-01-01", 4, 100, 20), 1)
+
+	testCases := []struct {
+		name           string
+		bookings       []Booking
+		policy         OverbookingPolicy
+		wantIDs        []string
+		wantProfit     float64
+		wantWalks      float64
+		wantOverbooked int
+	}{
+		{
+			name:       "Likely cancellation loses to a certain stay",
+			bookings:   []Booking{likelyCancel, certain},
+			wantIDs:    []string{"B"},
+			wantProfit: 15,
+		},
+		{
+			name:           "Overbooks within the risk limit",
+			bookings:       []Booking{coinFlipA, coinFlipB},
+			policy:         OverbookingPolicy{MaxWalkProbability: 0.3, WalkPenalty: 20},
+			wantIDs:        []string{"A", "B"},
+			wantProfit:     70, // 0.25*100 + 0.25*100 + 0.25*(100-20)
+			wantWalks:      0.25,
+			wantOverbooked: 1,
+		},
+		{
+			name:       "Respects the risk limit",
+			bookings:   []Booking{coinFlipA, coinFlipB},
+			policy:     OverbookingPolicy{MaxWalkProbability: 0.2, WalkPenalty: 20},
+			wantIDs:    []string{"A"},
+			wantProfit: 50,
+		},
+		{
+			name:       "No overbooking at a zero risk limit",
+			bookings:   []Booking{certainCancel, certain},
+			wantIDs:    []string{"B"},
+			wantProfit: 15,
+		},
+		{
+			name:       "Penalty makes overbooking unattractive",
+			bookings:   []Booking{coinFlipA, coinFlipB},
+			policy:     OverbookingPolicy{MaxWalkProbability: 0.3, WalkPenalty: 200},
+			wantIDs:    []string{"A"},
+			wantProfit: 50,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FindMaxExpectedProfit(tt.bookings, tt.policy)
+
+			if !reflect.DeepEqual(bookingIDs(got.Schedule), tt.wantIDs) {
+				t.Errorf("Schedule = %v, want %v", bookingIDs(got.Schedule), tt.wantIDs)
+			}
+			assertFloatEquals(t, tt.wantProfit, got.ExpectedProfit, 1e-9, "ExpectedProfit mismatch")
+			assertFloatEquals(t, tt.wantWalks, got.ExpectedWalks, 1e-9, "ExpectedWalks mismatch")
+			if len(got.Overbooked) != tt.wantOverbooked {
+				t.Errorf("Overbooked = %d pairs, want %d", len(got.Overbooked), tt.wantOverbooked)
+			}
+		})
+	}
+}
+
+func TestFindMaxExpectedProfitPartnerWindow(t *testing.T) {
+	// A and B would be overbooked (expected profit 70), but the certain stays
+	// checking in between them fill A's partner window
+	bookings := []Booking{withCancelProbability(newTestBooking(t, "A", "2024-01-01", 4, 1000, 10), 0.5)}
+	for i := 0; i < MaxOverbookingPartners; i++ {
+		bookings = append(bookings, newTestBooking(t, fmt.Sprintf("F%d", i), "2024-01-02", 1, 1, 10))
+	}
+	bookings = append(bookings, withCancelProbability(newTestBooking(t, "B", "2024-01-03", 4, 1000, 10), 0.5))
+
+	got := FindMaxExpectedProfit(bookings, OverbookingPolicy{MaxWalkProbability: 0.3, WalkPenalty: 20})
+
+	if len(got.Overbooked) != 0 {
+		t.Errorf("Overbooked = %v, want none beyond the partner window", got.Overbooked)
+	}
+	assertFloatEquals(t, 50.1, got.ExpectedProfit, 1e-9, "ExpectedProfit mismatch")
+}
+
+func TestContextVariantsAbort(t *testing.T) {
+	bookings := manyTestBookings(5000)
+	stay := Booking{RequestID: "Q", Checkin: bookings[0].Checkin, Nights: 2, Margin: 10}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	testCases := []struct {
+		name string
+		run  func() error
+	}{
+		{"FindMaxExpectedProfitContext", func() error {
+			_, err := FindMaxExpectedProfitContext(ctx, bookings, OverbookingPolicy{MaxWalkProbability: 1})
+			return err
+		}},
+		{"QuoteStayContext", func() error {
+			_, err := QuoteStayContext(ctx, bookings, stay, 0)
+			return err
+		}},
+		{"ValidateScheduleContext", func() error {
+			_, err := ValidateScheduleContext(ctx, bookings)
+			return err
+		}},
+		{"NewCalendarContext", func() error {
+			_, err := NewCalendarContext(ctx, bookings)
+			return err
+		}},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.run(); !errors.Is(err, context.Canceled) {
+				t.Errorf("%s() error = %v, want %v", tt.name, err, context.Canceled)
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package booking
+
+import "context"
+
+// ProgressFunc receives how many of the total steps of a long computation
+// have completed.
+type ProgressFunc func(done, total int)
+
+type progressKey struct{}
+
+// WithProgress returns a context that makes the context-aware computations
+// in this package report their progress to fn. fn must be quick and safe
+// for concurrent use.
+func WithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+func reportProgress(ctx context.Context, done, total int) {
+	if fn, ok := ctx.Value(progressKey{}).(ProgressFunc); ok {
+		fn(done, total)
+	}
+}
@@ -0,0 +1,117 @@
+package booking
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+)
+
+// ErrNoAcceptableRate means the stay would only be accepted above the
+// highest selling rate allowed.
+var ErrNoAcceptableRate = errors.New("no acceptable selling rate")
+
+// Quote is the cheapest price at which a prospective stay makes it into the
+// optimal schedule, and what accepting it at that price would cost.
+type Quote struct {
+	MinSellingRate float64 // Smallest whole-cent rate at which the stay is accepted
+	Displaced      []Booking
+	Schedule       ScheduleResult // Optimum once the stay is booked at MinSellingRate
+}
+
+// QuoteStay prices stay against the existing bookings. Its SellingRate is
+// ignored; Checkin, Nights and Margin must be set and its RequestID must not
+// clash with an existing booking. A positive maxSellingRate caps the quote,
+// returning ErrNoAcceptableRate when the stay needs more.
+//
+// Including the stay pays off once its profit matches the current optimum
+// minus the best profit still achievable around it. Whether FindMaxProfit
+// takes the stay at exactly that break-even point depends on how the tie
+// falls in its sort order, so the quoted rate is the first whole cent from
+// break-even on at which the optimizer itself includes the stay. Once it
+// does, any higher rate keeps the stay in, so that cent is searched for by
+// doubling the step from break-even and then bisecting.
+func QuoteStay(bookings []Booking, stay Booking, maxSellingRate float64) (Quote, error) {
+	// Without a deadline the computation cannot be interrupted
+	return QuoteStayContext(context.Background(), bookings, stay, maxSellingRate)
+}
+
+// QuoteStayContext is QuoteStay that gives up once ctx is done, returning
+// the context's cause like FindMaxProfitContext.
+func QuoteStayContext(ctx context.Context, bookings []Booking, stay Booking, maxSellingRate float64) (Quote, error) {
+	if err := CheckUniqueRequestIDs(append(slices.Clone(bookings), stay)); err != nil {
+		return Quote{}, err
+	}
+	if stay.Nights <= 0 || stay.Margin <= 0 {
+		return Quote{}, fmt.Errorf("stay needs positive nights and margin")
+	}
+
+	current, err := FindMaxProfitContext(ctx, bookings)
+	if err != nil {
+		return Quote{}, err
+	}
+
+	stay.Checkout = CalculateCheckout(stay.Checkin, stay.Nights)
+	compatible := slices.DeleteFunc(prepareBookings(bookings), func(b Booking) bool {
+		return !isCompatible(b, stay) && !isCompatible(stay, b)
+	})
+	around, err := FindMaxProfitContext(ctx, compatible)
+	if err != nil {
+		return Quote{}, err
+	}
+	breakEvenProfit := current.TotalProfit - around.TotalProfit
+	breakEvenRate := breakEvenProfit * 100 / stay.Margin
+
+	// Selling rates must be positive, so a free slot still costs a cent
+	startCents := math.Max(1, math.Ceil(breakEvenRate*100-1e-6))
+	maxCents := math.Inf(1)
+	if maxSellingRate > 0 {
+		maxCents = math.Floor(maxSellingRate*100 + 1e-6)
+	}
+	noRate := fmt.Errorf("%w: the stay needs more than the maximum of %.2f", ErrNoAcceptableRate, maxSellingRate)
+	if startCents > maxCents {
+		return Quote{}, noRate
+	}
+	accepts := func(cents float64) (ScheduleResult, bool, error) {
+		stay.SellingRate = cents / 100
+		withStay, err := FindMaxProfitContext(ctx, append(slices.Clone(bookings), stay))
+		return withStay, slices.ContainsFunc(withStay.OptimalSchedule, func(b Booking) bool {
+			return b.RequestID == stay.RequestID
+		}), err
+	}
+
+	// Below break-even the stay cannot be accepted, so refused starts just
+	// under it
+	refused, acceptedCents := startCents-1, startCents
+	withStay, ok, err := accepts(acceptedCents)
+	for step := 1.0; !ok; step *= 2 {
+		if err != nil {
+			return Quote{}, err
+		}
+		if acceptedCents >= maxCents {
+			return Quote{}, noRate
+		}
+		refused = acceptedCents
+		acceptedCents = math.Min(refused+step, maxCents)
+		withStay, ok, err = accepts(acceptedCents)
+	}
+	for acceptedCents-refused > 1 {
+		middle := math.Floor((refused + acceptedCents) / 2)
+		result, ok, err := accepts(middle)
+		switch {
+		case err != nil:
+			return Quote{}, err
+		case ok:
+			acceptedCents, withStay = middle, result
+		default:
+			refused = middle
+		}
+	}
+
+	return Quote{
+		MinSellingRate: acceptedCents / 100,
+		Displaced:      bookingsMissingFrom(current.OptimalSchedule, withStay.OptimalSchedule),
+		Schedule:       withStay,
+	}, nil
+}
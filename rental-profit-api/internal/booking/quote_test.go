@@ -0,0 +1,91 @@
+package booking
+
+import (
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestQuoteStay(t *testing.T) {
+	existing := []Booking{
+		newTestBooking(t, "B1", "2024-01-01", 4, 100, 20),
+		newTestBooking(t, "B3", "2024-01-06", 2, 100, 25),
+	}
+	stay := func(checkin string, nights int, margin float64) Booking {
+		return Booking{RequestID: "Q", Checkin: parseTestDate(t, checkin), Nights: nights, Margin: margin}
+	}
+
+	testCases := []struct {
+		name          string
+		bookings      []Booking
+		stay          Booking
+		wantRate      float64
+		wantDisplaced []string
+	}{
+		{"Empty calendar", []Booking{}, stay("2024-01-01", 2, 10), 0.01, []string{}},
+		{"Fits in a gap", existing, stay("2024-01-05", 1, 10), 0.01, []string{}},
+		{"Must match both overlapping bookings", existing, stay("2024-01-03", 5, 50), 90.00, []string{"B1", "B3"}},
+		{"Tie keeps the existing booking", existing[:1], stay("2024-01-04", 2, 10), 200.01, []string{"B1"}},
+		{"Must match one overlapping booking", existing, stay("2024-01-06", 1, 10), 250.00, []string{"B3"}},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := QuoteStay(tt.bookings, tt.stay, 0)
+			if err != nil {
+				t.Fatalf("QuoteStay() unexpected error: %v", err)
+			}
+			assertFloatEquals(t, tt.wantRate, got.MinSellingRate, 1e-9, "MinSellingRate mismatch")
+			if !reflect.DeepEqual(bookingIDs(got.Displaced), tt.wantDisplaced) {
+				t.Errorf("Displaced = %v, want %v", bookingIDs(got.Displaced), tt.wantDisplaced)
+			}
+
+			// One cent less must not be enough for FindMaxProfit to take it
+			cheaper := tt.stay
+			cheaper.SellingRate = got.MinSellingRate - 0.01
+			if slices.ContainsFunc(FindMaxProfit(append(slices.Clone(tt.bookings), cheaper)).OptimalSchedule, func(b Booking) bool {
+				return b.RequestID == tt.stay.RequestID
+			}) {
+				t.Errorf("stay accepted at %.2f, below the quoted rate", cheaper.SellingRate)
+			}
+		})
+	}
+}
+
+func TestQuoteStayDuplicateRequestID(t *testing.T) {
+	existing := []Booking{newTestBooking(t, "B1", "2024-01-01", 4, 100, 20)}
+	stay := Booking{RequestID: "B1", Checkin: parseTestDate(t, "2024-02-01"), Nights: 1, Margin: 10}
+
+	if _, err := QuoteStay(existing, stay, 0); !errors.Is(err, ErrDuplicateRequestID) {
+		t.Errorf("QuoteStay() error = %v, want %v", err, ErrDuplicateRequestID)
+	}
+}
+
+func TestQuoteStayMaxSellingRate(t *testing.T) {
+	// The stay ties B1 at 200.00, which FindMaxProfit settles for B1
+	existing := []Booking{newTestBooking(t, "B1", "2024-01-01", 4, 100, 20)}
+	stay := Booking{RequestID: "Q", Checkin: parseTestDate(t, "2024-01-04"), Nights: 2, Margin: 10}
+
+	testCases := []struct {
+		name           string
+		maxSellingRate float64
+		wantRate       float64
+		wantErr        error
+	}{
+		{"No maximum", 0, 200.01, nil},
+		{"Maximum at the quoted rate", 200.01, 200.01, nil},
+		{"Maximum at break-even", 200, 0, ErrNoAcceptableRate},
+		{"Maximum below break-even", 150, 0, ErrNoAcceptableRate},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := QuoteStay(existing, stay, tt.maxSellingRate)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("QuoteStay() error = %v, want %v", err, tt.wantErr)
+			}
+			assertFloatEquals(t, tt.wantRate, got.MinSellingRate, 1e-9, "MinSellingRate mismatch")
+		})
+	}
+}
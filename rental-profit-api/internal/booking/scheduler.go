@@ -1,18 +1,32 @@
 package booking
 
 import (
+	"context"
 	"math"
 	"slices"
 )
 
+// cancelCheckInterval is how many loop iterations or sort comparisons the
+// context-aware scheduling steps run between checks for cancellation.
+const cancelCheckInterval = 1024
+
+// findMaxProfitSteps is the number of steps FindMaxProfitContext reports
+// progress for: sorting, predecessors, DP and reconstruction.
+const findMaxProfitSteps = 4
+
+// isCompatible reports whether later can follow earlier in a schedule: a
+// guest may check in on the same day the previous one checks out.
+func isCompatible(earlier, later Booking) bool {
+	return !earlier.Checkout.After(later.Checkin)
+}
+
 func findLatestCompatibleBinarySearch(bookings []Booking, i int) int {
-	targetCheckin := bookings[i].Checkin
 	low, high := 0, i-1
 	latestCompatible := -1
 
 	for low <= high {
 		mid := low + (high-low)/2
-		if !bookings[mid].Checkout.After(targetCheckin) {
+		if isCompatible(bookings[mid], bookings[i]) {
 			latestCompatible = mid
 			low = mid + 1
 		} else {
@@ -23,6 +37,17 @@ func findLatestCompatibleBinarySearch(bookings []Booking, i int) int {
 }
 
 func FindMaxProfit(inputBookings []Booking) ScheduleResult {
+	// Without a deadline the computation cannot be interrupted
+	result, _ := FindMaxProfitContext(context.Background(), inputBookings)
+	return result
+}
+
+// FindMaxProfitContext is FindMaxProfit that gives up once ctx is done,
+// checking it periodically while sorting and filling the DP tables. The
+// error is the context's cause, so callers can tell a deadline they set
+// apart from the caller going away. Progress is reported after each step
+// to a ProgressFunc attached with WithProgress.
+func FindMaxProfitContext(ctx context.Context, inputBookings []Booking) (ScheduleResult, error) {
 	bookingsLength := len(inputBookings)
 
 	result := ScheduleResult{ // Initialize result struct
@@ -34,39 +59,69 @@ func FindMaxProfit(inputBookings []Booking) ScheduleResult {
 	}
 
 	if bookingsLength == 0 {
-		return result
+		return result, nil
 	}
 
 	// 1.- Calculate the checkout date and profit for each booking
-	bookings := make([]Booking, bookingsLength)
-	for i, booking := range inputBookings {
-		bookings[i] = booking
-		bookings[i].Checkout = CalculateCheckout(booking.Checkin, booking.Nights)
-		bookings[i].Profit = CalculateProfit(booking.SellingRate, booking.Margin, booking.Nights)
-	}
+	bookings := prepareBookings(inputBookings)
 
 	// 2.- Sort bookings by Checkout time
-	slices.SortFunc(bookings, func(a, b Booking) int {
-		checkoutComparision := a.Checkout.Compare(b.Checkout)
-		if checkoutComparision != 0 {
-			return checkoutComparision
-		}
-		return a.Checkin.Compare(b.Checkin)
-	})
+	if err := sortByCheckoutContext(ctx, bookings); err != nil {
+		return ScheduleResult{}, err
+	}
+	reportProgress(ctx, 1, findMaxProfitSteps)
 
 	// 3.- Calculate the latest compatible predecessor for each booking using binary search
 	latestCompatiblePredecessors := make([]int, bookingsLength)
-	for i := range bookingsLength {
-		latestCompatiblePredecessors[i] = findLatestCompatibleBinarySearch(bookings, i)
+	if err := updatePredecessors(ctx, bookings, latestCompatiblePredecessors, 0); err != nil {
+		return ScheduleResult{}, err
 	}
+	reportProgress(ctx, 2, findMaxProfitSteps)
 
 	// 4.- Calculate max profit up to index i
 	dp := make([]float64, bookingsLength)
-	if bookingsLength > 0 {
+	if err := updateDP(ctx, bookings, latestCompatiblePredecessors, dp, 0); err != nil {
+		return ScheduleResult{}, err
+	}
+	reportProgress(ctx, 3, findMaxProfitSteps)
+
+	// 5.- Reconstruct the optimal schedule from the DP table
+	result.OptimalSchedule = reconstructSchedule(bookings, latestCompatiblePredecessors, dp)
+
+	// 6.- Calculate the profits
+	calculateScheduleStats(&result)
+	reportProgress(ctx, findMaxProfitSteps, findMaxProfitSteps)
+
+	return result, nil
+}
+
+// updatePredecessors fills latestCompatiblePredecessors from index "from"
+// onward. Entries before it only depend on earlier bookings, so callers that
+// change the sorted slice at "from" can keep them. It only fails when ctx is
+// done.
+func updatePredecessors(ctx context.Context, bookings []Booking, latestCompatiblePredecessors []int, from int) error {
+	for i := from; i < len(bookings); i++ {
+		if (i-from)%cancelCheckInterval == 0 && ctx.Err() != nil {
+			return context.Cause(ctx)
+		}
+		latestCompatiblePredecessors[i] = findLatestCompatibleBinarySearch(bookings, i)
+	}
+	return nil
+}
+
+// updateDP fills the max profit up to each index from "from" onward,
+// reusing the already computed entries before it. It only fails when ctx is
+// done.
+func updateDP(ctx context.Context, bookings []Booking, latestCompatiblePredecessors []int, dp []float64, from int) error {
+	bookingsLength := len(bookings)
+	if bookingsLength > 0 && from == 0 {
 		dp[0] = math.Max(0, bookings[0].Profit)
 	}
 
-	for i := 1; i < bookingsLength; i++ {
+	for i := max(from, 1); i < bookingsLength; i++ {
+		if (i-from)%cancelCheckInterval == 0 && ctx.Err() != nil {
+			return context.Cause(ctx)
+		}
 		profit_of_i := bookings[i].Profit
 		compatibleProfit := 0.0
 		if latestCompatiblePredecessors[i] != -1 {
@@ -77,17 +132,24 @@ func FindMaxProfit(inputBookings []Booking) ScheduleResult {
 
 		dp[i] = math.Max(profitIncluding_i, profitExcluding_i)
 	}
+	return nil
+}
 
-	// 5.- Find the overall maximum profit
+// reconstructSchedule backtracks through the DP decisions and returns the
+// optimal schedule ordered by checkout.
+func reconstructSchedule(bookings []Booking, latestCompatiblePredecessors []int, dp []float64) []Booking {
+	bookingsLength := len(bookings)
+
+	// Find the overall maximum profit
 	maxProfit := 0.0
 	if bookingsLength > 0 {
 		maxProfit = dp[bookingsLength-1]
 	}
 	if maxProfit <= 0 {
-		return result
+		return []Booking{}
 	}
 
-	// 6.- Reconstruct the optimal schedule by backtracking through DP decisions
+	// Backtrack through DP decisions, starting from the latest checkout
 	optimalSchedule := []Booking{}
 	i := bookingsLength - 1
 	currentExpectedProfit := maxProfit 
@@ -126,11 +188,74 @@ func FindMaxProfit(inputBookings []Booking) ScheduleResult {
 		}
 	}
 
-	// 7.- Reversing the schedule because it was created backwards from latest checkout
+	// Reversing the schedule because it was created backwards from latest checkout
 	slices.Reverse(optimalSchedule)
-	result.OptimalSchedule = optimalSchedule
+	return optimalSchedule
+}
+
+// prepareBookings copies the input and fills in the derived Checkout and
+// Profit fields, leaving the caller's slice untouched.
+func prepareBookings(inputBookings []Booking) []Booking {
+	bookings := make([]Booking, len(inputBookings))
+	for i, booking := range inputBookings {
+		bookings[i] = booking
+		bookings[i].Checkout = CalculateCheckout(booking.Checkin, booking.Nights)
+		bookings[i].Profit = CalculateProfit(booking.SellingRate, booking.Margin, booking.Nights)
+	}
+	return bookings
+}
+
+// sortByCheckout orders bookings by checkout, breaking ties by checkin.
+func sortByCheckout(bookings []Booking) {
+	slices.SortFunc(bookings, compareByCheckout)
+}
+
+// sortCancelled carries the cancellation cause out of a sort comparison.
+type sortCancelled struct {
+	err error
+}
 
-	// 8.- Calculate the profits
+// sortByCheckoutContext is sortByCheckout that gives up once ctx is done,
+// leaving bookings in an unspecified order. The sort cannot be stopped
+// from the outside, so the comparison function bails out with a panic that
+// is recovered here.
+func sortByCheckoutContext(ctx context.Context, bookings []Booking) (err error) {
+	if ctx.Done() == nil {
+		sortByCheckout(bookings)
+		return nil
+	}
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			cancelled, ok := recovered.(sortCancelled)
+			if !ok {
+				panic(recovered)
+			}
+			err = cancelled.err
+		}
+	}()
+
+	comparisons := 0
+	slices.SortFunc(bookings, func(a, b Booking) int {
+		comparisons++
+		if comparisons%cancelCheckInterval == 0 && ctx.Err() != nil {
+			panic(sortCancelled{err: context.Cause(ctx)})
+		}
+		return compareByCheckout(a, b)
+	})
+	return nil
+}
+
+func compareByCheckout(a, b Booking) int {
+	checkoutComparision := a.Checkout.Compare(b.Checkout)
+	if checkoutComparision != 0 {
+		return checkoutComparision
+	}
+	return a.Checkin.Compare(b.Checkin)
+}
+
+// calculateScheduleStats fills the profit totals and per-night stats of
+// result from its OptimalSchedule.
+func calculateScheduleStats(result *ScheduleResult) {
 	var totalProfit float64
 	var totalProfitPerNight float64
 	scheduleLen := len(result.OptimalSchedule)
@@ -166,6 +291,4 @@ func FindMaxProfit(inputBookings []Booking) ScheduleResult {
 	if scheduleLen > 0 {
 		result.AvgProfitPerNight = totalProfitPerNight / float64(scheduleLen)
 	}
-
-	return result
 }
@@ -1,9 +1,13 @@
 package booking
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"reflect"
 	"sort"
 	"testing"
+	"time"
 )
 
 func newTestBooking(t *testing.T, id string, checkinStr string, nights int, rate, margin float64) Booking {
@@ -193,4 +197,87 @@ func TestFindMaxProfit(t *testing.T) {
 			assertScheduleResult(t, tt.expectedResult, gotResult)
 		})
 	}
-}
\ No newline at end of file
+}
+
+func manyTestBookings(count int) []Booking {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	bookings := make([]Booking, count)
+	for i := range bookings {
+		// Scramble the check-ins so the sort has work to do
+		bookings[i] = Booking{
+			RequestID:   fmt.Sprintf("B%d", i),
+			Checkin:     start.AddDate(0, 0, (i*7919)%count),
+			Nights:      1 + i%5,
+			SellingRate: float64(100 + i%50),
+			Margin:      10,
+		}
+	}
+	return bookings
+}
+
+func TestFindMaxProfitContext(t *testing.T) {
+	bookings := manyTestBookings(5000)
+	errBudget := errors.New("budget exceeded")
+
+	t.Run("Live context matches FindMaxProfit", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		got, err := FindMaxProfitContext(ctx, bookings)
+		if err != nil {
+			t.Fatalf("FindMaxProfitContext() unexpected error: %v", err)
+		}
+		assertScheduleResult(t, FindMaxProfit(bookings), got)
+	})
+
+	t.Run("Cancelled context aborts", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		got, err := FindMaxProfitContext(ctx, bookings)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("FindMaxProfitContext() error = %v, want %v", err, context.Canceled)
+		}
+		if got.OptimalSchedule != nil {
+			t.Errorf("FindMaxProfitContext() returned a partial schedule of %d bookings", len(got.OptimalSchedule))
+		}
+	})
+
+	t.Run("Cause is reported", func(t *testing.T) {
+		ctx, cancel := context.WithCancelCause(context.Background())
+		cancel(errBudget)
+		if _, err := FindMaxProfitContext(ctx, bookings); !errors.Is(err, errBudget) {
+			t.Errorf("FindMaxProfitContext() error = %v, want %v", err, errBudget)
+		}
+	})
+
+	t.Run("Sort stops early", func(t *testing.T) {
+		ctx, cancel := context.WithCancelCause(context.Background())
+		cancel(errBudget)
+		if err := sortByCheckoutContext(ctx, prepareBookings(bookings)); !errors.Is(err, errBudget) {
+			t.Errorf("sortByCheckoutContext() error = %v, want %v", err, errBudget)
+		}
+	})
+
+	t.Run("Progress is reported per step", func(t *testing.T) {
+		var reported []int
+		ctx := WithProgress(context.Background(), func(done, total int) {
+			if total != findMaxProfitSteps {
+				t.Errorf("progress total = %d, want %d", total, findMaxProfitSteps)
+			}
+			reported = append(reported, done)
+		})
+		if _, err := FindMaxProfitContext(ctx, bookings); err != nil {
+			t.Fatalf("FindMaxProfitContext() unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(reported, []int{1, 2, 3, 4}) {
+			t.Errorf("reported progress %v, want [1 2 3 4]", reported)
+		}
+	})
+
+	t.Run("Empty input never fails", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, err := FindMaxProfitContext(ctx, nil); err != nil {
+			t.Errorf("FindMaxProfitContext() unexpected error: %v", err)
+		}
+	})
+}
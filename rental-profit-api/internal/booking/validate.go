@@ -0,0 +1,92 @@
+package booking
+
+import (
+	"context"
+	"slices"
+	"sort"
+)
+
+// Overlap is a pair of bookings that cannot both be in a schedule. First is
+// the one that checks in earlier.
+type Overlap struct {
+	First  Booking
+	Second Booking
+}
+
+// MaxReportedOverlaps caps the pairs ValidateSchedule lists. n stays on the
+// same dates make n(n-1)/2 pairs, so beyond the cap they are only counted.
+const MaxReportedOverlaps = 100
+
+// ScheduleValidation is the outcome of checking a proposed schedule. The
+// Schedule stats are computed even when the proposal is infeasible.
+type ScheduleValidation struct {
+	Schedule     ScheduleResult
+	Overlaps     []Overlap // The first MaxReportedOverlaps overlapping pairs
+	OverlapCount int       // All overlapping pairs, listed or not
+}
+
+func (v ScheduleValidation) Feasible() bool {
+	return v.OverlapCount == 0
+}
+
+// Truncated reports whether some overlapping pairs were left out of Overlaps.
+func (v ScheduleValidation) Truncated() bool {
+	return v.OverlapCount > len(v.Overlaps)
+}
+
+// ValidateSchedule checks a proposed set of bookings for overlaps using the
+// same compatibility rule as FindMaxProfit and summarizes its profit.
+func ValidateSchedule(selected []Booking) ScheduleValidation {
+	// Without a deadline the computation cannot be interrupted
+	validation, _ := ValidateScheduleContext(context.Background(), selected)
+	return validation
+}
+
+// ValidateScheduleContext is ValidateSchedule that gives up once ctx is
+// done, returning the context's cause like FindMaxProfitContext.
+func ValidateScheduleContext(ctx context.Context, selected []Booking) (ScheduleValidation, error) {
+	schedule := prepareBookings(selected)
+	if err := sortByCheckoutContext(ctx, schedule); err != nil {
+		return ScheduleValidation{}, err
+	}
+
+	result := ScheduleResult{OptimalSchedule: schedule}
+	calculateScheduleStats(&result)
+
+	overlaps, count := FindOverlaps(schedule, MaxReportedOverlaps)
+	return ScheduleValidation{
+		Schedule:     result,
+		Overlaps:     overlaps,
+		OverlapCount: count,
+	}, nil
+}
+
+// FindOverlaps returns up to limit pairs of overlapping bookings, ordered by
+// the checkin of the first booking in the pair, and the number of
+// overlapping pairs in all. It takes O(n log n) plus the pairs listed.
+func FindOverlaps(bookings []Booking, limit int) ([]Overlap, int) {
+	byCheckin := prepareBookings(bookings)
+	slices.SortStableFunc(byCheckin, func(a, b Booking) int {
+		return a.Checkin.Compare(b.Checkin)
+	})
+
+	// Sweep by checkin: the bookings starting after the current one and
+	// before it checks out overlap it, and they are the ones up to the first
+	// compatible booking.
+	overlaps := []Overlap{}
+	count := 0
+	for i, first := range byCheckin {
+		later := byCheckin[i+1:]
+		end := sort.Search(len(later), func(j int) bool {
+			return isCompatible(first, later[j])
+		})
+		count += end
+		for _, second := range later[:end] {
+			if len(overlaps) >= limit {
+				break
+			}
+			overlaps = append(overlaps, Overlap{First: first, Second: second})
+		}
+	}
+	return overlaps, count
+}
@@ -0,0 +1,96 @@
+package booking
+
+import (
+	"reflect"
+	"testing"
+)
+
+func overlapIDs(overlaps []Overlap) [][2]string {
+	ids := make([][2]string, len(overlaps))
+	for i, o := range overlaps {
+		ids[i] = [2]string{o.First.RequestID, o.Second.RequestID}
+	}
+	return ids
+}
+
+func TestFindOverlaps(t *testing.T) {
+	testCases := []struct {
+		name      string
+		bookings  []Booking
+		limit     int
+		want      [][2]string
+		wantCount int
+	}{
+		{
+			name:     "Empty input",
+			bookings: []Booking{},
+			limit:    10,
+			want:     [][2]string{},
+		},
+		{
+			name: "Back-to-back stays do not overlap",
+			bookings: []Booking{
+				newTestBooking(t, "B1", "2024-01-01", 4, 100, 10),
+				newTestBooking(t, "B2", "2024-01-05", 2, 100, 10),
+			},
+			limit: 10,
+			want:  [][2]string{},
+		},
+		{
+			name: "Every overlapping pair is reported",
+			bookings: []Booking{
+				newTestBooking(t, "B3", "2024-01-04", 2, 100, 10),
+				newTestBooking(t, "B1", "2024-01-01", 10, 100, 10),
+				newTestBooking(t, "B2", "2024-01-02", 2, 100, 10),
+				newTestBooking(t, "B4", "2024-01-11", 1, 100, 10),
+			},
+			limit:     10,
+			want:      [][2]string{{"B1", "B2"}, {"B1", "B3"}},
+			wantCount: 2,
+		},
+		{
+			name: "Pairs beyond the limit are only counted",
+			bookings: []Booking{
+				newTestBooking(t, "B1", "2024-01-01", 3, 100, 10),
+				newTestBooking(t, "B2", "2024-01-01", 3, 100, 10),
+				newTestBooking(t, "B3", "2024-01-02", 3, 100, 10),
+				newTestBooking(t, "B4", "2024-01-03", 3, 100, 10),
+			},
+			limit:     2,
+			want:      [][2]string{{"B1", "B2"}, {"B1", "B3"}},
+			wantCount: 6,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			overlaps, count := FindOverlaps(tt.bookings, tt.limit)
+			if got := overlapIDs(overlaps); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindOverlaps() = %v, want %v", got, tt.want)
+			}
+			if count != tt.wantCount {
+				t.Errorf("FindOverlaps() count = %d, want %d", count, tt.wantCount)
+			}
+		})
+	}
+}
+
+func TestValidateSchedule(t *testing.T) {
+	selected := []Booking{
+		{RequestID: "B1", Checkin: parseTestDate(t, "2024-01-01"), Nights: 5, SellingRate: 100, Margin: 10},
+		{RequestID: "B2", Checkin: parseTestDate(t, "2024-01-04"), Nights: 4, SellingRate: 150, Margin: 20},
+	}
+
+	got := ValidateSchedule(selected)
+
+	if got.Feasible() {
+		t.Errorf("ValidateSchedule() reported an overlapping schedule as feasible")
+	}
+	if !reflect.DeepEqual(overlapIDs(got.Overlaps), [][2]string{{"B1", "B2"}}) {
+		t.Errorf("Overlaps = %v, want [[B1 B2]]", overlapIDs(got.Overlaps))
+	}
+	if got.OverlapCount != 1 || got.Truncated() {
+		t.Errorf("OverlapCount = %d, Truncated() = %v, want 1 and false", got.OverlapCount, got.Truncated())
+	}
+	assertFloatEquals(t, 40.0, got.Schedule.TotalProfit, 1e-9, "TotalProfit mismatch")
+}
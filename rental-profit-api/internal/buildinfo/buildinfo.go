@@ -0,0 +1,51 @@
+// Package buildinfo reports which build of the server is running.
+package buildinfo
+
+import "runtime/debug"
+
+// Set at link time when the VCS metadata is not available to the Go
+// toolchain, as in Docker builds that exclude .git:
+//
+//	-ldflags "-X rental-profit-api/internal/buildinfo.revision=<sha> -X rental-profit-api/internal/buildinfo.buildTime=<RFC 3339>"
+var (
+	revision  string
+	buildTime string
+)
+
+type Info struct {
+	Version    string
+	Revision   string
+	CommitTime string
+	BuildTime  string
+	Modified   bool
+	GoVersion  string
+}
+
+// Read combines the module and VCS data embedded by the Go toolchain with
+// the link-time values, which take precedence.
+func Read() Info {
+	info := Info{
+		Revision:  revision,
+		BuildTime: buildTime,
+	}
+
+	embedded, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	info.Version = embedded.Main.Version
+	info.GoVersion = embedded.GoVersion
+	for _, setting := range embedded.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if info.Revision == "" {
+				info.Revision = setting.Value
+			}
+		case "vcs.time":
+			info.CommitTime = setting.Value
+		case "vcs.modified":
+			info.Modified = setting.Value == "true"
+		}
+	}
+	return info
+}
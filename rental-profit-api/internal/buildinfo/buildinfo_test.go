@@ -0,0 +1,22 @@
+package buildinfo
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestReadPrefersLinkTimeValues(t *testing.T) {
+	defer func(previousRevision, previousBuildTime string) {
+		revision, buildTime = previousRevision, previousBuildTime
+	}(revision, buildTime)
+	revision, buildTime = "abc123", "2024-01-01T00:00:00Z"
+
+	info := Read()
+
+	if info.Revision != "abc123" || info.BuildTime != "2024-01-01T00:00:00Z" {
+		t.Errorf("Read() = %+v, want the link-time revision and build time", info)
+	}
+	if info.GoVersion != runtime.Version() {
+		t.Errorf("GoVersion = %q, want %q", info.GoVersion, runtime.Version())
+	}
+}
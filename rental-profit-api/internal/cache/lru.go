@@ -0,0 +1,88 @@
+// Package cache provides a least-recently-used cache bounded by the total
+// size of the values it holds rather than by their number.
+package cache
+
+import (
+	"container/list"
+	"sync"
+)
+
+// LRU keeps values up to a total size, evicting the least recently used
+// ones to make room. Sizes are whatever unit the caller estimates them in,
+// usually bytes. It is safe for concurrent use.
+type LRU[K comparable, V any] struct {
+	maxSize int64
+
+	mu    sync.Mutex
+	size  int64
+	order *list.List // Of *entry, most recently used first
+	items map[K]*list.Element
+}
+
+type entry[K comparable, V any] struct {
+	key   K
+	value V
+	size  int64
+}
+
+// NewLRU returns a cache holding values of up to maxSize in total.
+func NewLRU[K comparable, V any](maxSize int64) *LRU[K, V] {
+	return &LRU[K, V]{
+		maxSize: maxSize,
+		order:   list.New(),
+		items:   make(map[K]*list.Element),
+	}
+}
+
+// Get returns the value kept for key and marks it as recently used.
+func (c *LRU[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.items[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	c.order.MoveToFront(element)
+	return element.Value.(*entry[K, V]).value, true
+}
+
+// Add keeps value for key, replacing any previous value, and evicts the
+// least recently used values until the total fits. A value larger than the
+// whole cache is not kept.
+func (c *LRU[K, V]) Add(key K, value V, size int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.items[key]; ok {
+		c.removeLocked(element)
+	}
+	if size > c.maxSize {
+		return
+	}
+	c.items[key] = c.order.PushFront(&entry[K, V]{key: key, value: value, size: size})
+	c.size += size
+	for c.size > c.maxSize {
+		c.removeLocked(c.order.Back())
+	}
+}
+
+// Len reports how many values are kept.
+func (c *LRU[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.items)
+}
+
+// Size reports the total size of the values kept.
+func (c *LRU[K, V]) Size() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.size
+}
+
+// removeLocked drops element. The caller must hold c.mu.
+func (c *LRU[K, V]) removeLocked(element *list.Element) {
+	e := c.order.Remove(element).(*entry[K, V])
+	delete(c.items, e.key)
+	c.size -= e.size
+}
@@ -0,0 +1,75 @@
+package cache
+
+import (
+	"testing"
+)
+
+func TestLRU(t *testing.T) {
+	c := NewLRU[string, int](10)
+
+	// --- Define Test Scenarios ---
+	testCases := []struct {
+		name         string
+		add          func()
+		expectedKeys []string // Present after add, in no particular order
+		missingKeys  []string
+		expectedSize int64
+	}{
+		{
+			name:         "Values within the size",
+			add:          func() { c.Add("a", 1, 4); c.Add("b", 2, 4) },
+			expectedKeys: []string{"a", "b"},
+			expectedSize: 8,
+		},
+		{
+			name:         "Least recently used is evicted",
+			add:          func() { c.Get("a"); c.Add("c", 3, 4) },
+			expectedKeys: []string{"a", "c"},
+			missingKeys:  []string{"b"},
+			expectedSize: 8,
+		},
+		{
+			name:         "Replacing a value updates the size",
+			add:          func() { c.Add("a", 10, 2) },
+			expectedKeys: []string{"a", "c"},
+			expectedSize: 6,
+		},
+		{
+			name:         "Value larger than the cache is not kept",
+			add:          func() { c.Add("huge", 4, 11) },
+			expectedKeys: []string{"a", "c"},
+			missingKeys:  []string{"huge"},
+			expectedSize: 6,
+		},
+		{
+			name:         "Large value evicts several",
+			add:          func() { c.Add("d", 5, 9) },
+			expectedKeys: []string{"d"},
+			missingKeys:  []string{"a", "c"},
+			expectedSize: 9,
+		},
+	}
+
+	// --- Execute Scenarios ---
+	for _, tc := range testCases {
+		tc.add()
+		// Check the missing keys first: Get on present ones reorders them
+		for _, key := range tc.missingKeys {
+			if _, ok := c.Get(key); ok {
+				t.Errorf("%s: %q is still cached", tc.name, key)
+			}
+		}
+		for _, key := range tc.expectedKeys {
+			if _, ok := c.Get(key); !ok {
+				t.Errorf("%s: %q is not cached", tc.name, key)
+			}
+		}
+		if c.Len() != len(tc.expectedKeys) || c.Size() != tc.expectedSize {
+			t.Errorf("%s: Len() = %d, Size() = %d, want %d and %d", tc.name, c.Len(), c.Size(), len(tc.expectedKeys), tc.expectedSize)
+		}
+	}
+
+	if value, _ := c.Get("d"); value != 5 {
+		t.Errorf("Get(%q) = %d, want 5", "d", value)
+	}
+}
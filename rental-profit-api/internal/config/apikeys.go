@@ -0,0 +1,114 @@
+package config
+
+import "fmt"
+
+// minAPIKeyLength keeps keys long enough not to be guessed.
+const minAPIKeyLength = 16
+
+// APIKey grants its holder access to the data of one tenant. Several keys
+// may share a tenant, e.g. to rotate them.
+type APIKey struct {
+	Key    string `json:"key" yaml:"key"`
+	Tenant string `json:"tenant" yaml:"tenant"`
+}
+
+// TenantOverrides replaces some settings of the deployment for one tenant.
+// Fields left out keep the deployment's value.
+type TenantOverrides struct {
+	Limits struct {
+		MaxBookings     *int64   `json:"max_bookings" yaml:"max_bookings"`
+		MaxNights       *int64   `json:"max_nights" yaml:"max_nights"`
+		MaxDateSpanDays *int64   `json:"max_date_span_days" yaml:"max_date_span_days"`
+		MaxSellingRate  *float64 `json:"max_selling_rate" yaml:"max_selling_rate"`
+	} `json:"limits" yaml:"limits"`
+	Overbooking struct {
+		MaxWalkProbability *float64 `json:"max_walk_probability" yaml:"max_walk_probability"`
+		WalkPenalty        *float64 `json:"walk_penalty" yaml:"walk_penalty"`
+	} `json:"overbooking" yaml:"overbooking"`
+	JSON struct {
+		Strict *bool `json:"strict" yaml:"strict"`
+	} `json:"json" yaml:"json"`
+	RateLimit struct {
+		RequestsPerSecond *float64 `json:"requests_per_second" yaml:"requests_per_second"`
+		Burst             *int64   `json:"burst" yaml:"burst"`
+	} `json:"rate_limit" yaml:"rate_limit"`
+}
+
+// Apply returns c with the overridden settings replaced.
+func (o TenantOverrides) Apply(c Config) Config {
+	override(&c.Limits.MaxBookings, o.Limits.MaxBookings)
+	override(&c.Limits.MaxNights, o.Limits.MaxNights)
+	override(&c.Limits.MaxDateSpanDays, o.Limits.MaxDateSpanDays)
+	override(&c.Limits.MaxSellingRate, o.Limits.MaxSellingRate)
+	override(&c.Overbooking.MaxWalkProbability, o.Overbooking.MaxWalkProbability)
+	override(&c.Overbooking.WalkPenalty, o.Overbooking.WalkPenalty)
+	override(&c.JSON.Strict, o.JSON.Strict)
+	override(&c.RateLimit.RequestsPerSecond, o.RateLimit.RequestsPerSecond)
+	override(&c.RateLimit.Burst, o.RateLimit.Burst)
+	return c
+}
+
+func override[T any](setting *T, value *T) {
+	if value != nil {
+		*setting = *value
+	}
+}
+
+// apiKeysFile is the layout of the file named by auth.keys_file:
+//
+//	keys:
+//	  - key: 8b1f0c0e6f2a4d3c9e7b5a1d2c3e4f50
+//	    tenant: acme
+//	tenants:
+//	  acme:
+//	    limits:
+//	      max_bookings: 5000
+type apiKeysFile struct {
+	Keys    []APIKey                   `json:"keys" yaml:"keys"`
+	Tenants map[string]TenantOverrides `json:"tenants" yaml:"tenants"`
+}
+
+// LoadAPIKeys reads the YAML or JSON keys file at path, returning the keys
+// and the settings overridden per tenant. Every key needs a tenant, must be
+// at least minAPIKeyLength characters long and may only be listed once.
+// Overrides may only name tenants that have a key.
+func LoadAPIKeys(path string) ([]APIKey, map[string]TenantOverrides, error) {
+	var file apiKeysFile
+	if err := decodeFile(path, "API keys file", &file); err != nil {
+		return nil, nil, err
+	}
+	if len(file.Keys) == 0 {
+		return nil, nil, fmt.Errorf("%w: API keys file %s lists no keys", ErrInvalidConfig, path)
+	}
+
+	seen := make(map[string]bool, len(file.Keys))
+	tenants := make(map[string]bool)
+	for i, key := range file.Keys {
+		switch {
+		case key.Tenant == "":
+			return nil, nil, fmt.Errorf("%w: API keys file %s: keys[%d] has no tenant", ErrInvalidConfig, path, i)
+		case len(key.Key) < minAPIKeyLength:
+			return nil, nil, fmt.Errorf("%w: API keys file %s: keys[%d] must be at least %d characters long", ErrInvalidConfig, path, i, minAPIKeyLength)
+		case seen[key.Key]:
+			return nil, nil, fmt.Errorf("%w: API keys file %s: keys[%d] is listed twice", ErrInvalidConfig, path, i)
+		}
+		seen[key.Key] = true
+		tenants[key.Tenant] = true
+	}
+	for tenant := range file.Tenants {
+		if !tenants[tenant] {
+			return nil, nil, fmt.Errorf("%w: API keys file %s: tenants.%s has no key", ErrInvalidConfig, path, tenant)
+		}
+	}
+	return file.Keys, file.Tenants, nil
+}
+
+// ForTenant returns the settings tenant is served with: c with overrides
+// applied, validated like c itself.
+func (c Config) ForTenant(tenant string, overrides TenantOverrides) (Config, error) {
+	tenantConfig := overrides.Apply(c)
+	if err := tenantConfig.Validate(); err != nil {
+		return Config{}, fmt.Errorf("tenant %s: %w", tenant, err)
+	}
+	return tenantConfig, nil
+}
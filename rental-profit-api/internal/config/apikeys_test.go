@@ -0,0 +1,161 @@
+package config
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLoadAPIKeys(t *testing.T) {
+	yamlPath := writeTestFile(t, "keys.yaml", `
+keys:
+  - key: acme-0123456789abcdef
+    tenant: acme
+  - key: acme-fedcba9876543210
+    tenant: acme
+  - key: globex-0123456789abcdef
+    tenant: globex
+`)
+	jsonPath := writeTestFile(t, "keys.json", `{"keys":[{"key":"globex-0123456789abcdef","tenant":"globex"}]}`)
+
+	testCases := []struct {
+		name     string
+		path     string
+		expected []APIKey
+	}{
+		{
+			name: "YAML",
+			path: yamlPath,
+			expected: []APIKey{
+				{Key: "acme-0123456789abcdef", Tenant: "acme"},
+				{Key: "acme-fedcba9876543210", Tenant: "acme"},
+				{Key: "globex-0123456789abcdef", Tenant: "globex"},
+			},
+		},
+		{
+			name:     "JSON",
+			path:     jsonPath,
+			expected: []APIKey{{Key: "globex-0123456789abcdef", Tenant: "globex"}},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, _, err := LoadAPIKeys(tt.path)
+			if err != nil {
+				t.Fatalf("LoadAPIKeys() unexpected error: %v", err)
+			}
+			if !slices.Equal(keys, tt.expected) {
+				t.Errorf("LoadAPIKeys() = %+v, want %+v", keys, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLoadAPIKeysErrors(t *testing.T) {
+	testCases := []struct {
+		name        string
+		file        string
+		content     string
+		errContains string
+	}{
+		{
+			name:        "No keys",
+			file:        "keys.yaml",
+			content:     "keys: []\n",
+			errContains: "lists no keys",
+		},
+		{
+			name:        "Missing tenant",
+			file:        "keys.yaml",
+			content:     "keys:\n  - key: 0123456789abcdef\n",
+			errContains: "keys[0] has no tenant",
+		},
+		{
+			name:        "Short key",
+			file:        "keys.yaml",
+			content:     "keys:\n  - key: short\n    tenant: acme\n",
+			errContains: "keys[0] must be at least 16 characters long",
+		},
+		{
+			name:        "Duplicate key",
+			file:        "keys.yaml",
+			content:     "keys:\n  - key: 0123456789abcdef\n    tenant: acme\n  - key: 0123456789abcdef\n    tenant: globex\n",
+			errContains: "keys[1] is listed twice",
+		},
+		{
+			name:        "Overrides for a tenant without keys",
+			file:        "keys.yaml",
+			content:     "keys:\n  - key: 0123456789abcdef\n    tenant: acme\ntenants:\n  globex:\n    limits:\n      max_bookings: 10\n",
+			errContains: "tenants.globex has no key",
+		},
+		{
+			name:        "Unknown override",
+			file:        "keys.yaml",
+			content:     "keys:\n  - key: 0123456789abcdef\n    tenant: acme\ntenants:\n  acme:\n    compute:\n      budget: 1s\n",
+			errContains: "parsing API keys file",
+		},
+		{
+			name:        "Unknown field",
+			file:        "keys.json",
+			content:     `{"keys":[{"key":"0123456789abcdef","tenant":"acme","role":"admin"}]}`,
+			errContains: "parsing API keys file",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := LoadAPIKeys(writeTestFile(t, tt.file, tt.content))
+			if !errors.Is(err, ErrInvalidConfig) {
+				t.Fatalf("LoadAPIKeys() error = %v, want %v", err, ErrInvalidConfig)
+			}
+			if !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("LoadAPIKeys() error = %q, want substring %q", err.Error(), tt.errContains)
+			}
+		})
+	}
+}
+
+func TestTenantOverrides(t *testing.T) {
+	path := writeTestFile(t, "keys.yaml", `
+keys:
+  - key: acme-0123456789abcdef
+    tenant: acme
+  - key: globex-0123456789abcdef
+    tenant: globex
+tenants:
+  acme:
+    limits:
+      max_bookings: 10
+    json:
+      strict: true
+    rate_limit:
+      requests_per_second: 1
+  globex:
+    overbooking:
+      max_walk_probability: 2
+`)
+	_, overrides, err := LoadAPIKeys(path)
+	if err != nil {
+		t.Fatalf("LoadAPIKeys() unexpected error: %v", err)
+	}
+
+	base := Default()
+	acme, err := base.ForTenant("acme", overrides["acme"])
+	if err != nil {
+		t.Fatalf("ForTenant(acme) unexpected error: %v", err)
+	}
+	if acme.Limits.MaxBookings != 10 || !acme.JSON.Strict || acme.RateLimit.RequestsPerSecond != 1 {
+		t.Errorf("ForTenant(acme) = %+v, want the overridden settings", acme)
+	}
+	// Settings left out keep the deployment's value
+	if acme.Limits.MaxNights != base.Limits.MaxNights || acme.RateLimit.Burst != base.RateLimit.Burst {
+		t.Errorf("ForTenant(acme) limits %+v, rate limit %+v, want the rest from %+v, %+v", acme.Limits, acme.RateLimit, base.Limits, base.RateLimit)
+	}
+
+	_, err = base.ForTenant("globex", overrides["globex"])
+	if !errors.Is(err, ErrInvalidConfig) || !strings.Contains(err.Error(), "tenant globex") || !strings.Contains(err.Error(), "overbooking.max_walk_probability") {
+		t.Errorf("ForTenant(globex) error = %v, want the invalid override named", err)
+	}
+}
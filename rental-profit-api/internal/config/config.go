@@ -0,0 +1,567 @@
+// Package config loads the server settings from defaults, an optional YAML
+// or JSON file, environment variables and command-line flags, in increasing
+// order of precedence.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EnvPrefix is prepended to every environment variable read by Load.
+const EnvPrefix = "RENTAL_"
+
+var ErrInvalidConfig = errors.New("invalid configuration")
+
+type Config struct {
+	ListenAddr  string            `json:"listen_addr" yaml:"listen_addr"`
+	Log         LogConfig         `json:"log" yaml:"log"`
+	HTTP        HTTPConfig        `json:"http" yaml:"http"`
+	GRPC        GRPCConfig        `json:"grpc" yaml:"grpc"`
+	Limits      LimitsConfig      `json:"limits" yaml:"limits"`
+	Compute     ComputeConfig     `json:"compute" yaml:"compute"`
+	Cache       CacheConfig       `json:"cache" yaml:"cache"`
+	RateLimit   RateLimitConfig   `json:"rate_limit" yaml:"rate_limit"`
+	JSON        JSONConfig        `json:"json" yaml:"json"`
+	Idempotency IdempotencyConfig `json:"idempotency" yaml:"idempotency"`
+	Jobs        JobsConfig        `json:"jobs" yaml:"jobs"`
+	Webhooks    WebhooksConfig    `json:"webhooks" yaml:"webhooks"`
+	Auth        AuthConfig        `json:"auth" yaml:"auth"`
+	Features    FeatureConfig     `json:"features" yaml:"features"`
+	Overbooking OverbookingConfig `json:"overbooking" yaml:"overbooking"`
+}
+
+type LogConfig struct {
+	Level  string `json:"level" yaml:"level"`   // debug, info, warn or error
+	Format string `json:"format" yaml:"format"` // json or text
+}
+
+type HTTPConfig struct {
+	MaxBodyBytes      int64    `json:"max_body_bytes" yaml:"max_body_bytes"`
+	ReadTimeout       Duration `json:"read_timeout" yaml:"read_timeout"`
+	ReadHeaderTimeout Duration `json:"read_header_timeout" yaml:"read_header_timeout"`
+	WriteTimeout      Duration `json:"write_timeout" yaml:"write_timeout"`
+	IdleTimeout       Duration `json:"idle_timeout" yaml:"idle_timeout"`
+	MaxHeaderBytes    int64    `json:"max_header_bytes" yaml:"max_header_bytes"`
+	ShutdownDelay     Duration `json:"shutdown_delay" yaml:"shutdown_delay"`     // How long to keep serving with /readyz failing before draining
+	ShutdownTimeout   Duration `json:"shutdown_timeout" yaml:"shutdown_timeout"` // How long in-flight requests and gRPC calls may take to drain after the delay
+}
+
+// GRPCConfig controls the gRPC service served next to the HTTP API.
+type GRPCConfig struct {
+	ListenAddr string `json:"listen_addr" yaml:"listen_addr"` // Empty disables the gRPC service
+}
+
+// LimitsConfig bounds the payloads accepted by every endpoint that takes
+// bookings. Zero disables a bound.
+type LimitsConfig struct {
+	MaxBookings     int64   `json:"max_bookings" yaml:"max_bookings"`
+	MaxNights       int64   `json:"max_nights" yaml:"max_nights"`
+	MaxDateSpanDays int64   `json:"max_date_span_days" yaml:"max_date_span_days"` // From the earliest check-in to the latest checkout
+	MaxSellingRate  float64 `json:"max_selling_rate" yaml:"max_selling_rate"`
+}
+
+// ComputeConfig bounds the optimization work done for a single request and
+// how many optimizations run at once.
+type ComputeConfig struct {
+	Budget        Duration `json:"budget" yaml:"budget"`                 // Give up optimizing after this long, 0 for no limit
+	MaxConcurrent int64    `json:"max_concurrent" yaml:"max_concurrent"` // 0 for one per CPU
+	MaxQueued     int64    `json:"max_queued" yaml:"max_queued"`         // Requests waiting for a slot before new ones get 429
+}
+
+// CacheConfig bounds the memory kept for /maximize and /stats results.
+type CacheConfig struct {
+	MaxBytes int64 `json:"max_bytes" yaml:"max_bytes"` // 0 disables the cache
+}
+
+// RateLimitConfig throttles each API key, or each client IP while the API is
+// open, with a token bucket.
+type RateLimitConfig struct {
+	RequestsPerSecond float64 `json:"requests_per_second" yaml:"requests_per_second"` // 0 disables rate limiting
+	Burst             int64   `json:"burst" yaml:"burst"`
+}
+
+// JSONConfig controls how request bodies are decoded. Strict decoding rejects
+// unknown fields and data after the JSON value.
+type JSONConfig struct {
+	Strict   bool `json:"strict" yaml:"strict"`       // Unversioned and /v1 routes
+	StrictV2 bool `json:"strict_v2" yaml:"strict_v2"` // /v2 routes
+}
+
+// IdempotencyConfig controls how long and how many Idempotency-Key
+// responses are kept.
+type IdempotencyConfig struct {
+	TTL      Duration `json:"ttl" yaml:"ttl"`             // 0 ignores Idempotency-Key
+	MaxBytes int64    `json:"max_bytes" yaml:"max_bytes"` // Oldest responses are forgotten beyond it
+}
+
+// JobsConfig sizes the background worker pool behind /jobs.
+type JobsConfig struct {
+	Workers   int64    `json:"workers" yaml:"workers"`
+	QueueSize int64    `json:"queue_size" yaml:"queue_size"`
+	TTL       Duration `json:"ttl" yaml:"ttl"`         // How long finished jobs can be retrieved
+	Timeout   Duration `json:"timeout" yaml:"timeout"` // Per-job time limit, 0 for none
+}
+
+// WebhooksConfig controls how events are delivered to registered webhooks.
+type WebhooksConfig struct {
+	Workers        int64    `json:"workers" yaml:"workers"`
+	QueueSize      int64    `json:"queue_size" yaml:"queue_size"`
+	MaxAttempts    int64    `json:"max_attempts" yaml:"max_attempts"`       // Including the first attempt
+	InitialBackoff Duration `json:"initial_backoff" yaml:"initial_backoff"` // Doubles after every failed attempt
+	MaxBackoff     Duration `json:"max_backoff" yaml:"max_backoff"`
+	Timeout        Duration `json:"timeout" yaml:"timeout"`   // Per attempt
+	LogSize        int64    `json:"log_size" yaml:"log_size"` // Delivery attempts kept per webhook
+	MaxPerTenant   int64    `json:"max_per_tenant" yaml:"max_per_tenant"`
+	// Lets webhooks reach loopback, private and link-local addresses; for
+	// development only
+	AllowPrivateNetworks bool `json:"allow_private_networks" yaml:"allow_private_networks"`
+}
+
+// AuthConfig controls API key authentication.
+type AuthConfig struct {
+	KeysFile string `json:"keys_file" yaml:"keys_file"` // See LoadAPIKeys; empty leaves the API open
+}
+
+// FeatureConfig toggles the optional endpoint groups. /maximize and /stats
+// are always served.
+type FeatureConfig struct {
+	Compare          bool `json:"compare" yaml:"compare"`
+	ScheduleValidate bool `json:"schedule_validate" yaml:"schedule_validate"`
+	Quote            bool `json:"quote" yaml:"quote"`
+	Calendars        bool `json:"calendars" yaml:"calendars"`
+	Jobs             bool `json:"jobs" yaml:"jobs"`
+	Webhooks         bool `json:"webhooks" yaml:"webhooks"`
+}
+
+// OverbookingConfig holds the defaults for stochastic /maximize requests.
+type OverbookingConfig struct {
+	MaxWalkProbability float64 `json:"max_walk_probability" yaml:"max_walk_probability"`
+	WalkPenalty        float64 `json:"walk_penalty" yaml:"walk_penalty"`
+}
+
+func Default() Config {
+	return Config{
+		ListenAddr: ":8080",
+		Log: LogConfig{
+			Level:  "info",
+			Format: "json",
+		},
+		HTTP: HTTPConfig{
+			MaxBodyBytes:      10 << 20,
+			ReadTimeout:       Duration(30 * time.Second),
+			ReadHeaderTimeout: Duration(5 * time.Second),
+			WriteTimeout:      Duration(60 * time.Second),
+			IdleTimeout:       Duration(120 * time.Second),
+			MaxHeaderBytes:    64 << 10,
+			ShutdownTimeout:   Duration(25 * time.Second),
+		},
+		Limits: LimitsConfig{
+			MaxBookings:     10000,
+			MaxNights:       365,
+			MaxDateSpanDays: 3 * 365,
+			MaxSellingRate:  1_000_000,
+		},
+		Compute: ComputeConfig{
+			Budget:    Duration(30 * time.Second),
+			MaxQueued: 64,
+		},
+		Cache: CacheConfig{
+			MaxBytes: 64 << 20,
+		},
+		RateLimit: RateLimitConfig{
+			RequestsPerSecond: 20,
+			Burst:             40,
+		},
+		JSON: JSONConfig{
+			StrictV2: true,
+		},
+		Idempotency: IdempotencyConfig{
+			TTL:      Duration(24 * time.Hour),
+			MaxBytes: 64 << 20,
+		},
+		Jobs: JobsConfig{
+			Workers:   2,
+			QueueSize: 100,
+			TTL:       Duration(time.Hour),
+			Timeout:   Duration(15 * time.Minute),
+		},
+		Webhooks: WebhooksConfig{
+			Workers:        4,
+			QueueSize:      1000,
+			MaxAttempts:    5,
+			InitialBackoff: Duration(time.Second),
+			MaxBackoff:     Duration(time.Minute),
+			Timeout:        Duration(10 * time.Second),
+			LogSize:        100,
+			MaxPerTenant:   100,
+		},
+		Features: FeatureConfig{
+			Compare:          true,
+			ScheduleValidate: true,
+			Quote:            true,
+			Calendars:        true,
+			Jobs:             true,
+			Webhooks:         true,
+		},
+	}
+}
+
+// setting binds one configuration key to its field. The key doubles as the
+// flag name (dots and underscores become dashes) and the environment
+// variable name (EnvPrefix plus the key upper-cased, dots become
+// underscores).
+type setting struct {
+	key   string
+	usage string
+	field func(*Config) any
+}
+
+var settings = []setting{
+	{"listen_addr", "address the HTTP server listens on", func(c *Config) any { return &c.ListenAddr }},
+	{"log.level", "log level: debug, info, warn or error", func(c *Config) any { return &c.Log.Level }},
+	{"log.format", "log format: json or text", func(c *Config) any { return &c.Log.Format }},
+	{"http.max_body_bytes", "maximum request body size in bytes", func(c *Config) any { return &c.HTTP.MaxBodyBytes }},
+	{"http.read_timeout", "maximum duration for reading a whole request", func(c *Config) any { return &c.HTTP.ReadTimeout }},
+	{"http.read_header_timeout", "maximum duration for reading request headers", func(c *Config) any { return &c.HTTP.ReadHeaderTimeout }},
+	{"http.write_timeout", "maximum duration before timing out a response write", func(c *Config) any { return &c.HTTP.WriteTimeout }},
+	{"http.idle_timeout", "maximum keep-alive idle time", func(c *Config) any { return &c.HTTP.IdleTimeout }},
+	{"http.max_header_bytes", "maximum size of request headers in bytes", func(c *Config) any { return &c.HTTP.MaxHeaderBytes }},
+	{"http.shutdown_delay", "how long to keep serving with /readyz failing before draining", func(c *Config) any { return &c.HTTP.ShutdownDelay }},
+	{"http.shutdown_timeout", "how long in-flight requests and gRPC calls may drain on SIGTERM/SIGINT, after http.shutdown_delay", func(c *Config) any { return &c.HTTP.ShutdownTimeout }},
+	{"grpc.listen_addr", "address the gRPC service listens on, empty to disable it", func(c *Config) any { return &c.GRPC.ListenAddr }},
+	{"limits.max_bookings", "maximum bookings per request, 0 for no limit", func(c *Config) any { return &c.Limits.MaxBookings }},
+	{"limits.max_nights", "maximum nights per booking, 0 for no limit", func(c *Config) any { return &c.Limits.MaxNights }},
+	{"limits.max_date_span_days", "maximum days between the earliest check-in and latest checkout of a request, 0 for no limit", func(c *Config) any { return &c.Limits.MaxDateSpanDays }},
+	{"limits.max_selling_rate", "maximum selling rate per booking, 0 for no limit", func(c *Config) any { return &c.Limits.MaxSellingRate }},
+	{"compute.budget", "how long a request may spend optimizing, 0 for no limit", func(c *Config) any { return &c.Compute.Budget }},
+	{"compute.max_concurrent", "optimizations run concurrently across all requests, 0 for one per CPU", func(c *Config) any { return &c.Compute.MaxConcurrent }},
+	{"compute.max_queued", "optimizations waiting for a free slot before new requests are answered with 429", func(c *Config) any { return &c.Compute.MaxQueued }},
+	{"cache.max_bytes", "memory for /maximize and /stats results kept for repeated payloads in bytes, 0 to disable caching", func(c *Config) any { return &c.Cache.MaxBytes }},
+	{"rate_limit.requests_per_second", "sustained requests per second allowed per API key or client IP, 0 for no limit", func(c *Config) any { return &c.RateLimit.RequestsPerSecond }},
+	{"rate_limit.burst", "requests a client may send at once above the sustained rate", func(c *Config) any { return &c.RateLimit.Burst }},
+	{"json.strict", "reject unknown fields and trailing data in request bodies on unversioned and /v1 routes", func(c *Config) any { return &c.JSON.Strict }},
+	{"json.strict_v2", "reject unknown fields and trailing data in request bodies on /v2 routes", func(c *Config) any { return &c.JSON.StrictV2 }},
+	{"idempotency.ttl", "how long responses to requests with an Idempotency-Key are replayed for retries, 0 to ignore the header", func(c *Config) any { return &c.Idempotency.TTL }},
+	{"idempotency.max_bytes", "memory for responses kept for Idempotency-Key retries in bytes; the oldest are forgotten beyond it", func(c *Config) any { return &c.Idempotency.MaxBytes }},
+	{"jobs.workers", "background optimizations run concurrently", func(c *Config) any { return &c.Jobs.Workers }},
+	{"jobs.queue_size", "background optimizations waiting for a worker before new ones are rejected", func(c *Config) any { return &c.Jobs.QueueSize }},
+	{"jobs.ttl", "how long finished background optimizations can be retrieved", func(c *Config) any { return &c.Jobs.TTL }},
+	{"jobs.timeout", "time limit per background optimization, 0 for none", func(c *Config) any { return &c.Jobs.Timeout }},
+	{"webhooks.workers", "webhook deliveries sent concurrently", func(c *Config) any { return &c.Webhooks.Workers }},
+	{"webhooks.queue_size", "webhook deliveries waiting for a worker before new ones are dropped", func(c *Config) any { return &c.Webhooks.QueueSize }},
+	{"webhooks.max_attempts", "attempts per webhook delivery, including the first", func(c *Config) any { return &c.Webhooks.MaxAttempts }},
+	{"webhooks.initial_backoff", "wait before retrying a failed webhook delivery, doubled on every retry", func(c *Config) any { return &c.Webhooks.InitialBackoff }},
+	{"webhooks.max_backoff", "longest wait between webhook delivery attempts", func(c *Config) any { return &c.Webhooks.MaxBackoff }},
+	{"webhooks.timeout", "time limit per webhook delivery attempt", func(c *Config) any { return &c.Webhooks.Timeout }},
+	{"webhooks.log_size", "delivery attempts kept per webhook", func(c *Config) any { return &c.Webhooks.LogSize }},
+	{"webhooks.max_per_tenant", "webhooks a tenant may register", func(c *Config) any { return &c.Webhooks.MaxPerTenant }},
+	{"webhooks.allow_private_networks", "let webhooks reach loopback, private and link-local addresses, for development against local receivers", func(c *Config) any { return &c.Webhooks.AllowPrivateNetworks }},
+	{"auth.keys_file", "YAML or JSON file mapping API keys to tenants; empty leaves the API open", func(c *Config) any { return &c.Auth.KeysFile }},
+	{"features.compare", "serve /compare", func(c *Config) any { return &c.Features.Compare }},
+	{"features.schedule_validate", "serve /schedule/validate", func(c *Config) any { return &c.Features.ScheduleValidate }},
+	{"features.quote", "serve /quote", func(c *Config) any { return &c.Features.Quote }},
+	{"features.calendars", "serve /calendars", func(c *Config) any { return &c.Features.Calendars }},
+	{"features.jobs", "serve /jobs", func(c *Config) any { return &c.Features.Jobs }},
+	{"features.webhooks", "serve /webhooks and deliver events to registered URLs", func(c *Config) any { return &c.Features.Webhooks }},
+	{"overbooking.max_walk_probability", "default risk limit for stochastic /maximize", func(c *Config) any { return &c.Overbooking.MaxWalkProbability }},
+	{"overbooking.walk_penalty", "default cost per walked guest for stochastic /maximize", func(c *Config) any { return &c.Overbooking.WalkPenalty }},
+}
+
+func (s setting) flagName() string {
+	return strings.NewReplacer(".", "-", "_", "-").Replace(s.key)
+}
+
+func (s setting) envName() string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(s.key, ".", "_"))
+}
+
+// Load builds the effective configuration. args are the command-line
+// arguments without the program name and getenv is usually os.Getenv.
+// The config file is named by the -config flag or the RENTAL_CONFIG
+// environment variable.
+func Load(args []string, getenv func(string) string) (Config, error) {
+	cfg := Default()
+
+	fs := flag.NewFlagSet("server", flag.ContinueOnError)
+	configPath := fs.String("config", getenv(EnvPrefix+"CONFIG"), "path to a YAML or JSON config file")
+	for _, s := range settings {
+		usage := fmt.Sprintf("%s (env %s)", s.usage, s.envName())
+		if _, ok := s.field(&cfg).(*bool); ok {
+			fs.Var(&boolFlag{value: formatValue(s.field(&cfg))}, s.flagName(), usage)
+		} else {
+			fs.String(s.flagName(), formatValue(s.field(&cfg)), usage)
+		}
+	}
+	if err := fs.Parse(args); err != nil {
+		return Config{}, err
+	}
+
+	if *configPath != "" {
+		if err := decodeFile(*configPath, "config file", &cfg); err != nil {
+			return Config{}, err
+		}
+	}
+
+	for _, s := range settings {
+		if value, ok := lookupEnv(getenv, s.envName()); ok {
+			if err := setValue(s.field(&cfg), value); err != nil {
+				return Config{}, fmt.Errorf("%w: %s: %w", ErrInvalidConfig, s.envName(), err)
+			}
+		}
+	}
+
+	var flagErr error
+	fs.Visit(func(f *flag.Flag) {
+		for _, s := range settings {
+			if flagErr == nil && f.Name == s.flagName() {
+				if err := setValue(s.field(&cfg), f.Value.String()); err != nil {
+					flagErr = fmt.Errorf("%w: -%s: %w", ErrInvalidConfig, f.Name, err)
+				}
+			}
+		}
+	})
+	if flagErr != nil {
+		return Config{}, flagErr
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// boolFlag is the flag of a bool setting. Like the flag package's own bool
+// flags it may be given without a value, as -json-strict, but it keeps the
+// raw value so Load parses it after the file and environment like any other.
+type boolFlag struct {
+	value string
+}
+
+func (f *boolFlag) String() string {
+	return f.value
+}
+
+func (f *boolFlag) Set(value string) error {
+	f.value = value
+	return nil
+}
+
+func (f *boolFlag) IsBoolFlag() bool {
+	return true
+}
+
+// lookupEnv treats empty variables as unset, since getenv cannot tell them
+// apart.
+func lookupEnv(getenv func(string) string, name string) (string, bool) {
+	value := getenv(name)
+	return value, value != ""
+}
+
+// decodeFile overlays the YAML or JSON file on target, rejecting keys it
+// does not know. kind names the file in errors.
+func decodeFile(path string, kind string, target any) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("%w: reading %s: %w", ErrInvalidConfig, kind, err)
+	}
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(content))
+		decoder.KnownFields(true)
+		err = decoder.Decode(target)
+	case ".json":
+		decoder := json.NewDecoder(bytes.NewReader(content))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(target)
+	default:
+		return fmt.Errorf("%w: %s %s must end in .yaml, .yml or .json", ErrInvalidConfig, kind, path)
+	}
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("%w: parsing %s %s: %w", ErrInvalidConfig, kind, path, err)
+	}
+	return nil
+}
+
+func setValue(field any, value string) error {
+	switch target := field.(type) {
+	case *string:
+		*target = value
+	case *bool:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		*target = parsed
+	case *int64:
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		*target = parsed
+	case *float64:
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		*target = parsed
+	case *Duration:
+		return target.UnmarshalText([]byte(value))
+	default:
+		return fmt.Errorf("unsupported setting type %T", field)
+	}
+	return nil
+}
+
+func formatValue(field any) string {
+	switch value := field.(type) {
+	case *string:
+		return *value
+	case *bool:
+		return strconv.FormatBool(*value)
+	case *int64:
+		return strconv.FormatInt(*value, 10)
+	case *float64:
+		return strconv.FormatFloat(*value, 'g', -1, 64)
+	case *Duration:
+		return value.String()
+	default:
+		return ""
+	}
+}
+
+// Validate reports the first setting that cannot be used.
+func (c Config) Validate() error {
+	if c.ListenAddr == "" {
+		return fmt.Errorf("%w: listen_addr must not be empty", ErrInvalidConfig)
+	}
+	if _, err := c.Log.SlogLevel(); err != nil {
+		return fmt.Errorf("%w: log.level: %w", ErrInvalidConfig, err)
+	}
+	if c.Log.Format != "json" && c.Log.Format != "text" {
+		return fmt.Errorf("%w: log.format must be json or text, got %q", ErrInvalidConfig, c.Log.Format)
+	}
+	if c.HTTP.MaxBodyBytes <= 0 {
+		return fmt.Errorf("%w: http.max_body_bytes must be positive", ErrInvalidConfig)
+	}
+	if c.HTTP.MaxHeaderBytes <= 0 {
+		return fmt.Errorf("%w: http.max_header_bytes must be positive", ErrInvalidConfig)
+	}
+	for name, timeout := range map[string]Duration{
+		"http.read_timeout":        c.HTTP.ReadTimeout,
+		"http.read_header_timeout": c.HTTP.ReadHeaderTimeout,
+		"http.write_timeout":       c.HTTP.WriteTimeout,
+		"http.idle_timeout":        c.HTTP.IdleTimeout,
+		"http.shutdown_delay":      c.HTTP.ShutdownDelay,
+		"http.shutdown_timeout":    c.HTTP.ShutdownTimeout,
+		"compute.budget":           c.Compute.Budget,
+		"idempotency.ttl":          c.Idempotency.TTL,
+		"jobs.timeout":             c.Jobs.Timeout,
+		"webhooks.initial_backoff": c.Webhooks.InitialBackoff,
+		"webhooks.max_backoff":     c.Webhooks.MaxBackoff,
+	} {
+		if timeout < 0 {
+			return fmt.Errorf("%w: %s must not be negative", ErrInvalidConfig, name)
+		}
+	}
+	if c.Idempotency.MaxBytes <= 0 {
+		return fmt.Errorf("%w: idempotency.max_bytes must be positive", ErrInvalidConfig)
+	}
+	if c.Jobs.Workers <= 0 {
+		return fmt.Errorf("%w: jobs.workers must be positive", ErrInvalidConfig)
+	}
+	if c.Jobs.QueueSize < 0 {
+		return fmt.Errorf("%w: jobs.queue_size must not be negative", ErrInvalidConfig)
+	}
+	if c.Jobs.TTL <= 0 {
+		return fmt.Errorf("%w: jobs.ttl must be positive", ErrInvalidConfig)
+	}
+	for name, value := range map[string]int64{
+		"webhooks.workers":        c.Webhooks.Workers,
+		"webhooks.max_attempts":   c.Webhooks.MaxAttempts,
+		"webhooks.timeout":        int64(c.Webhooks.Timeout),
+		"webhooks.log_size":       c.Webhooks.LogSize,
+		"webhooks.max_per_tenant": c.Webhooks.MaxPerTenant,
+	} {
+		if value <= 0 {
+			return fmt.Errorf("%w: %s must be positive", ErrInvalidConfig, name)
+		}
+	}
+	if c.Webhooks.QueueSize < 0 {
+		return fmt.Errorf("%w: webhooks.queue_size must not be negative", ErrInvalidConfig)
+	}
+	for name, limit := range map[string]float64{
+		"limits.max_bookings":            float64(c.Limits.MaxBookings),
+		"limits.max_nights":              float64(c.Limits.MaxNights),
+		"limits.max_date_span_days":      float64(c.Limits.MaxDateSpanDays),
+		"limits.max_selling_rate":        c.Limits.MaxSellingRate,
+		"compute.max_concurrent":         float64(c.Compute.MaxConcurrent),
+		"compute.max_queued":             float64(c.Compute.MaxQueued),
+		"cache.max_bytes":                float64(c.Cache.MaxBytes),
+		"rate_limit.requests_per_second": c.RateLimit.RequestsPerSecond,
+	} {
+		if limit < 0 {
+			return fmt.Errorf("%w: %s must not be negative", ErrInvalidConfig, name)
+		}
+	}
+	if c.RateLimit.RequestsPerSecond > 0 && c.RateLimit.Burst <= 0 {
+		return fmt.Errorf("%w: rate_limit.burst must be positive", ErrInvalidConfig)
+	}
+	if c.Overbooking.MaxWalkProbability < 0 || c.Overbooking.MaxWalkProbability > 1 {
+		return fmt.Errorf("%w: overbooking.max_walk_probability must be between 0 and 1", ErrInvalidConfig)
+	}
+	if c.Overbooking.WalkPenalty < 0 {
+		return fmt.Errorf("%w: overbooking.walk_penalty must not be negative", ErrInvalidConfig)
+	}
+	return nil
+}
+
+// LogValue lists every setting under the key used in files, so the
+// effective configuration can be logged at startup.
+func (c Config) LogValue() slog.Value {
+	attrs := make([]slog.Attr, len(settings))
+	for i, s := range settings {
+		attrs[i] = slog.String(s.key, formatValue(s.field(&c)))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+func (c LogConfig) SlogLevel() (slog.Level, error) {
+	var level slog.Level
+	err := level.UnmarshalText([]byte(c.Level))
+	return level, err
+}
+
+// Duration is a time.Duration written as a Go duration string ("30s") in
+// config files and the effective config dump.
+type Duration time.Duration
+
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
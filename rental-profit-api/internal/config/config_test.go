@@ -0,0 +1,258 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func envFrom(values map[string]string) func(string) string {
+	return func(name string) string {
+		return values[name]
+	}
+}
+
+func writeTestFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	return path
+}
+
+func TestLoadDefaults(t *testing.T) {
+	cfg, err := Load(nil, envFrom(nil))
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	if cfg != Default() {
+		t.Errorf("Load() = %+v, want defaults %+v", cfg, Default())
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	yamlPath := writeTestFile(t, "config.yaml", `
+listen_addr: ":9000"
+log:
+  level: debug
+http:
+  read_timeout: 10s
+features:
+  quote: false
+overbooking:
+  walk_penalty: 25
+`)
+
+	testCases := []struct {
+		name  string
+		args  []string
+		env   map[string]string
+		check func(t *testing.T, cfg Config)
+	}{
+		{
+			name: "File overrides defaults",
+			args: []string{"-config", yamlPath},
+			check: func(t *testing.T, cfg Config) {
+				if cfg.ListenAddr != ":9000" || cfg.Log.Level != "debug" || cfg.Features.Quote || cfg.Overbooking.WalkPenalty != 25 {
+					t.Errorf("file settings not applied: %+v", cfg)
+				}
+				if time.Duration(cfg.HTTP.ReadTimeout) != 10*time.Second {
+					t.Errorf("ReadTimeout = %v, want 10s", cfg.HTTP.ReadTimeout)
+				}
+				if cfg.Log.Format != "json" || !cfg.Features.Compare {
+					t.Errorf("settings missing from the file lost their defaults: %+v", cfg)
+				}
+			},
+		},
+		{
+			name: "Config path from environment",
+			env:  map[string]string{"RENTAL_CONFIG": yamlPath},
+			check: func(t *testing.T, cfg Config) {
+				if cfg.ListenAddr != ":9000" {
+					t.Errorf("ListenAddr = %q, want :9000", cfg.ListenAddr)
+				}
+			},
+		},
+		{
+			name: "Environment overrides file",
+			args: []string{"-config", yamlPath},
+			env:  map[string]string{"RENTAL_LISTEN_ADDR": ":9100", "RENTAL_FEATURES_QUOTE": "true"},
+			check: func(t *testing.T, cfg Config) {
+				if cfg.ListenAddr != ":9100" || !cfg.Features.Quote {
+					t.Errorf("environment settings not applied: %+v", cfg)
+				}
+			},
+		},
+		{
+			name: "Flags override environment",
+			args: []string{"-config", yamlPath, "-listen-addr", ":9200", "-http-idle-timeout", "1m"},
+			env:  map[string]string{"RENTAL_LISTEN_ADDR": ":9100"},
+			check: func(t *testing.T, cfg Config) {
+				if cfg.ListenAddr != ":9200" {
+					t.Errorf("ListenAddr = %q, want :9200", cfg.ListenAddr)
+				}
+				if time.Duration(cfg.HTTP.IdleTimeout) != time.Minute {
+					t.Errorf("IdleTimeout = %v, want 1m", cfg.HTTP.IdleTimeout)
+				}
+			},
+		},
+		{
+			name: "Bool flags without a value",
+			args: []string{"-config", yamlPath, "-json-strict", "-features-quote", "-features-compare=false"},
+			env:  map[string]string{"RENTAL_JSON_STRICT": "false"},
+			check: func(t *testing.T, cfg Config) {
+				if !cfg.JSON.Strict || !cfg.Features.Quote || cfg.Features.Compare {
+					t.Errorf("bool flags not applied: %+v", cfg)
+				}
+			},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := Load(tt.args, envFrom(tt.env))
+			if err != nil {
+				t.Fatalf("Load() unexpected error: %v", err)
+			}
+			tt.check(t, cfg)
+		})
+	}
+}
+
+func TestLoadJSONFile(t *testing.T) {
+	path := writeTestFile(t, "config.json", `{"log":{"format":"text"},"http":{"write_timeout":"2m"}}`)
+
+	cfg, err := Load([]string{"-config", path}, envFrom(nil))
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	if cfg.Log.Format != "text" || time.Duration(cfg.HTTP.WriteTimeout) != 2*time.Minute {
+		t.Errorf("JSON settings not applied: %+v", cfg)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	testCases := []struct {
+		name        string
+		args        []string
+		env         map[string]string
+		errContains string
+	}{
+		{
+			name:        "Unknown file key",
+			args:        []string{"-config", writeTestFile(t, "typo.yaml", "listen_adr: \":1\"\n")},
+			errContains: "listen_adr",
+		},
+		{
+			name:        "Unknown JSON key",
+			args:        []string{"-config", writeTestFile(t, "typo.json", `{"lsten_addr":":1"}`)},
+			errContains: "lsten_addr",
+		},
+		{
+			name:        "Unsupported file extension",
+			args:        []string{"-config", writeTestFile(t, "config.toml", "")},
+			errContains: "must end in .yaml, .yml or .json",
+		},
+		{
+			name:        "Missing file",
+			args:        []string{"-config", filepath.Join(t.TempDir(), "missing.yaml")},
+			errContains: "reading config file",
+		},
+		{
+			name:        "Malformed environment value",
+			env:         map[string]string{"RENTAL_HTTP_MAX_BODY_BYTES": "lots"},
+			errContains: "RENTAL_HTTP_MAX_BODY_BYTES",
+		},
+		{
+			name:        "Malformed flag value",
+			args:        []string{"-http-read-timeout", "soon"},
+			errContains: "-http-read-timeout",
+		},
+		{
+			name:        "Malformed bool flag value",
+			args:        []string{"-json-strict=maybe"},
+			errContains: "-json-strict",
+		},
+		{
+			name:        "Invalid log level",
+			args:        []string{"-log-level", "loud"},
+			errContains: "log.level",
+		},
+		{
+			name:        "Invalid log format",
+			args:        []string{"-log-format", "xml"},
+			errContains: "log.format must be json or text",
+		},
+		{
+			name:        "Non-positive body limit",
+			args:        []string{"-http-max-body-bytes", "0"},
+			errContains: "http.max_body_bytes must be positive",
+		},
+		{
+			name:        "Negative timeout",
+			args:        []string{"-http-write-timeout", "-1s"},
+			errContains: "http.write_timeout must not be negative",
+		},
+		{
+			name:        "Negative input limit",
+			env:         map[string]string{"RENTAL_LIMITS_MAX_NIGHTS": "-1"},
+			errContains: "limits.max_nights must not be negative",
+		},
+		{
+			name:        "No webhook delivery attempts",
+			args:        []string{"-webhooks-max-attempts", "0"},
+			errContains: "webhooks.max_attempts must be positive",
+		},
+		{
+			name:        "Negative compute queue",
+			args:        []string{"-compute-max-queued", "-1"},
+			errContains: "compute.max_queued must not be negative",
+		},
+		{
+			name:        "Negative cache size",
+			args:        []string{"-cache-max-bytes", "-1"},
+			errContains: "cache.max_bytes must not be negative",
+		},
+		{
+			name:        "Rate limit without burst",
+			args:        []string{"-rate-limit-requests-per-second", "5", "-rate-limit-burst", "0"},
+			errContains: "rate_limit.burst must be positive",
+		},
+		{
+			name:        "Negative idempotency TTL",
+			args:        []string{"-idempotency-ttl", "-1h"},
+			errContains: "idempotency.ttl must not be negative",
+		},
+		{
+			name:        "No idempotency memory",
+			args:        []string{"-idempotency-max-bytes", "0"},
+			errContains: "idempotency.max_bytes must be positive",
+		},
+		{
+			name:        "No webhooks per tenant",
+			args:        []string{"-webhooks-max-per-tenant", "0"},
+			errContains: "webhooks.max_per_tenant must be positive",
+		},
+		{
+			name:        "Risk limit out of range",
+			args:        []string{"-overbooking-max-walk-probability", "1.5"},
+			errContains: "overbooking.max_walk_probability must be between 0 and 1",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(tt.args, envFrom(tt.env))
+			if !errors.Is(err, ErrInvalidConfig) {
+				t.Fatalf("Load() error = %v, want %v", err, ErrInvalidConfig)
+			}
+			if !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Load() error = %q, want substring %q", err.Error(), tt.errContains)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+// Package idempotency remembers the outcome of requests by idempotency key,
+// so a client retrying a request it is unsure went through gets the original
+// response instead of repeating its effect.
+package idempotency
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrInProgress rejects a request while another one with the same key is
+// still being served.
+var ErrInProgress = errors.New("a request with this idempotency key is in progress")
+
+// ErrMismatch rejects a request reusing a key for a different request.
+var ErrMismatch = errors.New("idempotency key was used for a different request")
+
+// sweepInterval is how often Begin forgets expired keys.
+const sweepInterval = time.Minute
+
+// Fingerprint identifies a request, so a retry can be told apart from a
+// different request reusing its key.
+type Fingerprint [sha256.Size]byte
+
+// Response is a stored response, replayed for retries.
+type Response struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// Store keeps responses by tenant and key for a TTL. Keys of different
+// tenants never collide. Stored responses are bounded in total size: beyond
+// it the oldest ones are forgotten before their TTL.
+type Store struct {
+	ttl      time.Duration
+	maxBytes int64
+	now      func() time.Time
+
+	mu        sync.Mutex
+	entries   map[entryKey]*entry
+	completed *list.List // Of entryKey, oldest completion first
+	size      int64      // Of the completed entries
+	lastSweep time.Time
+}
+
+type entryKey struct {
+	tenant, key string
+}
+
+type entry struct {
+	fingerprint Fingerprint
+	response    *Response // nil while the first request is being served
+	expires     time.Time
+	element     *list.Element // In Store.completed once response is set
+	size        int64
+}
+
+// NewStore returns a store that remembers keys for ttl after their first
+// request, keeping responses of up to maxBytes in total.
+func NewStore(ttl time.Duration, maxBytes int64) *Store {
+	return &Store{
+		ttl:       ttl,
+		maxBytes:  maxBytes,
+		now:       time.Now,
+		entries:   make(map[entryKey]*entry),
+		completed: list.New(),
+	}
+}
+
+// Begin claims key for a request. For a key seen before it returns the
+// stored response, or ErrInProgress while that is not known yet, or
+// ErrMismatch if the request differs. Otherwise it returns nil and the
+// caller must call either Complete or Abandon once the request is served.
+func (s *Store) Begin(tenant, key string, fingerprint Fingerprint) (*Response, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	s.sweepLocked(now)
+
+	k := entryKey{tenant, key}
+	if e, ok := s.entries[k]; ok {
+		if !now.Before(e.expires) {
+			s.removeLocked(k)
+		} else {
+			switch {
+			case e.fingerprint != fingerprint:
+				return nil, ErrMismatch
+			case e.response == nil:
+				return nil, ErrInProgress
+			}
+			return e.response, nil
+		}
+	}
+	s.entries[k] = &entry{fingerprint: fingerprint, expires: now.Add(s.ttl)}
+	return nil, nil
+}
+
+// Complete stores the response to the request that claimed key, forgetting
+// the oldest stored responses until the total fits. A response larger than
+// the whole store is not kept, and its key is released as by Abandon.
+func (s *Store) Complete(tenant, key string, response Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k := entryKey{tenant, key}
+	e, ok := s.entries[k]
+	if !ok || e.response != nil {
+		return
+	}
+	e.size = responseSize(k, response)
+	if e.size > s.maxBytes {
+		delete(s.entries, k)
+		return
+	}
+	e.response = &response
+	e.element = s.completed.PushBack(k)
+	s.size += e.size
+	for s.size > s.maxBytes {
+		s.removeLocked(s.completed.Front().Value.(entryKey))
+	}
+}
+
+// Abandon releases key without storing a response, so the request can be
+// retried, for instance after a server error.
+func (s *Store) Abandon(tenant, key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k := entryKey{tenant, key}
+	if e, ok := s.entries[k]; ok && e.response == nil {
+		delete(s.entries, k)
+	}
+}
+
+// sweepLocked forgets expired keys. The caller must hold s.mu.
+func (s *Store) sweepLocked(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for k, e := range s.entries {
+		if !now.Before(e.expires) {
+			s.removeLocked(k)
+		}
+	}
+}
+
+// removeLocked forgets k. The caller must hold s.mu.
+func (s *Store) removeLocked(k entryKey) {
+	e := s.entries[k]
+	if e.element != nil {
+		s.completed.Remove(e.element)
+		s.size -= e.size
+	}
+	delete(s.entries, k)
+}
+
+// responseSize estimates the memory a stored response takes, in bytes.
+func responseSize(k entryKey, response Response) int64 {
+	size := len(k.tenant) + len(k.key) + len(response.Body)
+	for name, values := range response.Header {
+		size += len(name)
+		for _, value := range values {
+			size += len(value)
+		}
+	}
+	return int64(size)
+}
@@ -0,0 +1,125 @@
+package idempotency
+
+import (
+	"crypto/sha256"
+	"errors"
+	"testing"
+	"time"
+)
+
+const testTenant = "acme"
+
+func TestStore(t *testing.T) {
+	s := NewStore(time.Hour, 1<<20)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	first := sha256.Sum256([]byte("POST /calendars\n[]"))
+	other := sha256.Sum256([]byte("POST /calendars\n[{}]"))
+	created := Response{Status: 201, Body: []byte(`{"calendar_id":"c1"}`)}
+
+	// --- Define Test Scenarios ---
+	testCases := []struct {
+		name             string
+		advance          time.Duration
+		tenant           string
+		key              string
+		fingerprint      Fingerprint
+		then             func(tenant, key string) // Called after a successful Begin without a stored response
+		expectedResponse *Response
+		expectedErr      error
+	}{
+		{name: "First request", tenant: testTenant, key: "k1", fingerprint: first},
+		{name: "Retry while in progress", tenant: testTenant, key: "k1", fingerprint: first, expectedErr: ErrInProgress},
+		{name: "Other tenant, same key", tenant: "globex", key: "k1", fingerprint: first,
+			then: func(tenant, key string) { s.Abandon(tenant, key) }},
+		{name: "Different request while in progress", tenant: testTenant, key: "k2", fingerprint: first,
+			then: func(tenant, key string) { s.Complete(tenant, key, created) }},
+		{name: "Retry after completion", tenant: testTenant, key: "k2", fingerprint: first, expectedResponse: &created},
+		{name: "Key reused for another request", tenant: testTenant, key: "k2", fingerprint: other, expectedErr: ErrMismatch},
+		{name: "Abandoned key", tenant: testTenant, key: "k3", fingerprint: first,
+			then: func(tenant, key string) { s.Abandon(tenant, key) }},
+		{name: "Retry after abandon", tenant: testTenant, key: "k3", fingerprint: other,
+			then: func(tenant, key string) { s.Complete(tenant, key, created) }},
+		{name: "Retry after expiry", advance: time.Hour, tenant: testTenant, key: "k2", fingerprint: other},
+	}
+
+	// --- Execute Scenarios ---
+	for _, tc := range testCases {
+		now = now.Add(tc.advance)
+		response, err := s.Begin(tc.tenant, tc.key, tc.fingerprint)
+		if !errors.Is(err, tc.expectedErr) {
+			t.Errorf("%s: Begin() error = %v, want %v", tc.name, err, tc.expectedErr)
+			continue
+		}
+		if (response == nil) != (tc.expectedResponse == nil) || (response != nil && response.Status != tc.expectedResponse.Status) {
+			t.Errorf("%s: Begin() response = %+v, want %+v", tc.name, response, tc.expectedResponse)
+		}
+		if err == nil && response == nil && tc.then != nil {
+			tc.then(tc.tenant, tc.key)
+		}
+	}
+}
+
+func TestStoreForgetsExpiredKeys(t *testing.T) {
+	s := NewStore(time.Minute, 1<<20)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	s.Begin(testTenant, "old", Fingerprint{})
+	s.Complete(testTenant, "old", Response{Status: 200})
+	now = now.Add(2 * sweepInterval)
+	s.Begin(testTenant, "new", Fingerprint{})
+	if _, ok := s.entries[entryKey{testTenant, "old"}]; ok {
+		t.Errorf("expired key survived the sweep")
+	}
+	if _, ok := s.entries[entryKey{testTenant, "new"}]; !ok {
+		t.Errorf("new key is missing")
+	}
+}
+
+func TestStoreEvictsOldestResponses(t *testing.T) {
+	s := NewStore(time.Hour, 100)
+	body := make([]byte, 40)
+
+	s.Begin(testTenant, "k1", Fingerprint{})
+	s.Complete(testTenant, "k1", Response{Status: 201, Body: body})
+	s.Begin(testTenant, "k2", Fingerprint{})
+	s.Complete(testTenant, "k2", Response{Status: 201, Body: body})
+	s.Begin(testTenant, "pending", Fingerprint{})
+	s.Begin(testTenant, "k3", Fingerprint{})
+	s.Complete(testTenant, "k3", Response{Status: 201, Body: body})
+
+	// --- Define Test Scenarios ---
+	testCases := []struct {
+		key            string
+		expectedKept   bool
+		expectedStatus int // Of the kept response, 0 while in progress
+	}{
+		{key: "k1", expectedKept: false},
+		{key: "k2", expectedKept: true, expectedStatus: 201},
+		{key: "pending", expectedKept: true},
+		{key: "k3", expectedKept: true, expectedStatus: 201},
+	}
+
+	// --- Execute Scenarios ---
+	for _, tc := range testCases {
+		e, ok := s.entries[entryKey{testTenant, tc.key}]
+		if ok != tc.expectedKept {
+			t.Errorf("%s: kept = %t, want %t", tc.key, ok, tc.expectedKept)
+			continue
+		}
+		if ok && (e.response == nil) != (tc.expectedStatus == 0) {
+			t.Errorf("%s: response = %+v, want status %d", tc.key, e.response, tc.expectedStatus)
+		}
+	}
+	if s.size > 100 {
+		t.Errorf("size = %d, want at most 100", s.size)
+	}
+
+	s.Begin(testTenant, "huge", Fingerprint{})
+	s.Complete(testTenant, "huge", Response{Status: 201, Body: make([]byte, 200)})
+	if response, err := s.Begin(testTenant, "huge", Fingerprint{}); response != nil || err != nil {
+		t.Errorf("Begin() after an oversized response = %+v, %v, want the key released", response, err)
+	}
+}
@@ -0,0 +1,264 @@
+// Package jobs runs long computations in the background on a bounded pool
+// of workers and keeps their outcome in memory for a while so clients can
+// poll for it.
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"rental-profit-api/internal/store"
+)
+
+// ErrQueueFull rejects a submission while every worker is busy and the
+// queue is at capacity.
+var ErrQueueFull = errors.New("job queue is full")
+
+// ErrClosed rejects a submission after Close.
+var ErrClosed = errors.New("job manager is closed")
+
+type Status string
+
+const (
+	StatusQueued    Status = "queued"
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+)
+
+// Func is the work of one job. It should return promptly once ctx is done
+// and may call report with its progress, from 0 to 1.
+type Func[T any] func(ctx context.Context, report func(progress float64)) (T, error)
+
+// Job is a point-in-time copy of a job's state.
+type Job[T any] struct {
+	ID         string
+	Owner      string // Only the owner can retrieve the job
+	Status     Status
+	Progress   float64 // From 0 to 1
+	Result     T       // Set once the job succeeded
+	Err        error   // Set once the job failed
+	CreatedAt  time.Time
+	StartedAt  time.Time
+	FinishedAt time.Time
+}
+
+// Done reports whether the job has finished, successfully or not.
+func (j Job[T]) Done() bool {
+	return j.Status == StatusSucceeded || j.Status == StatusFailed
+}
+
+// Options configures a Manager.
+type Options struct {
+	Workers   int           // Jobs run concurrently
+	QueueSize int           // Jobs waiting for a worker before Submit fails
+	TTL       time.Duration // How long finished jobs stay retrievable
+	Timeout   time.Duration // Per-job time limit, 0 for none
+}
+
+// Manager queues jobs, runs them on a fixed number of workers and forgets
+// finished jobs once their TTL has passed.
+type Manager[T any] struct {
+	options Options
+	now     func() time.Time
+
+	mu          sync.Mutex
+	jobs        map[string]*entry[T]
+	outstanding int // Jobs queued or running
+	closed      bool
+	onDone      func(Job[T])
+
+	queue  chan *entry[T]
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+type entry[T any] struct {
+	job Job[T]
+	fn  Func[T]
+}
+
+// NewManager starts the workers. Call Close to stop them.
+func NewManager[T any](options Options) *Manager[T] {
+	options.Workers = max(options.Workers, 1)
+	options.QueueSize = max(options.QueueSize, 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m := &Manager[T]{
+		options: options,
+		now:     time.Now,
+		jobs:    make(map[string]*entry[T]),
+		queue:   make(chan *entry[T], options.Workers+options.QueueSize),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+	m.wg.Add(options.Workers)
+	for range options.Workers {
+		go m.work()
+	}
+	return m
+}
+
+// OnDone registers fn to be called from the worker with every job once it
+// has finished and its final state is visible through Get.
+func (m *Manager[T]) OnDone(fn func(Job[T])) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onDone = fn
+}
+
+// Submit enqueues fn on behalf of owner and returns the queued job.
+func (m *Manager[T]) Submit(owner string, fn Func[T]) (Job[T], error) {
+	id, err := store.NewID()
+	if err != nil {
+		return Job[T]{}, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return Job[T]{}, ErrClosed
+	}
+	m.expireLocked()
+	if m.outstanding >= m.options.Workers+m.options.QueueSize {
+		return Job[T]{}, ErrQueueFull
+	}
+
+	e := &entry[T]{
+		job: Job[T]{ID: id, Owner: owner, Status: StatusQueued, CreatedAt: m.now()},
+		fn:  fn,
+	}
+	// The channel has room for every outstanding job, so this never blocks
+	m.queue <- e
+	m.outstanding++
+	m.jobs[id] = e
+	return e.job, nil
+}
+
+// Get returns the owner's job with the given ID, or store.ErrNotFound if it
+// never existed, has expired or belongs to someone else.
+func (m *Manager[T]) Get(owner, id string) (Job[T], error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.expireLocked()
+	e, ok := m.jobs[id]
+	if !ok || e.job.Owner != owner {
+		return Job[T]{}, store.ErrNotFound
+	}
+	return e.job, nil
+}
+
+// Close stops accepting jobs, cancels the running ones and waits for the
+// workers to exit. Jobs still queued are marked as failed.
+func (m *Manager[T]) Close() {
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return
+	}
+	m.closed = true
+	close(m.queue)
+	m.mu.Unlock()
+
+	m.cancel()
+	m.wg.Wait()
+}
+
+// Ping reports whether the manager still accepts jobs, for readiness checks.
+func (m *Manager[T]) Ping(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return ErrClosed
+	}
+	return ctx.Err()
+}
+
+func (m *Manager[T]) work() {
+	defer m.wg.Done()
+	for e := range m.queue {
+		m.run(e)
+	}
+}
+
+func (m *Manager[T]) run(e *entry[T]) {
+	m.update(e, func(job *Job[T]) {
+		job.Status = StatusRunning
+		job.StartedAt = m.now()
+	})
+
+	ctx := m.ctx
+	if m.options.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.options.Timeout)
+		defer cancel()
+	}
+	report := func(progress float64) {
+		m.update(e, func(job *Job[T]) {
+			job.Progress = min(max(progress, job.Progress), 1)
+		})
+	}
+
+	var result T
+	err := ctx.Err()
+	if err == nil {
+		result, err = m.call(ctx, e.fn, report)
+	}
+
+	var finished Job[T]
+	var onDone func(Job[T])
+	m.update(e, func(job *Job[T]) {
+		m.outstanding--
+		job.FinishedAt = m.now()
+		if err != nil {
+			job.Status = StatusFailed
+			job.Err = err
+		} else {
+			job.Status = StatusSucceeded
+			job.Progress = 1
+			job.Result = result
+		}
+		finished, onDone = *job, m.onDone
+	})
+	if onDone != nil {
+		onDone(finished)
+	}
+}
+
+// call runs fn, turning a panic into an error so one bad job cannot take
+// down the worker.
+func (m *Manager[T]) call(ctx context.Context, fn Func[T], report func(float64)) (result T, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			slog.Error("Panic while running job", "panic", recovered, "stack", string(debug.Stack()))
+			err = fmt.Errorf("job panicked: %v", recovered)
+		}
+	}()
+	return fn(ctx, report)
+}
+
+func (m *Manager[T]) update(e *entry[T], change func(*Job[T])) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	change(&e.job)
+}
+
+// expireLocked forgets finished jobs older than the TTL. The caller must
+// hold m.mu.
+func (m *Manager[T]) expireLocked() {
+	if m.options.TTL <= 0 {
+		return
+	}
+	cutoff := m.now().Add(-m.options.TTL)
+	for id, e := range m.jobs {
+		if e.job.Done() && e.job.FinishedAt.Before(cutoff) {
+			delete(m.jobs, id)
+		}
+	}
+}
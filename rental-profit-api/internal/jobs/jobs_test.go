@@ -0,0 +1,270 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"rental-profit-api/internal/store"
+)
+
+// testOwner owns every job submitted by these tests.
+const testOwner = "acme"
+
+// waitDone polls until the job has finished.
+func waitDone[T any](t *testing.T, m *Manager[T], id string) Job[T] {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		job, err := m.Get(testOwner, id)
+		if err != nil {
+			t.Fatalf("Get(%q) unexpected error: %v", id, err)
+		}
+		if job.Done() {
+			return job
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("job %s did not finish in time", id)
+	return Job[T]{}
+}
+
+func TestManagerRunsJobs(t *testing.T) {
+	m := NewManager[int](Options{Workers: 2, QueueSize: 4, TTL: time.Hour})
+	defer m.Close()
+
+	// --- Define Test Scenarios ---
+	testCases := []struct {
+		name             string
+		fn               Func[int]
+		expectedStatus   Status
+		expectedResult   int
+		expectedProgress float64
+		errContains      string
+	}{
+		{
+			name: "Success",
+			fn: func(ctx context.Context, report func(float64)) (int, error) {
+				report(0.5)
+				return 42, nil
+			},
+			expectedStatus:   StatusSucceeded,
+			expectedResult:   42,
+			expectedProgress: 1,
+		},
+		{
+			name: "Failure keeps the reported progress",
+			fn: func(ctx context.Context, report func(float64)) (int, error) {
+				report(0.25)
+				return 0, errors.New("boom")
+			},
+			expectedStatus:   StatusFailed,
+			expectedProgress: 0.25,
+			errContains:      "boom",
+		},
+		{
+			name: "Panic",
+			fn: func(ctx context.Context, report func(float64)) (int, error) {
+				panic("bad input")
+			},
+			expectedStatus: StatusFailed,
+			errContains:    "job panicked: bad input",
+		},
+	}
+
+	// --- Execute Scenarios ---
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			queued, err := m.Submit(testOwner, tt.fn)
+			if err != nil {
+				t.Fatalf("Submit() unexpected error: %v", err)
+			}
+			if queued.Status != StatusQueued || queued.CreatedAt.IsZero() {
+				t.Errorf("Submit() = %+v, want a queued job", queued)
+			}
+
+			job := waitDone(t, m, queued.ID)
+			if job.Status != tt.expectedStatus {
+				t.Errorf("Status = %q, want %q", job.Status, tt.expectedStatus)
+			}
+			if job.Result != tt.expectedResult {
+				t.Errorf("Result = %v, want %v", job.Result, tt.expectedResult)
+			}
+			if job.Progress != tt.expectedProgress {
+				t.Errorf("Progress = %v, want %v", job.Progress, tt.expectedProgress)
+			}
+			if tt.errContains != "" && (job.Err == nil || !strings.Contains(job.Err.Error(), tt.errContains)) {
+				t.Errorf("Err = %v, want substring %q", job.Err, tt.errContains)
+			}
+			if job.StartedAt.IsZero() || job.FinishedAt.Before(job.StartedAt) {
+				t.Errorf("unexpected timestamps: started %v, finished %v", job.StartedAt, job.FinishedAt)
+			}
+		})
+	}
+}
+
+func TestManagerQueueFull(t *testing.T) {
+	m := NewManager[int](Options{Workers: 1, QueueSize: 1, TTL: time.Hour})
+	defer m.Close()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	blocking := func(ctx context.Context, report func(float64)) (int, error) {
+		close(started)
+		<-release
+		return 1, nil
+	}
+	idle := func(ctx context.Context, report func(float64)) (int, error) { return 2, nil }
+
+	running, err := m.Submit(testOwner, blocking)
+	if err != nil {
+		t.Fatalf("Submit() unexpected error: %v", err)
+	}
+	<-started
+	queued, err := m.Submit(testOwner, idle)
+	if err != nil {
+		t.Fatalf("Submit() unexpected error: %v", err)
+	}
+	if _, err := m.Submit(testOwner, idle); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Submit() on a full queue error = %v, want %v", err, ErrQueueFull)
+	}
+
+	close(release)
+	waitDone(t, m, running.ID)
+	if job := waitDone(t, m, queued.ID); job.Result != 2 {
+		t.Errorf("queued job Result = %v, want 2", job.Result)
+	}
+}
+
+func TestManagerTimeout(t *testing.T) {
+	m := NewManager[int](Options{Workers: 1, TTL: time.Hour, Timeout: 10 * time.Millisecond})
+	defer m.Close()
+
+	queued, err := m.Submit(testOwner, func(ctx context.Context, report func(float64)) (int, error) {
+		<-ctx.Done()
+		return 0, ctx.Err()
+	})
+	if err != nil {
+		t.Fatalf("Submit() unexpected error: %v", err)
+	}
+	if job := waitDone(t, m, queued.ID); !errors.Is(job.Err, context.DeadlineExceeded) {
+		t.Errorf("Err = %v, want %v", job.Err, context.DeadlineExceeded)
+	}
+}
+
+func TestManagerExpiresFinishedJobs(t *testing.T) {
+	m := NewManager[int](Options{Workers: 1, TTL: time.Minute})
+	defer m.Close()
+
+	var mu sync.Mutex
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	m.now = func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}
+
+	queued, err := m.Submit(testOwner, func(ctx context.Context, report func(float64)) (int, error) { return 1, nil })
+	if err != nil {
+		t.Fatalf("Submit() unexpected error: %v", err)
+	}
+	waitDone(t, m, queued.ID)
+
+	mu.Lock()
+	now = now.Add(59 * time.Second)
+	mu.Unlock()
+	if _, err := m.Get(testOwner, queued.ID); err != nil {
+		t.Errorf("Get() before the TTL unexpected error: %v", err)
+	}
+
+	mu.Lock()
+	now = now.Add(2 * time.Second)
+	mu.Unlock()
+	if _, err := m.Get(testOwner, queued.ID); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("Get() after the TTL error = %v, want %v", err, store.ErrNotFound)
+	}
+}
+
+func TestManagerClose(t *testing.T) {
+	m := NewManager[int](Options{Workers: 1, QueueSize: 1, TTL: time.Hour})
+
+	started := make(chan struct{})
+	running, err := m.Submit(testOwner, func(ctx context.Context, report func(float64)) (int, error) {
+		close(started)
+		<-ctx.Done()
+		return 0, ctx.Err()
+	})
+	if err != nil {
+		t.Fatalf("Submit() unexpected error: %v", err)
+	}
+	<-started
+	queued, err := m.Submit(testOwner, func(ctx context.Context, report func(float64)) (int, error) { return 1, nil })
+	if err != nil {
+		t.Fatalf("Submit() unexpected error: %v", err)
+	}
+
+	m.Close()
+
+	for _, id := range []string{running.ID, queued.ID} {
+		job, err := m.Get(testOwner, id)
+		if err != nil {
+			t.Fatalf("Get() unexpected error: %v", err)
+		}
+		if job.Status != StatusFailed || !errors.Is(job.Err, context.Canceled) {
+			t.Errorf("job %s = %q with %v, want failed with %v", id, job.Status, job.Err, context.Canceled)
+		}
+	}
+	if _, err := m.Submit(testOwner, func(ctx context.Context, report func(float64)) (int, error) { return 1, nil }); !errors.Is(err, ErrClosed) {
+		t.Errorf("Submit() after Close() error = %v, want %v", err, ErrClosed)
+	}
+	if err := m.Ping(context.Background()); !errors.Is(err, ErrClosed) {
+		t.Errorf("Ping() after Close() error = %v, want %v", err, ErrClosed)
+	}
+}
+
+func TestManagerOnDone(t *testing.T) {
+	m := NewManager[int](Options{Workers: 1, TTL: time.Hour})
+	defer m.Close()
+
+	finished := make(chan Job[int], 1)
+	m.OnDone(func(job Job[int]) {
+		// The final state must already be visible to pollers
+		if polled, err := m.Get(testOwner, job.ID); err != nil || polled.Status != job.Status {
+			t.Errorf("Get() from OnDone = %q, %v, want %q", polled.Status, err, job.Status)
+		}
+		finished <- job
+	})
+
+	queued, err := m.Submit(testOwner, func(ctx context.Context, report func(float64)) (int, error) { return 7, nil })
+	if err != nil {
+		t.Fatalf("Submit() unexpected error: %v", err)
+	}
+	select {
+	case job := <-finished:
+		if job.ID != queued.ID || job.Status != StatusSucceeded || job.Result != 7 {
+			t.Errorf("OnDone got %+v, want job %s succeeded with 7", job, queued.ID)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("OnDone was not called in time")
+	}
+}
+
+func TestManagerIsolatesOwners(t *testing.T) {
+	m := NewManager[int](Options{Workers: 1, TTL: time.Hour})
+	defer m.Close()
+
+	queued, err := m.Submit(testOwner, func(ctx context.Context, report func(float64)) (int, error) { return 1, nil })
+	if err != nil {
+		t.Fatalf("Submit() unexpected error: %v", err)
+	}
+	if queued.Owner != testOwner {
+		t.Errorf("Owner = %q, want %q", queued.Owner, testOwner)
+	}
+	if _, err := m.Get("globex", queued.ID); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("Get() by another owner error = %v, want %v", err, store.ErrNotFound)
+	}
+	waitDone(t, m, queued.ID)
+}
@@ -0,0 +1,289 @@
+// Package metrics is a minimal Prometheus instrumentation library: labelled
+// counters and histograms plus a writer for the text exposition format.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefBuckets are the Prometheus default latency buckets, in seconds.
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// collector is a metric family that can write itself in text format.
+type collector interface {
+	write(w io.Writer) error
+}
+
+// Registry holds the metric families exposed by one /metrics endpoint.
+type Registry struct {
+	mu         sync.Mutex
+	collectors []collector
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Default is the registry used by the package level constructors.
+var Default = NewRegistry()
+
+func (r *Registry) register(c collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.collectors = append(r.collectors, c)
+}
+
+// WriteText writes every registered family in the Prometheus text format,
+// in registration order.
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	collectors := slices.Clone(r.collectors)
+	r.mu.Unlock()
+	for _, c := range collectors {
+		if err := c.write(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Handler serves the registry in the Prometheus text format.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		r.WriteText(w)
+	})
+}
+
+// family is the state shared by counters and histograms: a name, help text
+// and one series per distinct combination of label values.
+type family[S any] struct {
+	name   string
+	help   string
+	kind   string
+	labels []string
+
+	mu     sync.Mutex
+	series map[string]*S
+	values map[string][]string
+	newS   func() *S
+}
+
+func newFamily[S any](name, help, kind string, labels []string, newS func() *S) *family[S] {
+	return &family[S]{
+		name:   name,
+		help:   help,
+		kind:   kind,
+		labels: labels,
+		series: make(map[string]*S),
+		values: make(map[string][]string),
+		newS:   newS,
+	}
+}
+
+// with returns the series for labelValues, creating it on first use. The
+// caller must hold f.mu.
+func (f *family[S]) with(labelValues []string) *S {
+	if len(labelValues) != len(f.labels) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", f.name, len(f.labels), len(labelValues)))
+	}
+	key := strings.Join(labelValues, "\xff")
+	s, ok := f.series[key]
+	if !ok {
+		s = f.newS()
+		f.series[key] = s
+		f.values[key] = slices.Clone(labelValues)
+	}
+	return s
+}
+
+// sortedKeys returns the series keys ordered by label values so the output
+// is stable between scrapes. The caller must hold f.mu.
+func (f *family[S]) sortedKeys() []string {
+	keys := make([]string, 0, len(f.series))
+	for key := range f.series {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func (f *family[S]) writeHeader(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", f.name, escapeHelp(f.help), f.name, f.kind)
+	return err
+}
+
+// labelPairs renders label values as {a="x",b="y"}, with extra appended
+// verbatim for the histogram le label.
+func (f *family[S]) labelPairs(labelValues []string, extra string) string {
+	pairs := make([]string, 0, len(labelValues)+1)
+	for i, value := range labelValues {
+		pairs = append(pairs, f.labels[i]+`="`+escapeLabelValue(value)+`"`)
+	}
+	if extra != "" {
+		pairs = append(pairs, extra)
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// CounterVec is a monotonically increasing value per label combination.
+type CounterVec struct {
+	*family[float64]
+}
+
+// NewCounterVec creates a counter family and registers it with Default.
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	return Default.NewCounterVec(name, help, labels...)
+}
+
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{newFamily(name, help, "counter", labels, func() *float64 { return new(float64) })}
+	r.register(c)
+	return c
+}
+
+// Inc adds one to the series for labelValues.
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds delta, which must not be negative, to the series for labelValues.
+func (c *CounterVec) Add(delta float64, labelValues ...string) {
+	if delta < 0 {
+		panic(fmt.Sprintf("metrics: counter %s cannot decrease", c.name))
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	*c.with(labelValues) += delta
+}
+
+// Value returns the current value of the series for labelValues.
+func (c *CounterVec) Value(labelValues ...string) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return *c.with(labelValues)
+}
+
+func (c *CounterVec) write(w io.Writer) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.writeHeader(w); err != nil {
+		return err
+	}
+	for _, key := range c.sortedKeys() {
+		if _, err := fmt.Fprintf(w, "%s%s %s\n", c.name, c.labelPairs(c.values[key], ""), formatFloat(*c.series[key])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// histogramSeries counts observations per bucket, non-cumulatively.
+type histogramSeries struct {
+	bucketCounts []uint64
+	count        uint64
+	sum          float64
+}
+
+// HistogramVec samples observations into buckets per label combination.
+type HistogramVec struct {
+	*family[histogramSeries]
+	buckets []float64 // Upper bounds, sorted and without +Inf
+}
+
+// NewHistogramVec creates a histogram family and registers it with Default.
+func NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	return Default.NewHistogramVec(name, help, buckets, labels...)
+}
+
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	buckets = slices.Clone(buckets)
+	slices.Sort(buckets)
+	buckets = slices.DeleteFunc(buckets, func(bound float64) bool { return math.IsInf(bound, 1) })
+	h := &HistogramVec{
+		family: newFamily(name, help, "histogram", labels, func() *histogramSeries {
+			return &histogramSeries{bucketCounts: make([]uint64, len(buckets))}
+		}),
+		buckets: buckets,
+	}
+	r.register(h)
+	return h
+}
+
+// Observe records value in the series for labelValues.
+func (h *HistogramVec) Observe(value float64, labelValues ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s := h.with(labelValues)
+	if i, _ := slices.BinarySearch(h.buckets, value); i < len(h.buckets) {
+		s.bucketCounts[i]++
+	}
+	s.count++
+	s.sum += value
+}
+
+// Count returns how many values the series for labelValues has observed.
+func (h *HistogramVec) Count(labelValues ...string) uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.with(labelValues).count
+}
+
+func (h *HistogramVec) write(w io.Writer) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err := h.writeHeader(w); err != nil {
+		return err
+	}
+	for _, key := range h.sortedKeys() {
+		s, labelValues := h.series[key], h.values[key]
+		var cumulative uint64
+		for i, bound := range h.buckets {
+			cumulative += s.bucketCounts[i]
+			le := `le="` + formatFloat(bound) + `"`
+			if _, err := fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, h.labelPairs(labelValues, le), cumulative); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, h.labelPairs(labelValues, `le="+Inf"`), s.count); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "%s_sum%s %s\n", h.name, h.labelPairs(labelValues, ""), formatFloat(s.sum)); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "%s_count%s %d\n", h.name, h.labelPairs(labelValues, ""), s.count); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func formatFloat(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+func escapeHelp(help string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(help)
+}
+
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
@@ -0,0 +1,86 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegistryWriteText(t *testing.T) {
+	registry := NewRegistry()
+	requests := registry.NewCounterVec("requests_total", "Requests served.", "route", "status")
+	latency := registry.NewHistogramVec("latency_seconds", "Request latency.", []float64{1, 0.1}, "route")
+	registry.NewCounterVec("empty_total", "Never incremented.")
+
+	requests.Inc("/maximize", "200")
+	requests.Inc("/maximize", "200")
+	requests.Add(0.5, `a"b\c`+"\n", "500")
+	latency.Observe(0.05, "/maximize")
+	latency.Observe(0.5, "/maximize")
+	latency.Observe(2, "/maximize")
+
+	var output strings.Builder
+	if err := registry.WriteText(&output); err != nil {
+		t.Fatalf("WriteText returned error: %v", err)
+	}
+
+	expected := `# HELP requests_total Requests served.
+# TYPE requests_total counter
+requests_total{route="/maximize",status="200"} 2
+requests_total{route="a\"b\\c\n",status="500"} 0.5
+# HELP latency_seconds Request latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{route="/maximize",le="0.1"} 1
+latency_seconds_bucket{route="/maximize",le="1"} 2
+latency_seconds_bucket{route="/maximize",le="+Inf"} 3
+latency_seconds_sum{route="/maximize"} 2.55
+latency_seconds_count{route="/maximize"} 3
+# HELP empty_total Never incremented.
+# TYPE empty_total counter
+`
+	if output.String() != expected {
+		t.Errorf("unexpected exposition:\ngot:\n%s\nwant:\n%s", output.String(), expected)
+	}
+}
+
+func TestHistogramBucketBoundsAreInclusive(t *testing.T) {
+	registry := NewRegistry()
+	histogram := registry.NewHistogramVec("size", "Size.", []float64{0, 10})
+	histogram.Observe(0)
+	histogram.Observe(10)
+	histogram.Observe(11)
+
+	var output strings.Builder
+	registry.WriteText(&output)
+	for _, line := range []string{`size_bucket{le="0"} 1`, `size_bucket{le="10"} 2`, `size_bucket{le="+Inf"} 3`} {
+		if !strings.Contains(output.String(), line) {
+			t.Errorf("exposition missing %q:\n%s", line, output.String())
+		}
+	}
+}
+
+func TestLabelCountMismatchPanics(t *testing.T) {
+	counter := NewRegistry().NewCounterVec("requests_total", "Requests served.", "route")
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic for missing label values")
+		}
+	}()
+	counter.Inc()
+}
+
+func TestRegistryHandler(t *testing.T) {
+	registry := NewRegistry()
+	registry.NewCounterVec("requests_total", "Requests served.").Inc()
+
+	recorder := httptest.NewRecorder()
+	registry.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if contentType := recorder.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "text/plain; version=0.0.4") {
+		t.Errorf("unexpected content type %q", contentType)
+	}
+	if !strings.Contains(recorder.Body.String(), "requests_total 1\n") {
+		t.Errorf("unexpected body %q", recorder.Body.String())
+	}
+}
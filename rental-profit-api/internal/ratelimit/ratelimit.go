@@ -0,0 +1,146 @@
+// Package ratelimit throttles clients with per-key token buckets and caps
+// concurrent work with a semaphore that queues a bounded number of waiters.
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+)
+
+// ErrQueueFull rejects an Acquire while the semaphore already has as many
+// waiters as it queues.
+var ErrQueueFull = errors.New("too many waiting for a slot")
+
+// sweepInterval is how often Allow forgets idle buckets.
+const sweepInterval = time.Minute
+
+// Limiter keeps one token bucket per key. Each bucket holds up to burst
+// tokens and refills at rate tokens per second; every allowed request takes
+// one.
+type Limiter struct {
+	rate  float64
+	burst float64
+	now   func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewLimiter returns a limiter allowing rate requests per second per key,
+// with bursts of up to burst requests. burst is raised to 1 if lower.
+func NewLimiter(rate float64, burst int) *Limiter {
+	return &Limiter{
+		rate:    rate,
+		burst:   float64(max(burst, 1)),
+		now:     time.Now,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// Allow takes a token from key's bucket. When the bucket is empty it
+// reports how long until the next token is available instead.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	l.sweepLocked(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	wait := (1 - b.tokens) / l.rate
+	return false, time.Duration(math.Ceil(wait * float64(time.Second)))
+}
+
+// sweepLocked forgets buckets that have refilled completely, since a fresh
+// bucket behaves the same. The caller must hold l.mu.
+func (l *Limiter) sweepLocked(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// Semaphore lets a fixed number of holders in at a time and makes a bounded
+// number of others wait for a slot.
+type Semaphore struct {
+	slots      chan struct{}
+	maxWaiting int
+
+	mu      sync.Mutex
+	waiting int
+}
+
+// NewSemaphore returns a semaphore with limit slots (at least 1) that queues
+// up to maxWaiting callers of Acquire.
+func NewSemaphore(limit, maxWaiting int) *Semaphore {
+	return &Semaphore{
+		slots:      make(chan struct{}, max(limit, 1)),
+		maxWaiting: max(maxWaiting, 0),
+	}
+}
+
+// Acquire takes a slot, waiting for one if the queue has room and failing
+// with ErrQueueFull otherwise. It gives up with ctx's cause once ctx is
+// done. Call release once finished.
+func (s *Semaphore) Acquire(ctx context.Context) (release func(), err error) {
+	select {
+	case s.slots <- struct{}{}:
+		return s.release, nil
+	default:
+	}
+
+	s.mu.Lock()
+	if s.waiting >= s.maxWaiting {
+		s.mu.Unlock()
+		return nil, ErrQueueFull
+	}
+	s.waiting++
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.waiting--
+		s.mu.Unlock()
+	}()
+
+	return s.Wait(ctx)
+}
+
+// Wait takes a slot, waiting as long as it takes regardless of the queue
+// limit. It is meant for callers already throttled elsewhere, such as a
+// bounded pool of background workers.
+func (s *Semaphore) Wait(ctx context.Context) (release func(), err error) {
+	select {
+	case s.slots <- struct{}{}:
+		return s.release, nil
+	case <-ctx.Done():
+		return nil, context.Cause(ctx)
+	}
+}
+
+func (s *Semaphore) release() {
+	<-s.slots
+}
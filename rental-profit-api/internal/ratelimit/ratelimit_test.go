@@ -0,0 +1,121 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLimiterAllow(t *testing.T) {
+	l := NewLimiter(2, 3)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	l.now = func() time.Time { return now }
+
+	// --- Define Test Scenarios ---
+	testCases := []struct {
+		name          string
+		advance       time.Duration
+		key           string
+		expectedAllow bool
+		expectedWait  time.Duration
+	}{
+		{name: "Burst 1", key: "a", expectedAllow: true},
+		{name: "Burst 2", key: "a", expectedAllow: true},
+		{name: "Burst 3", key: "a", expectedAllow: true},
+		{name: "Bucket empty", key: "a", expectedAllow: false, expectedWait: 500 * time.Millisecond},
+		{name: "Other key has its own bucket", key: "b", expectedAllow: true},
+		{name: "Partly refilled", advance: 200 * time.Millisecond, key: "a", expectedAllow: false, expectedWait: 300 * time.Millisecond},
+		{name: "Refilled one token", advance: 300 * time.Millisecond, key: "a", expectedAllow: true},
+		{name: "Empty again", key: "a", expectedAllow: false, expectedWait: 500 * time.Millisecond},
+		{name: "Refill stops at burst", advance: time.Hour, key: "a", expectedAllow: true},
+		{name: "Burst 2 after refill", key: "a", expectedAllow: true},
+		{name: "Burst 3 after refill", key: "a", expectedAllow: true},
+		{name: "Empty after burst", key: "a", expectedAllow: false, expectedWait: 500 * time.Millisecond},
+	}
+
+	// --- Execute Scenarios ---
+	for _, tc := range testCases {
+		now = now.Add(tc.advance)
+		allowed, wait := l.Allow(tc.key)
+		if allowed != tc.expectedAllow || wait != tc.expectedWait {
+			t.Errorf("%s: Allow(%q) = %v, %v, want %v, %v", tc.name, tc.key, allowed, wait, tc.expectedAllow, tc.expectedWait)
+		}
+	}
+}
+
+func TestLimiterForgetsIdleBuckets(t *testing.T) {
+	l := NewLimiter(1, 1)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	l.now = func() time.Time { return now }
+
+	l.Allow("idle")
+	now = now.Add(2 * sweepInterval)
+	l.Allow("active")
+	if _, ok := l.buckets["idle"]; ok {
+		t.Errorf("bucket of an idle key survived the sweep")
+	}
+	if _, ok := l.buckets["active"]; !ok {
+		t.Errorf("bucket of the active key is missing")
+	}
+}
+
+func TestSemaphore(t *testing.T) {
+	s := NewSemaphore(1, 1)
+
+	release, err := s.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Acquire() unexpected error: %v", err)
+	}
+
+	// The second caller queues
+	acquired := make(chan func(), 1)
+	go func() {
+		queuedRelease, err := s.Acquire(context.Background())
+		if err != nil {
+			t.Errorf("queued Acquire() unexpected error: %v", err)
+			return
+		}
+		acquired <- queuedRelease
+	}()
+	waitFor(t, func() bool {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return s.waiting == 1
+	})
+
+	// The queue is full, but Wait ignores the queue limit
+	if _, err := s.Acquire(context.Background()); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Acquire() with a full queue error = %v, want %v", err, ErrQueueFull)
+	}
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cause := errors.New("gave up")
+	cancel(cause)
+	if _, err := s.Wait(ctx); !errors.Is(err, cause) {
+		t.Errorf("Wait() with a cancelled context error = %v, want %v", err, cause)
+	}
+
+	release()
+	select {
+	case queuedRelease := <-acquired:
+		queuedRelease()
+	case <-time.After(5 * time.Second):
+		t.Fatal("queued Acquire() did not get the released slot")
+	}
+	if release, err := s.Acquire(context.Background()); err != nil {
+		t.Errorf("Acquire() after all releases unexpected error: %v", err)
+	} else {
+		release()
+	}
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
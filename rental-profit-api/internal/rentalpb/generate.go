@@ -0,0 +1,5 @@
+// Package rentalpb holds the Go code generated from
+// proto/rental/v1/rental.proto for the gRPC service.
+package rentalpb
+
+//go:generate protoc -I ../../proto --go_out=../.. --go_opt=module=rental-profit-api --go-grpc_out=../.. --go-grpc_opt=module=rental-profit-api rental/v1/rental.proto
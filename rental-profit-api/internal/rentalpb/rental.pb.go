@@ -0,0 +1,635 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: rental/v1/rental.proto
+
+// The gRPC counterpart of POST /maximize and POST /stats. Requests are
+// validated, limited and optimized exactly like the JSON API; authenticate
+// with the same API keys in the x-api-key or authorization metadata.
+
+package rentalpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Booking struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	RequestId         string                 `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	CheckIn           string                 `protobuf:"bytes,2,opt,name=check_in,json=checkIn,proto3" json:"check_in,omitempty"` // YYYY-MM-DD
+	Nights            int32                  `protobuf:"varint,3,opt,name=nights,proto3" json:"nights,omitempty"`
+	SellingRate       float64                `protobuf:"fixed64,4,opt,name=selling_rate,json=sellingRate,proto3" json:"selling_rate,omitempty"`
+	Margin            float64                `protobuf:"fixed64,5,opt,name=margin,proto3" json:"margin,omitempty"` // Percentage of the selling rate kept as profit
+	CancelProbability float64                `protobuf:"fixed64,6,opt,name=cancel_probability,json=cancelProbability,proto3" json:"cancel_probability,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *Booking) Reset() {
+	*x = Booking{}
+	mi := &file_rental_v1_rental_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Booking) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Booking) ProtoMessage() {}
+
+func (x *Booking) ProtoReflect() protoreflect.Message {
+	mi := &file_rental_v1_rental_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Booking.ProtoReflect.Descriptor instead.
+func (*Booking) Descriptor() ([]byte, []int) {
+	return file_rental_v1_rental_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Booking) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *Booking) GetCheckIn() string {
+	if x != nil {
+		return x.CheckIn
+	}
+	return ""
+}
+
+func (x *Booking) GetNights() int32 {
+	if x != nil {
+		return x.Nights
+	}
+	return 0
+}
+
+func (x *Booking) GetSellingRate() float64 {
+	if x != nil {
+		return x.SellingRate
+	}
+	return 0
+}
+
+func (x *Booking) GetMargin() float64 {
+	if x != nil {
+		return x.Margin
+	}
+	return 0
+}
+
+func (x *Booking) GetCancelProbability() float64 {
+	if x != nil {
+		return x.CancelProbability
+	}
+	return 0
+}
+
+type MaximizeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Bookings      []*Booking             `protobuf:"bytes,1,rep,name=bookings,proto3" json:"bookings,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MaximizeRequest) Reset() {
+	*x = MaximizeRequest{}
+	mi := &file_rental_v1_rental_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MaximizeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MaximizeRequest) ProtoMessage() {}
+
+func (x *MaximizeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rental_v1_rental_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MaximizeRequest.ProtoReflect.Descriptor instead.
+func (*MaximizeRequest) Descriptor() ([]byte, []int) {
+	return file_rental_v1_rental_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *MaximizeRequest) GetBookings() []*Booking {
+	if x != nil {
+		return x.Bookings
+	}
+	return nil
+}
+
+type MaximizeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RequestIds    []string               `protobuf:"bytes,1,rep,name=request_ids,json=requestIds,proto3" json:"request_ids,omitempty"`
+	TotalProfit   float64                `protobuf:"fixed64,2,opt,name=total_profit,json=totalProfit,proto3" json:"total_profit,omitempty"`
+	AvgNight      float64                `protobuf:"fixed64,3,opt,name=avg_night,json=avgNight,proto3" json:"avg_night,omitempty"`
+	MinNight      float64                `protobuf:"fixed64,4,opt,name=min_night,json=minNight,proto3" json:"min_night,omitempty"`
+	MaxNight      float64                `protobuf:"fixed64,5,opt,name=max_night,json=maxNight,proto3" json:"max_night,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MaximizeResponse) Reset() {
+	*x = MaximizeResponse{}
+	mi := &file_rental_v1_rental_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MaximizeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MaximizeResponse) ProtoMessage() {}
+
+func (x *MaximizeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rental_v1_rental_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MaximizeResponse.ProtoReflect.Descriptor instead.
+func (*MaximizeResponse) Descriptor() ([]byte, []int) {
+	return file_rental_v1_rental_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *MaximizeResponse) GetRequestIds() []string {
+	if x != nil {
+		return x.RequestIds
+	}
+	return nil
+}
+
+func (x *MaximizeResponse) GetTotalProfit() float64 {
+	if x != nil {
+		return x.TotalProfit
+	}
+	return 0
+}
+
+func (x *MaximizeResponse) GetAvgNight() float64 {
+	if x != nil {
+		return x.AvgNight
+	}
+	return 0
+}
+
+func (x *MaximizeResponse) GetMinNight() float64 {
+	if x != nil {
+		return x.MinNight
+	}
+	return 0
+}
+
+func (x *MaximizeResponse) GetMaxNight() float64 {
+	if x != nil {
+		return x.MaxNight
+	}
+	return 0
+}
+
+type StatsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Bookings      []*Booking             `protobuf:"bytes,1,rep,name=bookings,proto3" json:"bookings,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StatsRequest) Reset() {
+	*x = StatsRequest{}
+	mi := &file_rental_v1_rental_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatsRequest) ProtoMessage() {}
+
+func (x *StatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rental_v1_rental_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatsRequest.ProtoReflect.Descriptor instead.
+func (*StatsRequest) Descriptor() ([]byte, []int) {
+	return file_rental_v1_rental_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *StatsRequest) GetBookings() []*Booking {
+	if x != nil {
+		return x.Bookings
+	}
+	return nil
+}
+
+type StatsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AvgNight      float64                `protobuf:"fixed64,1,opt,name=avg_night,json=avgNight,proto3" json:"avg_night,omitempty"`
+	MinNight      float64                `protobuf:"fixed64,2,opt,name=min_night,json=minNight,proto3" json:"min_night,omitempty"`
+	MaxNight      float64                `protobuf:"fixed64,3,opt,name=max_night,json=maxNight,proto3" json:"max_night,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StatsResponse) Reset() {
+	*x = StatsResponse{}
+	mi := &file_rental_v1_rental_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatsResponse) ProtoMessage() {}
+
+func (x *StatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rental_v1_rental_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatsResponse.ProtoReflect.Descriptor instead.
+func (*StatsResponse) Descriptor() ([]byte, []int) {
+	return file_rental_v1_rental_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *StatsResponse) GetAvgNight() float64 {
+	if x != nil {
+		return x.AvgNight
+	}
+	return 0
+}
+
+func (x *StatsResponse) GetMinNight() float64 {
+	if x != nil {
+		return x.MinNight
+	}
+	return 0
+}
+
+func (x *StatsResponse) GetMaxNight() float64 {
+	if x != nil {
+		return x.MaxNight
+	}
+	return 0
+}
+
+type MaximizeBatchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Requests      []*MaximizeRequest     `protobuf:"bytes,1,rep,name=requests,proto3" json:"requests,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MaximizeBatchRequest) Reset() {
+	*x = MaximizeBatchRequest{}
+	mi := &file_rental_v1_rental_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MaximizeBatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MaximizeBatchRequest) ProtoMessage() {}
+
+func (x *MaximizeBatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rental_v1_rental_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MaximizeBatchRequest.ProtoReflect.Descriptor instead.
+func (*MaximizeBatchRequest) Descriptor() ([]byte, []int) {
+	return file_rental_v1_rental_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *MaximizeBatchRequest) GetRequests() []*MaximizeRequest {
+	if x != nil {
+		return x.Requests
+	}
+	return nil
+}
+
+type MaximizeBatchResult struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Index int32                  `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"` // Of the request in MaximizeBatchRequest.requests
+	// Types that are valid to be assigned to Outcome:
+	//
+	//	*MaximizeBatchResult_Response
+	//	*MaximizeBatchResult_Error
+	Outcome       isMaximizeBatchResult_Outcome `protobuf_oneof:"outcome"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MaximizeBatchResult) Reset() {
+	*x = MaximizeBatchResult{}
+	mi := &file_rental_v1_rental_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MaximizeBatchResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MaximizeBatchResult) ProtoMessage() {}
+
+func (x *MaximizeBatchResult) ProtoReflect() protoreflect.Message {
+	mi := &file_rental_v1_rental_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MaximizeBatchResult.ProtoReflect.Descriptor instead.
+func (*MaximizeBatchResult) Descriptor() ([]byte, []int) {
+	return file_rental_v1_rental_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *MaximizeBatchResult) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *MaximizeBatchResult) GetOutcome() isMaximizeBatchResult_Outcome {
+	if x != nil {
+		return x.Outcome
+	}
+	return nil
+}
+
+func (x *MaximizeBatchResult) GetResponse() *MaximizeResponse {
+	if x != nil {
+		if x, ok := x.Outcome.(*MaximizeBatchResult_Response); ok {
+			return x.Response
+		}
+	}
+	return nil
+}
+
+func (x *MaximizeBatchResult) GetError() *Error {
+	if x != nil {
+		if x, ok := x.Outcome.(*MaximizeBatchResult_Error); ok {
+			return x.Error
+		}
+	}
+	return nil
+}
+
+type isMaximizeBatchResult_Outcome interface {
+	isMaximizeBatchResult_Outcome()
+}
+
+type MaximizeBatchResult_Response struct {
+	Response *MaximizeResponse `protobuf:"bytes,2,opt,name=response,proto3,oneof"`
+}
+
+type MaximizeBatchResult_Error struct {
+	Error *Error `protobuf:"bytes,3,opt,name=error,proto3,oneof"`
+}
+
+func (*MaximizeBatchResult_Response) isMaximizeBatchResult_Outcome() {}
+
+func (*MaximizeBatchResult_Error) isMaximizeBatchResult_Outcome() {}
+
+// Error reports why one request of a batch failed, as the status the
+// equivalent unary call would have ended with.
+type Error struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          int32                  `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"` // A google.rpc.Code
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Error) Reset() {
+	*x = Error{}
+	mi := &file_rental_v1_rental_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Error) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Error) ProtoMessage() {}
+
+func (x *Error) ProtoReflect() protoreflect.Message {
+	mi := &file_rental_v1_rental_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Error.ProtoReflect.Descriptor instead.
+func (*Error) Descriptor() ([]byte, []int) {
+	return file_rental_v1_rental_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Error) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *Error) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+var File_rental_v1_rental_proto protoreflect.FileDescriptor
+
+const file_rental_v1_rental_proto_rawDesc = "" +
+	"\n" +
+	"\x16rental/v1/rental.proto\x12\trental.v1\"\xc5\x01\n" +
+	"\aBooking\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x01 \x01(\tR\trequestId\x12\x19\n" +
+	"\bcheck_in\x18\x02 \x01(\tR\acheckIn\x12\x16\n" +
+	"\x06nights\x18\x03 \x01(\x05R\x06nights\x12!\n" +
+	"\fselling_rate\x18\x04 \x01(\x01R\vsellingRate\x12\x16\n" +
+	"\x06margin\x18\x05 \x01(\x01R\x06margin\x12-\n" +
+	"\x12cancel_probability\x18\x06 \x01(\x01R\x11cancelProbability\"A\n" +
+	"\x0fMaximizeRequest\x12.\n" +
+	"\bbookings\x18\x01 \x03(\v2\x12.rental.v1.BookingR\bbookings\"\xad\x01\n" +
+	"\x10MaximizeResponse\x12\x1f\n" +
+	"\vrequest_ids\x18\x01 \x03(\tR\n" +
+	"requestIds\x12!\n" +
+	"\ftotal_profit\x18\x02 \x01(\x01R\vtotalProfit\x12\x1b\n" +
+	"\tavg_night\x18\x03 \x01(\x01R\bavgNight\x12\x1b\n" +
+	"\tmin_night\x18\x04 \x01(\x01R\bminNight\x12\x1b\n" +
+	"\tmax_night\x18\x05 \x01(\x01R\bmaxNight\">\n" +
+	"\fStatsRequest\x12.\n" +
+	"\bbookings\x18\x01 \x03(\v2\x12.rental.v1.BookingR\bbookings\"f\n" +
+	"\rStatsResponse\x12\x1b\n" +
+	"\tavg_night\x18\x01 \x01(\x01R\bavgNight\x12\x1b\n" +
+	"\tmin_night\x18\x02 \x01(\x01R\bminNight\x12\x1b\n" +
+	"\tmax_night\x18\x03 \x01(\x01R\bmaxNight\"N\n" +
+	"\x14MaximizeBatchRequest\x126\n" +
+	"\brequests\x18\x01 \x03(\v2\x1a.rental.v1.MaximizeRequestR\brequests\"\x9b\x01\n" +
+	"\x13MaximizeBatchResult\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x05R\x05index\x129\n" +
+	"\bresponse\x18\x02 \x01(\v2\x1b.rental.v1.MaximizeResponseH\x00R\bresponse\x12(\n" +
+	"\x05error\x18\x03 \x01(\v2\x10.rental.v1.ErrorH\x00R\x05errorB\t\n" +
+	"\aoutcome\"5\n" +
+	"\x05Error\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\x05R\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage2\xe4\x01\n" +
+	"\rRentalService\x12C\n" +
+	"\bMaximize\x12\x1a.rental.v1.MaximizeRequest\x1a\x1b.rental.v1.MaximizeResponse\x12:\n" +
+	"\x05Stats\x12\x17.rental.v1.StatsRequest\x1a\x18.rental.v1.StatsResponse\x12R\n" +
+	"\rMaximizeBatch\x12\x1f.rental.v1.MaximizeBatchRequest\x1a\x1e.rental.v1.MaximizeBatchResult0\x01B%Z#rental-profit-api/internal/rentalpbb\x06proto3"
+
+var (
+	file_rental_v1_rental_proto_rawDescOnce sync.Once
+	file_rental_v1_rental_proto_rawDescData []byte
+)
+
+func file_rental_v1_rental_proto_rawDescGZIP() []byte {
+	file_rental_v1_rental_proto_rawDescOnce.Do(func() {
+		file_rental_v1_rental_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_rental_v1_rental_proto_rawDesc), len(file_rental_v1_rental_proto_rawDesc)))
+	})
+	return file_rental_v1_rental_proto_rawDescData
+}
+
+var file_rental_v1_rental_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_rental_v1_rental_proto_goTypes = []any{
+	(*Booking)(nil),              // 0: rental.v1.Booking
+	(*MaximizeRequest)(nil),      // 1: rental.v1.MaximizeRequest
+	(*MaximizeResponse)(nil),     // 2: rental.v1.MaximizeResponse
+	(*StatsRequest)(nil),         // 3: rental.v1.StatsRequest
+	(*StatsResponse)(nil),        // 4: rental.v1.StatsResponse
+	(*MaximizeBatchRequest)(nil), // 5: rental.v1.MaximizeBatchRequest
+	(*MaximizeBatchResult)(nil),  // 6: rental.v1.MaximizeBatchResult
+	(*Error)(nil),                // 7: rental.v1.Error
+}
+var file_rental_v1_rental_proto_depIdxs = []int32{
+	0, // 0: rental.v1.MaximizeRequest.bookings:type_name -> rental.v1.Booking
+	0, // 1: rental.v1.StatsRequest.bookings:type_name -> rental.v1.Booking
+	1, // 2: rental.v1.MaximizeBatchRequest.requests:type_name -> rental.v1.MaximizeRequest
+	2, // 3: rental.v1.MaximizeBatchResult.response:type_name -> rental.v1.MaximizeResponse
+	7, // 4: rental.v1.MaximizeBatchResult.error:type_name -> rental.v1.Error
+	1, // 5: rental.v1.RentalService.Maximize:input_type -> rental.v1.MaximizeRequest
+	3, // 6: rental.v1.RentalService.Stats:input_type -> rental.v1.StatsRequest
+	5, // 7: rental.v1.RentalService.MaximizeBatch:input_type -> rental.v1.MaximizeBatchRequest
+	2, // 8: rental.v1.RentalService.Maximize:output_type -> rental.v1.MaximizeResponse
+	4, // 9: rental.v1.RentalService.Stats:output_type -> rental.v1.StatsResponse
+	6, // 10: rental.v1.RentalService.MaximizeBatch:output_type -> rental.v1.MaximizeBatchResult
+	8, // [8:11] is the sub-list for method output_type
+	5, // [5:8] is the sub-list for method input_type
+	5, // [5:5] is the sub-list for extension type_name
+	5, // [5:5] is the sub-list for extension extendee
+	0, // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_rental_v1_rental_proto_init() }
+func file_rental_v1_rental_proto_init() {
+	if File_rental_v1_rental_proto != nil {
+		return
+	}
+	file_rental_v1_rental_proto_msgTypes[6].OneofWrappers = []any{
+		(*MaximizeBatchResult_Response)(nil),
+		(*MaximizeBatchResult_Error)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_rental_v1_rental_proto_rawDesc), len(file_rental_v1_rental_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_rental_v1_rental_proto_goTypes,
+		DependencyIndexes: file_rental_v1_rental_proto_depIdxs,
+		MessageInfos:      file_rental_v1_rental_proto_msgTypes,
+	}.Build()
+	File_rental_v1_rental_proto = out.File
+	file_rental_v1_rental_proto_goTypes = nil
+	file_rental_v1_rental_proto_depIdxs = nil
+}
@@ -0,0 +1,215 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: rental/v1/rental.proto
+
+// The gRPC counterpart of POST /maximize and POST /stats. Requests are
+// validated, limited and optimized exactly like the JSON API; authenticate
+// with the same API keys in the x-api-key or authorization metadata.
+
+package rentalpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	RentalService_Maximize_FullMethodName      = "/rental.v1.RentalService/Maximize"
+	RentalService_Stats_FullMethodName         = "/rental.v1.RentalService/Stats"
+	RentalService_MaximizeBatch_FullMethodName = "/rental.v1.RentalService/MaximizeBatch"
+)
+
+// RentalServiceClient is the client API for RentalService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type RentalServiceClient interface {
+	// Maximize finds the most profitable set of non-overlapping bookings.
+	Maximize(ctx context.Context, in *MaximizeRequest, opts ...grpc.CallOption) (*MaximizeResponse, error)
+	// Stats reports profit per night statistics across all bookings.
+	Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsResponse, error)
+	// MaximizeBatch optimizes several independent booking lists, streaming
+	// one result per list as it is ready. A list that fails does not end the
+	// stream; its result carries the error instead.
+	MaximizeBatch(ctx context.Context, in *MaximizeBatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[MaximizeBatchResult], error)
+}
+
+type rentalServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewRentalServiceClient(cc grpc.ClientConnInterface) RentalServiceClient {
+	return &rentalServiceClient{cc}
+}
+
+func (c *rentalServiceClient) Maximize(ctx context.Context, in *MaximizeRequest, opts ...grpc.CallOption) (*MaximizeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MaximizeResponse)
+	err := c.cc.Invoke(ctx, RentalService_Maximize_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rentalServiceClient) Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StatsResponse)
+	err := c.cc.Invoke(ctx, RentalService_Stats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rentalServiceClient) MaximizeBatch(ctx context.Context, in *MaximizeBatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[MaximizeBatchResult], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &RentalService_ServiceDesc.Streams[0], RentalService_MaximizeBatch_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[MaximizeBatchRequest, MaximizeBatchResult]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type RentalService_MaximizeBatchClient = grpc.ServerStreamingClient[MaximizeBatchResult]
+
+// RentalServiceServer is the server API for RentalService service.
+// All implementations must embed UnimplementedRentalServiceServer
+// for forward compatibility.
+type RentalServiceServer interface {
+	// Maximize finds the most profitable set of non-overlapping bookings.
+	Maximize(context.Context, *MaximizeRequest) (*MaximizeResponse, error)
+	// Stats reports profit per night statistics across all bookings.
+	Stats(context.Context, *StatsRequest) (*StatsResponse, error)
+	// MaximizeBatch optimizes several independent booking lists, streaming
+	// one result per list as it is ready. A list that fails does not end the
+	// stream; its result carries the error instead.
+	MaximizeBatch(*MaximizeBatchRequest, grpc.ServerStreamingServer[MaximizeBatchResult]) error
+	mustEmbedUnimplementedRentalServiceServer()
+}
+
+// UnimplementedRentalServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedRentalServiceServer struct{}
+
+func (UnimplementedRentalServiceServer) Maximize(context.Context, *MaximizeRequest) (*MaximizeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Maximize not implemented")
+}
+func (UnimplementedRentalServiceServer) Stats(context.Context, *StatsRequest) (*StatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Stats not implemented")
+}
+func (UnimplementedRentalServiceServer) MaximizeBatch(*MaximizeBatchRequest, grpc.ServerStreamingServer[MaximizeBatchResult]) error {
+	return status.Errorf(codes.Unimplemented, "method MaximizeBatch not implemented")
+}
+func (UnimplementedRentalServiceServer) mustEmbedUnimplementedRentalServiceServer() {}
+func (UnimplementedRentalServiceServer) testEmbeddedByValue()                       {}
+
+// UnsafeRentalServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to RentalServiceServer will
+// result in compilation errors.
+type UnsafeRentalServiceServer interface {
+	mustEmbedUnimplementedRentalServiceServer()
+}
+
+func RegisterRentalServiceServer(s grpc.ServiceRegistrar, srv RentalServiceServer) {
+	// If the following call pancis, it indicates UnimplementedRentalServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&RentalService_ServiceDesc, srv)
+}
+
+func _RentalService_Maximize_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MaximizeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RentalServiceServer).Maximize(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RentalService_Maximize_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RentalServiceServer).Maximize(ctx, req.(*MaximizeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RentalService_Stats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RentalServiceServer).Stats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RentalService_Stats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RentalServiceServer).Stats(ctx, req.(*StatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RentalService_MaximizeBatch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(MaximizeBatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RentalServiceServer).MaximizeBatch(m, &grpc.GenericServerStream[MaximizeBatchRequest, MaximizeBatchResult]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type RentalService_MaximizeBatchServer = grpc.ServerStreamingServer[MaximizeBatchResult]
+
+// RentalService_ServiceDesc is the grpc.ServiceDesc for RentalService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var RentalService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "rental.v1.RentalService",
+	HandlerType: (*RentalServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Maximize",
+			Handler:    _RentalService_Maximize_Handler,
+		},
+		{
+			MethodName: "Stats",
+			Handler:    _RentalService_Stats_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "MaximizeBatch",
+			Handler:       _RentalService_MaximizeBatch_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "rental/v1/rental.proto",
+}
@@ -0,0 +1,113 @@
+package store
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+
+	"rental-profit-api/internal/booking"
+)
+
+var ErrNotFound = errors.New("not found")
+
+// CalendarStore keeps calendars in memory, keyed by a generated ID. Access
+// to each calendar goes through View and Update so callers never touch a
+// calendar without holding its lock. Every calendar has a lock of its own,
+// so a long change to one calendar does not hold up the others.
+//
+// Every calendar belongs to the tenant that created it. The other methods
+// take the caller's tenant and report another tenant's calendars as
+// ErrNotFound, so their IDs cannot even be probed.
+type CalendarStore struct {
+	mu        sync.RWMutex
+	calendars map[string]*tenantCalendar
+}
+
+type tenantCalendar struct {
+	tenant string
+
+	mu       sync.RWMutex
+	calendar *booking.Calendar
+}
+
+func NewCalendarStore() *CalendarStore {
+	return &CalendarStore{calendars: make(map[string]*tenantCalendar)}
+}
+
+// Ping reports whether the store can serve requests. The in-memory store
+// always can; it exists so readiness checks treat every backend alike.
+func (s *CalendarStore) Ping(ctx context.Context) error {
+	return ctx.Err()
+}
+
+// Create stores the calendar for tenant and returns its new ID.
+func (s *CalendarStore) Create(tenant string, calendar *booking.Calendar) (string, error) {
+	id, err := NewID()
+	if err != nil {
+		return "", err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calendars[id] = &tenantCalendar{tenant: tenant, calendar: calendar}
+	return id, nil
+}
+
+// View runs fn with read access to the calendar.
+func (s *CalendarStore) View(tenant, id string, fn func(*booking.Calendar) error) error {
+	stored, err := s.lookup(tenant, id)
+	if err != nil {
+		return err
+	}
+	stored.mu.RLock()
+	defer stored.mu.RUnlock()
+	return fn(stored.calendar)
+}
+
+// Update runs fn with exclusive access to the calendar. A calendar deleted
+// meanwhile is still handed to fn, but the change is lost with it.
+func (s *CalendarStore) Update(tenant, id string, fn func(*booking.Calendar) error) error {
+	stored, err := s.lookup(tenant, id)
+	if err != nil {
+		return err
+	}
+	stored.mu.Lock()
+	defer stored.mu.Unlock()
+	return fn(stored.calendar)
+}
+
+func (s *CalendarStore) Delete(tenant, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.lookupLocked(tenant, id); err != nil {
+		return err
+	}
+	delete(s.calendars, id)
+	return nil
+}
+
+// lookup returns the tenant's calendar, holding s.mu only for the lookup.
+func (s *CalendarStore) lookup(tenant, id string) (*tenantCalendar, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.lookupLocked(tenant, id)
+}
+
+// lookupLocked returns the tenant's calendar. The caller must hold s.mu.
+func (s *CalendarStore) lookupLocked(tenant, id string) (*tenantCalendar, error) {
+	stored, ok := s.calendars[id]
+	if !ok || stored.tenant != tenant {
+		return nil, ErrNotFound
+	}
+	return stored, nil
+}
+
+// NewID returns a random 128-bit identifier encoded as hex.
+func NewID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,145 @@
+package store
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"rental-profit-api/internal/booking"
+)
+
+func TestCalendarStore(t *testing.T) {
+	calendarStore := NewCalendarStore()
+	calendar, err := booking.NewCalendar(nil)
+	if err != nil {
+		t.Fatalf("NewCalendar() unexpected error: %v", err)
+	}
+
+	id, err := calendarStore.Create("acme", calendar)
+	if err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+
+	err = calendarStore.View("acme", id, func(got *booking.Calendar) error {
+		if got != calendar {
+			t.Errorf("View() returned a different calendar")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Errorf("View() unexpected error: %v", err)
+	}
+
+	wantErr := errors.New("boom")
+	if err := calendarStore.Update("acme", id, func(*booking.Calendar) error { return wantErr }); !errors.Is(err, wantErr) {
+		t.Errorf("Update() error = %v, want %v", err, wantErr)
+	}
+
+	if err := calendarStore.Delete("acme", id); err != nil {
+		t.Errorf("Delete() unexpected error: %v", err)
+	}
+	if err := calendarStore.View("acme", id, func(*booking.Calendar) error { return nil }); !errors.Is(err, ErrNotFound) {
+		t.Errorf("View() after Delete() error = %v, want %v", err, ErrNotFound)
+	}
+	if err := calendarStore.Delete("acme", id); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Delete() twice error = %v, want %v", err, ErrNotFound)
+	}
+}
+
+func TestCalendarStoreIsolatesTenants(t *testing.T) {
+	calendarStore := NewCalendarStore()
+	calendar, err := booking.NewCalendar(nil)
+	if err != nil {
+		t.Fatalf("NewCalendar() unexpected error: %v", err)
+	}
+	id, err := calendarStore.Create("acme", calendar)
+	if err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+
+	noop := func(*booking.Calendar) error { return nil }
+	if err := calendarStore.View("globex", id, noop); !errors.Is(err, ErrNotFound) {
+		t.Errorf("View() by another tenant error = %v, want %v", err, ErrNotFound)
+	}
+	if err := calendarStore.Update("globex", id, noop); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Update() by another tenant error = %v, want %v", err, ErrNotFound)
+	}
+	if err := calendarStore.Delete("globex", id); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Delete() by another tenant error = %v, want %v", err, ErrNotFound)
+	}
+	if err := calendarStore.View("acme", id, noop); err != nil {
+		t.Errorf("View() by the owner after the other tenant's attempts unexpected error: %v", err)
+	}
+}
+
+func TestCalendarStoreLocksEachCalendar(t *testing.T) {
+	calendarStore := NewCalendarStore()
+	newCalendar := func() string {
+		calendar, err := booking.NewCalendar(nil)
+		if err != nil {
+			t.Fatalf("NewCalendar() unexpected error: %v", err)
+		}
+		id, err := calendarStore.Create("acme", calendar)
+		if err != nil {
+			t.Fatalf("Create() unexpected error: %v", err)
+		}
+		return id
+	}
+	busy, idle := newCalendar(), newCalendar()
+	other, err := booking.NewCalendar(nil)
+	if err != nil {
+		t.Fatalf("NewCalendar() unexpected error: %v", err)
+	}
+
+	started, release := make(chan struct{}), make(chan struct{})
+	updated := make(chan error, 1)
+	go func() {
+		updated <- calendarStore.Update("acme", busy, func(*booking.Calendar) error {
+			close(started)
+			<-release
+			return nil
+		})
+	}()
+	<-started
+
+	done := make(chan error, 1)
+	go func() {
+		noop := func(*booking.Calendar) error { return nil }
+		if err := calendarStore.View("acme", idle, noop); err != nil {
+			done <- err
+			return
+		}
+		if err := calendarStore.Update("acme", idle, noop); err != nil {
+			done <- err
+			return
+		}
+		_, err := calendarStore.Create("globex", other)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("access to another calendar unexpected error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("access to another calendar blocked behind a running Update()")
+	}
+
+	viewed := make(chan error, 1)
+	go func() {
+		viewed <- calendarStore.View("acme", busy, func(*booking.Calendar) error { return nil })
+	}()
+	select {
+	case <-viewed:
+		t.Fatal("View() of the calendar ran while its Update() held it")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	if err := <-updated; err != nil {
+		t.Errorf("Update() unexpected error: %v", err)
+	}
+	if err := <-viewed; err != nil {
+		t.Errorf("View() unexpected error: %v", err)
+	}
+}
@@ -26,4 +26,16 @@ func NewTestRequest(t *testing.T, method, path string, body any) *http.Request {
 	}
 	req.Header.Set("Content-Type", "application/json")
 	return req
-}
\ No newline at end of file
+}
+
+// NewRawTestRequest builds a JSON request whose body is sent verbatim, for
+// payloads that cannot be expressed by marshalling a Go value.
+func NewRawTestRequest(t *testing.T, method, path, body string) *http.Request {
+	t.Helper()
+	req, err := http.NewRequest(method, path, bytes.NewBufferString(body))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
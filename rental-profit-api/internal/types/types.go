@@ -1,19 +1,26 @@
 package types
 
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
 type BookingRequest struct {
-	RequestID   string  `json:"request_id"`
-	Checkin     string  `json:"check_in"` 
-	Nights      int     `json:"nights"`
-	SellingRate float64 `json:"selling_rate"`
-	Margin      float64 `json:"margin"`
+	RequestID         string  `json:"request_id"`
+	Checkin           string  `json:"check_in"`
+	Nights            int     `json:"nights"`
+	SellingRate       float64 `json:"selling_rate"`
+	Margin            float64 `json:"margin"`
+	CancelProbability float64 `json:"cancel_probability,omitempty"`
 }
 
 type MaximizeResponse struct {
-	RequestIDs []string `json:"request_ids"`
-	TotalProfit float64 `json:"total_profit"`
-	AvgNight    float64 `json:"avg_night"` 
-	MinNight    float64 `json:"min_night"`
-	MaxNight    float64 `json:"max_night"`
+	RequestIDs  []string `json:"request_ids"`
+	TotalProfit float64  `json:"total_profit"`
+	AvgNight    float64  `json:"avg_night"`
+	MinNight    float64  `json:"min_night"`
+	MaxNight    float64  `json:"max_night"`
 }
 
 type StatsResponse struct {
@@ -23,7 +30,8 @@ type StatsResponse struct {
 }
 
 type ErrorResponse struct {
-	Message string `json:"message"`
+	Message   string `json:"message"`
+	RequestID string `json:"request_id,omitempty"`
 }
 
 type ProfitStats struct {
@@ -32,4 +40,232 @@ type ProfitStats struct {
 	MinNight    float64
 	MaxNight    float64
 	RequestIDs  []string
-}
\ No newline at end of file
+}
+
+// ScheduleSelection is one side of a comparison: either an explicit list of
+// request IDs or the keyword "optimal", meaning the FindMaxProfit schedule.
+// A side left out or given as null has neither, see IsZero.
+type ScheduleSelection struct {
+	Optimal    bool
+	RequestIDs []string
+}
+
+// IsZero reports whether the selection was missing or null, as opposed to an
+// explicit empty list.
+func (s ScheduleSelection) IsZero() bool {
+	return !s.Optimal && s.RequestIDs == nil
+}
+
+const OptimalSelection = "optimal"
+
+func (s *ScheduleSelection) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*s = ScheduleSelection{}
+		return nil
+	}
+	var keyword string
+	if err := json.Unmarshal(data, &keyword); err == nil {
+		if keyword != OptimalSelection {
+			return fmt.Errorf("schedule selection must be a list of request_ids or %q, got %q", OptimalSelection, keyword)
+		}
+		*s = ScheduleSelection{Optimal: true}
+		return nil
+	}
+	var requestIDs []string
+	if err := json.Unmarshal(data, &requestIDs); err != nil {
+		return fmt.Errorf("schedule selection must be a list of request_ids or %q", OptimalSelection)
+	}
+	*s = ScheduleSelection{RequestIDs: requestIDs}
+	return nil
+}
+
+func (s ScheduleSelection) MarshalJSON() ([]byte, error) {
+	if s.Optimal {
+		return json.Marshal(OptimalSelection)
+	}
+	if s.RequestIDs == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(s.RequestIDs)
+}
+
+type CompareRequest struct {
+	Bookings []BookingRequest  `json:"bookings"`
+	Left     ScheduleSelection `json:"left"`
+	Right    ScheduleSelection `json:"right"`
+}
+
+type ScheduleSummary struct {
+	RequestIDs     []string `json:"request_ids"`
+	TotalProfit    float64  `json:"total_profit"`
+	OccupiedNights int      `json:"occupied_nights"`
+	AvgNight       float64  `json:"avg_night"`
+	MinNight       float64  `json:"min_night"`
+	MaxNight       float64  `json:"max_night"`
+}
+
+type CompareResponse struct {
+	Left           ScheduleSummary `json:"left"`
+	Right          ScheduleSummary `json:"right"`
+	Added          []string        `json:"added"`
+	Removed        []string        `json:"removed"`
+	ProfitDelta    float64         `json:"profit_delta"`
+	OccupancyDelta int             `json:"occupancy_delta"`
+}
+
+type ValidateScheduleRequest struct {
+	Bookings   []BookingRequest `json:"bookings"`
+	RequestIDs []string         `json:"request_ids"`
+}
+
+type OverlapPair struct {
+	First  string `json:"first"`
+	Second string `json:"second"`
+}
+
+type ValidateScheduleResponse struct {
+	Valid             bool          `json:"valid"`
+	Overlaps          []OverlapPair `json:"overlaps"`
+	OverlapCount      int           `json:"overlap_count"`
+	OverlapsTruncated bool          `json:"overlaps_truncated"`
+	TotalProfit       float64       `json:"total_profit"`
+}
+
+type CalendarResponse struct {
+	CalendarID   string           `json:"calendar_id"`
+	BookingCount int              `json:"booking_count"`
+	Schedule     MaximizeResponse `json:"schedule"`
+}
+
+type BookingDecisionResponse struct {
+	RequestID string           `json:"request_id"`
+	Accepted  bool             `json:"accepted"`
+	Displaced []string         `json:"displaced"`
+	Schedule  MaximizeResponse `json:"schedule"`
+}
+
+// QuoteStay is a prospective booking whose selling rate is yet to be agreed.
+type QuoteStay struct {
+	RequestID string  `json:"request_id"`
+	Checkin   string  `json:"check_in"`
+	Nights    int     `json:"nights"`
+	Margin    float64 `json:"margin"`
+}
+
+type QuoteRequest struct {
+	Bookings []BookingRequest `json:"bookings"`
+	Stay     QuoteStay        `json:"stay"`
+}
+
+type QuoteResponse struct {
+	MinSellingRate float64          `json:"min_selling_rate"`
+	Displaced      []string         `json:"displaced"`
+	Schedule       MaximizeResponse `json:"schedule"`
+}
+
+type OverbookedPair struct {
+	First           string  `json:"first"`
+	Second          string  `json:"second"`
+	WalkProbability float64 `json:"walk_probability"`
+}
+
+// ExpectedProfitResponse is returned by /maximize in stochastic mode.
+type ExpectedProfitResponse struct {
+	RequestIDs     []string         `json:"request_ids"`
+	ExpectedProfit float64          `json:"expected_profit"`
+	ExpectedWalks  float64          `json:"expected_walks"`
+	Overbooked     []OverbookedPair `json:"overbooked"`
+}
+
+type HealthResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+type VersionResponse struct {
+	Version    string `json:"version"`
+	Revision   string `json:"revision"`
+	CommitTime string `json:"commit_time,omitempty"`
+	BuildTime  string `json:"build_time,omitempty"`
+	Modified   bool   `json:"modified"`
+	GoVersion  string `json:"go_version"`
+}
+
+// JobResponse describes an asynchronous optimization. Result is set once
+// the job succeeded and Error once it failed.
+type JobResponse struct {
+	JobID      string            `json:"job_id"`
+	Status     string            `json:"status"`
+	Progress   float64           `json:"progress"`
+	CreatedAt  time.Time         `json:"created_at"`
+	StartedAt  *time.Time        `json:"started_at,omitempty"`
+	FinishedAt *time.Time        `json:"finished_at,omitempty"`
+	Result     *MaximizeResponse `json:"result,omitempty"`
+	Error      string            `json:"error,omitempty"`
+}
+
+// WebhookRequest registers a callback URL. Secret signs the deliveries and is
+// generated when omitted.
+type WebhookRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Secret string   `json:"secret,omitempty"`
+}
+
+// WebhookResponse describes a registered webhook. The secret is only
+// returned when the webhook is created.
+type WebhookResponse struct {
+	WebhookID string    `json:"webhook_id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type WebhookDeliveryResponse struct {
+	DeliveryID  string    `json:"delivery_id"`
+	EventID     string    `json:"event_id"`
+	Event       string    `json:"event"`
+	Attempt     int       `json:"attempt"`
+	StatusCode  int       `json:"status_code,omitempty"`
+	Succeeded   bool      `json:"succeeded"`
+	Error       string    `json:"error,omitempty"`
+	DurationMs  float64   `json:"duration_ms"`
+	DeliveredAt time.Time `json:"delivered_at"`
+}
+
+// CalendarChangedEvent is the payload of the calendar.changed webhook event.
+type CalendarChangedEvent struct {
+	CalendarID string                  `json:"calendar_id"`
+	Action     string                  `json:"action"`
+	Decision   BookingDecisionResponse `json:"decision"`
+}
+
+// Envelope wraps every /v2 response. Data holds the endpoint's payload and is
+// null when the request failed, in which case Errors says why.
+type Envelope struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []EnvelopeError `json:"errors"`
+	Meta   ResponseMeta    `json:"meta"`
+}
+
+type EnvelopeError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type ResponseMeta struct {
+	RequestID   string       `json:"request_id"`
+	Timing      Timing       `json:"timing"`
+	InputCounts *InputCounts `json:"input_counts,omitempty"`
+}
+
+type Timing struct {
+	DurationMs float64 `json:"duration_ms"`
+}
+
+// InputCounts sizes the request payload. It is omitted when the request
+// failed before its body was decoded.
+type InputCounts struct {
+	Bookings int `json:"bookings"`
+}
@@ -0,0 +1,509 @@
+// Package webhooks stores callback registrations and delivers signed JSON
+// events to them, retrying failed deliveries with exponential backoff and
+// keeping a log of every attempt.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"rental-profit-api/internal/store"
+)
+
+// Headers set on every delivery. The signature covers the timestamp and the
+// raw body as "<timestamp>.<body>", so receivers can reject replays.
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+)
+
+var ErrInvalidURL = errors.New("webhook url must be an absolute http or https URL")
+
+// ErrPrivateAddress rejects a webhook URL whose host is, or resolves to, a
+// loopback, private, link-local or otherwise reserved address, so tenants
+// cannot make the server call internal services.
+var ErrPrivateAddress = errors.New("webhook url must resolve to public addresses only")
+
+// ErrTooManyWebhooks rejects a registration past Options.MaxPerTenant.
+var ErrTooManyWebhooks = errors.New("too many webhooks")
+
+// Webhook is a registered callback. Events lists the event types it
+// receives; only events published for its tenant reach it.
+type Webhook struct {
+	ID        string
+	Tenant    string
+	URL       string
+	Events    []string
+	Secret    string
+	CreatedAt time.Time
+}
+
+// Event is the JSON body POSTed to subscribers.
+type Event struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"created_at"`
+	Data      any       `json:"data"`
+}
+
+// Delivery records one attempt to deliver an event to a webhook.
+type Delivery struct {
+	ID         string
+	EventID    string
+	EventType  string
+	Attempt    int
+	StatusCode int    // 0 when no response was received
+	Error      string // Why the attempt failed, empty on success
+	Succeeded  bool
+	Duration   time.Duration
+	At         time.Time
+}
+
+// Options configures a Service.
+type Options struct {
+	Workers        int           // Deliveries sent concurrently
+	QueueSize      int           // Deliveries waiting for a worker before new ones are dropped
+	MaxAttempts    int           // Attempts per delivery, including the first
+	InitialBackoff time.Duration // Wait before the first retry; doubles on every retry
+	MaxBackoff     time.Duration // Upper bound for the wait between retries
+	Timeout        time.Duration // Per attempt
+	LogSize        int           // Delivery attempts kept per webhook
+	MaxPerTenant   int           // Webhooks a tenant may register, 0 for no limit
+	// AllowPrivateNetworks lets webhooks reach loopback, private and
+	// link-local addresses, for development against local receivers
+	AllowPrivateNetworks bool
+}
+
+// Service keeps webhooks in memory and delivers events to them in the
+// background.
+type Service struct {
+	options  Options
+	client   *http.Client
+	lookupIP func(ctx context.Context, network, host string) ([]netip.Addr, error)
+	now      func() time.Time
+
+	mu         sync.RWMutex
+	webhooks   map[string]*Webhook
+	deliveries map[string][]Delivery // By webhook ID, newest first
+
+	queue  chan *delivery
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// delivery is an event on its way to one webhook.
+type delivery struct {
+	id      string
+	webhook Webhook
+	event   Event
+	body    []byte
+	attempt int
+}
+
+// NewService starts the delivery workers. Call Close to stop them.
+func NewService(options Options) *Service {
+	options.Workers = max(options.Workers, 1)
+	options.MaxAttempts = max(options.MaxAttempts, 1)
+	options.LogSize = max(options.LogSize, 1)
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if !options.AllowPrivateNetworks {
+		// Checking the address actually dialed, after every lookup, keeps a
+		// host that resolved to a public address at registration from
+		// being rebound to an internal one. A proxy would hide that address.
+		dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second, Control: refusePrivateAddress}
+		transport.DialContext = dialer.DialContext
+		transport.Proxy = nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Service{
+		options:    options,
+		client:     &http.Client{Timeout: options.Timeout, Transport: transport},
+		lookupIP:   net.DefaultResolver.LookupNetIP,
+		now:        time.Now,
+		webhooks:   make(map[string]*Webhook),
+		deliveries: make(map[string][]Delivery),
+		queue:      make(chan *delivery, max(options.QueueSize, 0)),
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+	s.wg.Add(options.Workers)
+	for range options.Workers {
+		go s.work()
+	}
+	return s
+}
+
+// Register stores a webhook of tenant for the given event types. An empty
+// secret is replaced by a generated one. Unless Options.AllowPrivateNetworks
+// is set, the host must resolve to public addresses only. A tenant already
+// holding Options.MaxPerTenant webhooks gets ErrTooManyWebhooks.
+func (s *Service) Register(tenant, rawURL string, events []string, secret string) (Webhook, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return Webhook{}, ErrInvalidURL
+	}
+	if !s.options.AllowPrivateNetworks {
+		if err := s.checkHost(parsed.Hostname()); err != nil {
+			return Webhook{}, err
+		}
+	}
+	id, err := store.NewID()
+	if err != nil {
+		return Webhook{}, err
+	}
+	if secret == "" {
+		if secret, err = store.NewID(); err != nil {
+			return Webhook{}, err
+		}
+	}
+
+	webhook := &Webhook{
+		ID:        id,
+		Tenant:    tenant,
+		URL:       parsed.String(),
+		Events:    slices.Clone(events),
+		Secret:    secret,
+		CreatedAt: s.now(),
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.options.MaxPerTenant > 0 && s.countLocked(tenant) >= s.options.MaxPerTenant {
+		return Webhook{}, fmt.Errorf("%w: at most %d per tenant", ErrTooManyWebhooks, s.options.MaxPerTenant)
+	}
+	s.webhooks[id] = webhook
+	return *webhook, nil
+}
+
+// countLocked returns the number of the tenant's webhooks. The caller must
+// hold s.mu.
+func (s *Service) countLocked(tenant string) int {
+	count := 0
+	for _, webhook := range s.webhooks {
+		if webhook.Tenant == tenant {
+			count++
+		}
+	}
+	return count
+}
+
+// checkHost returns ErrPrivateAddress unless every address of host is
+// public. A host that cannot be resolved is rejected too.
+func (s *Service) checkHost(host string) error {
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if isPrivate(addr) {
+			return ErrPrivateAddress
+		}
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), s.options.Timeout)
+	defer cancel()
+	addrs, err := s.lookupIP(ctx, "ip", host)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrPrivateAddress, err)
+	}
+	for _, addr := range addrs {
+		if isPrivate(addr) {
+			return fmt.Errorf("%w: %s resolves to %s", ErrPrivateAddress, host, addr)
+		}
+	}
+	return nil
+}
+
+// refusePrivateAddress is a net.Dialer Control hook failing connections to
+// addresses isPrivate reports.
+func refusePrivateAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if addr, err := netip.ParseAddr(host); err != nil || isPrivate(addr) {
+		return fmt.Errorf("%w: refusing to connect to %s", ErrPrivateAddress, host)
+	}
+	return nil
+}
+
+// blockedPrefixes are the special-purpose ranges of the IANA registries that
+// are not globally reachable: the host itself, internal and carrier-grade
+// NAT networks, link-local ones including cloud metadata services on
+// 169.254.169.254, multicast, and ranges reserved for documentation,
+// benchmarking or future use.
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("10.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("127.0.0.0/8"),
+	netip.MustParsePrefix("169.254.0.0/16"),
+	netip.MustParsePrefix("172.16.0.0/12"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("192.0.2.0/24"),
+	netip.MustParsePrefix("192.168.0.0/16"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("198.51.100.0/24"),
+	netip.MustParsePrefix("203.0.113.0/24"),
+	netip.MustParsePrefix("224.0.0.0/4"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("::/96"), // Unspecified, loopback and IPv4-compatible
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+	netip.MustParsePrefix("100::/64"),
+	netip.MustParsePrefix("2001::/23"),
+	netip.MustParsePrefix("2001:db8::/32"),
+	netip.MustParsePrefix("fc00::/7"),
+	netip.MustParsePrefix("fe80::/10"),
+	netip.MustParsePrefix("fec0::/10"),
+	netip.MustParsePrefix("ff00::/8"),
+}
+
+// nat64Prefix is the well-known NAT64 prefix, embedding an IPv4 address in
+// its last 32 bits.
+var nat64Prefix = netip.MustParsePrefix("64:ff9b::/96")
+
+// isPrivate reports addresses in blockedPrefixes. IPv4-mapped and NAT64
+// addresses are checked by the IPv4 address they reach.
+func isPrivate(addr netip.Addr) bool {
+	addr = addr.Unmap().WithZone("")
+	if nat64Prefix.Contains(addr) {
+		ip := addr.As16()
+		addr = netip.AddrFrom4([4]byte(ip[12:]))
+	}
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// Get returns the tenant's webhook with the given ID, or store.ErrNotFound.
+func (s *Service) Get(tenant, id string) (Webhook, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	webhook, err := s.lookupLocked(tenant, id)
+	if err != nil {
+		return Webhook{}, err
+	}
+	return *webhook, nil
+}
+
+// List returns the tenant's webhooks, oldest first.
+func (s *Service) List(tenant string) []Webhook {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	webhooks := []Webhook{}
+	for _, webhook := range s.webhooks {
+		if webhook.Tenant == tenant {
+			webhooks = append(webhooks, *webhook)
+		}
+	}
+	slices.SortFunc(webhooks, func(a, b Webhook) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return webhooks
+}
+
+// Delete removes the webhook and its delivery log. Pending retries to it
+// are abandoned.
+func (s *Service) Delete(tenant, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.lookupLocked(tenant, id); err != nil {
+		return err
+	}
+	delete(s.webhooks, id)
+	delete(s.deliveries, id)
+	return nil
+}
+
+// Deliveries returns the logged delivery attempts for the webhook, newest
+// first.
+func (s *Service) Deliveries(tenant, id string) ([]Delivery, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if _, err := s.lookupLocked(tenant, id); err != nil {
+		return nil, err
+	}
+	return slices.Clone(s.deliveries[id]), nil
+}
+
+// lookupLocked returns the tenant's webhook, reporting other tenants'
+// webhooks as store.ErrNotFound. The caller must hold s.mu.
+func (s *Service) lookupLocked(tenant, id string) (*Webhook, error) {
+	webhook, ok := s.webhooks[id]
+	if !ok || webhook.Tenant != tenant {
+		return nil, store.ErrNotFound
+	}
+	return webhook, nil
+}
+
+// Publish queues the event for every webhook of tenant subscribed to
+// eventType. It never blocks: when the queue is full the delivery is logged
+// as dropped.
+func (s *Service) Publish(tenant, eventType string, data any) {
+	eventID, err := store.NewID()
+	if err != nil {
+		slog.Error("Failed to publish webhook event", "event", eventType, "error", err)
+		return
+	}
+	event := Event{ID: eventID, Type: eventType, CreatedAt: s.now().UTC(), Data: data}
+	body, err := json.Marshal(event)
+	if err != nil {
+		slog.Error("Failed to encode webhook event", "event", eventType, "error", err)
+		return
+	}
+
+	s.mu.RLock()
+	var subscribers []Webhook
+	for _, webhook := range s.webhooks {
+		if webhook.Tenant == tenant && slices.Contains(webhook.Events, eventType) {
+			subscribers = append(subscribers, *webhook)
+		}
+	}
+	s.mu.RUnlock()
+
+	for _, webhook := range subscribers {
+		deliveryID, err := store.NewID()
+		if err != nil {
+			slog.Error("Failed to publish webhook event", "event", eventType, "error", err)
+			return
+		}
+		s.enqueue(&delivery{id: deliveryID, webhook: webhook, event: event, body: body, attempt: 1})
+	}
+}
+
+// Close stops the workers and abandons pending retries.
+func (s *Service) Close() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+// Sign returns the hex HMAC-SHA256 of "<timestamp>.<body>" under secret.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignatureHeaderValue formats the signature header as "t=<unix>,v1=<hex>".
+func SignatureHeaderValue(secret string, timestamp int64, body []byte) string {
+	return fmt.Sprintf("t=%d,v1=%s", timestamp, Sign(secret, timestamp, body))
+}
+
+func (s *Service) enqueue(d *delivery) {
+	select {
+	case <-s.ctx.Done():
+	case s.queue <- d:
+	default:
+		s.record(d, Delivery{Error: "dropped: delivery queue is full"})
+	}
+}
+
+func (s *Service) work() {
+	defer s.wg.Done()
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case d := <-s.queue:
+			s.attempt(d)
+		}
+	}
+}
+
+// attempt sends d once, logs the outcome and schedules a retry if it failed
+// and attempts remain.
+func (s *Service) attempt(d *delivery) {
+	if _, err := s.Get(d.webhook.Tenant, d.webhook.ID); err != nil {
+		return
+	}
+	start := s.now()
+	statusCode, err := s.send(d)
+	outcome := Delivery{StatusCode: statusCode, Succeeded: err == nil, Duration: s.now().Sub(start)}
+	if err != nil {
+		outcome.Error = err.Error()
+	}
+	s.record(d, outcome)
+
+	if err == nil || d.attempt >= s.options.MaxAttempts {
+		return
+	}
+	retry := *d
+	retry.attempt++
+	time.AfterFunc(s.backoff(d.attempt), func() {
+		s.enqueue(&retry)
+	})
+}
+
+func (s *Service) send(d *delivery) (int, error) {
+	req, err := http.NewRequestWithContext(s.ctx, http.MethodPost, d.webhook.URL, bytes.NewReader(d.body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, d.event.Type)
+	req.Header.Set(DeliveryHeader, d.id)
+	req.Header.Set(SignatureHeader, SignatureHeaderValue(d.webhook.Secret, s.now().Unix(), d.body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("receiver answered %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff is the wait after the given failed attempt.
+func (s *Service) backoff(attempt int) time.Duration {
+	wait := s.options.InitialBackoff
+	for range attempt - 1 {
+		if wait >= s.options.MaxBackoff {
+			break
+		}
+		wait *= 2
+	}
+	return min(wait, s.options.MaxBackoff)
+}
+
+// record prepends the attempt to the webhook's log, trimming it to LogSize.
+// Attempts for deleted webhooks are not logged.
+func (s *Service) record(d *delivery, outcome Delivery) {
+	outcome.ID = d.id
+	outcome.EventID = d.event.ID
+	outcome.EventType = d.event.Type
+	outcome.Attempt = d.attempt
+	outcome.At = s.now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.webhooks[d.webhook.ID]; !ok {
+		return
+	}
+	log := append([]Delivery{outcome}, s.deliveries[d.webhook.ID]...)
+	s.deliveries[d.webhook.ID] = log[:min(len(log), s.options.LogSize)]
+}
@@ -0,0 +1,407 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"rental-profit-api/internal/store"
+)
+
+// testTenant owns the webhooks registered by these tests.
+const testTenant = "acme"
+
+// receiver is an httptest server recording the requests it gets and
+// answering with the next status from statuses, then 200.
+type receiver struct {
+	*httptest.Server
+	requests chan *http.Request
+	bodies   chan []byte
+	calls    atomic.Int32
+}
+
+func newReceiver(t *testing.T, statuses ...int) *receiver {
+	t.Helper()
+	rec := &receiver{requests: make(chan *http.Request, 16), bodies: make(chan []byte, 16)}
+	rec.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		call := int(rec.calls.Add(1))
+		rec.requests <- r
+		rec.bodies <- body
+		if call <= len(statuses) {
+			w.WriteHeader(statuses[call-1])
+		}
+	}))
+	t.Cleanup(rec.Close)
+	return rec
+}
+
+func newTestService(t *testing.T, maxAttempts int) *Service {
+	t.Helper()
+	s := NewService(Options{
+		Workers:        2,
+		QueueSize:      16,
+		MaxAttempts:    maxAttempts,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     5 * time.Millisecond,
+		Timeout:        time.Second,
+		LogSize:        10,
+		// The receivers listen on loopback
+		AllowPrivateNetworks: true,
+	})
+	t.Cleanup(s.Close)
+	return s
+}
+
+// waitDeliveries polls until the webhook has logged count attempts.
+func waitDeliveries(t *testing.T, s *Service, id string, count int) []Delivery {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		deliveries, err := s.Deliveries(testTenant, id)
+		if err != nil {
+			t.Fatalf("Deliveries(%q) unexpected error: %v", id, err)
+		}
+		if len(deliveries) >= count {
+			return deliveries
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("webhook %s did not log %d deliveries in time", id, count)
+	return nil
+}
+
+func TestServiceDeliversSignedEvents(t *testing.T) {
+	s := newTestService(t, 1)
+	subscribed := newReceiver(t)
+	other := newReceiver(t)
+	foreign := newReceiver(t)
+
+	webhook, err := s.Register(testTenant, subscribed.URL, []string{"job.completed"}, "s3cret")
+	if err != nil {
+		t.Fatalf("Register() unexpected error: %v", err)
+	}
+	if _, err := s.Register(testTenant, other.URL, []string{"calendar.changed"}, ""); err != nil {
+		t.Fatalf("Register() unexpected error: %v", err)
+	}
+	if _, err := s.Register("globex", foreign.URL, []string{"job.completed"}, ""); err != nil {
+		t.Fatalf("Register() unexpected error: %v", err)
+	}
+
+	s.Publish(testTenant, "job.completed", map[string]string{"job_id": "J1"})
+
+	var r *http.Request
+	select {
+	case r = <-subscribed.requests:
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscribed receiver got no delivery")
+	}
+	body := <-subscribed.bodies
+
+	if got := r.Header.Get(EventHeader); got != "job.completed" {
+		t.Errorf("%s = %q, want %q", EventHeader, got, "job.completed")
+	}
+	if got := r.Header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", got)
+	}
+	timestamp, signature, ok := strings.Cut(strings.TrimPrefix(r.Header.Get(SignatureHeader), "t="), ",v1=")
+	if !ok {
+		t.Fatalf("%s = %q, want t=<unix>,v1=<hex>", SignatureHeader, r.Header.Get(SignatureHeader))
+	}
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		t.Fatalf("signature timestamp %q is not a number: %v", timestamp, err)
+	}
+	if want := Sign("s3cret", unix, body); signature != want {
+		t.Errorf("signature = %q, want %q", signature, want)
+	}
+
+	var event Event
+	if err := json.Unmarshal(body, &event); err != nil {
+		t.Fatalf("delivery body is not JSON: %v", err)
+	}
+	if event.Type != "job.completed" || event.ID == "" || event.Data.(map[string]any)["job_id"] != "J1" {
+		t.Errorf("delivered event = %+v", event)
+	}
+
+	deliveries := waitDeliveries(t, s, webhook.ID, 1)
+	if d := deliveries[0]; !d.Succeeded || d.StatusCode != http.StatusOK || d.Attempt != 1 || d.EventID != event.ID {
+		t.Errorf("logged delivery = %+v, want first attempt succeeded with 200", d)
+	}
+	if r.Header.Get(DeliveryHeader) != deliveries[0].ID {
+		t.Errorf("%s = %q, want the logged delivery ID %q", DeliveryHeader, r.Header.Get(DeliveryHeader), deliveries[0].ID)
+	}
+
+	// Give a misrouted delivery the chance to show up
+	time.Sleep(20 * time.Millisecond)
+	if calls := other.calls.Load(); calls != 0 {
+		t.Errorf("unsubscribed receiver got %d deliveries, want 0", calls)
+	}
+	if calls := foreign.calls.Load(); calls != 0 {
+		t.Errorf("other tenant's receiver got %d deliveries, want 0", calls)
+	}
+}
+
+func TestServiceRetries(t *testing.T) {
+	// --- Define Test Scenarios ---
+	testCases := []struct {
+		name              string
+		statuses          []int
+		maxAttempts       int
+		expectedAttempts  int
+		expectedSucceeded bool
+	}{
+		{
+			name:              "Succeeds after failures",
+			statuses:          []int{http.StatusInternalServerError, http.StatusBadGateway},
+			maxAttempts:       5,
+			expectedAttempts:  3,
+			expectedSucceeded: true,
+		},
+		{
+			name:              "Gives up after max attempts",
+			statuses:          []int{500, 500, 500, 500, 500},
+			maxAttempts:       3,
+			expectedAttempts:  3,
+			expectedSucceeded: false,
+		},
+	}
+
+	// --- Execute Scenarios ---
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := newTestService(t, tc.maxAttempts)
+			rec := newReceiver(t, tc.statuses...)
+			webhook, err := s.Register(testTenant, rec.URL, []string{"job.completed"}, "")
+			if err != nil {
+				t.Fatalf("Register() unexpected error: %v", err)
+			}
+
+			s.Publish(testTenant, "job.completed", nil)
+
+			deliveries := waitDeliveries(t, s, webhook.ID, tc.expectedAttempts)
+			// Wait out one more backoff to catch attempts beyond the limit
+			time.Sleep(20 * time.Millisecond)
+			if calls := int(rec.calls.Load()); calls != tc.expectedAttempts {
+				t.Errorf("receiver got %d attempts, want %d", calls, tc.expectedAttempts)
+			}
+			for i, d := range deliveries {
+				if want := tc.expectedAttempts - i; d.Attempt != want {
+					t.Errorf("deliveries[%d].Attempt = %d, want %d (newest first)", i, d.Attempt, want)
+				}
+				if d.ID != deliveries[0].ID {
+					t.Errorf("deliveries[%d].ID = %q, want retries to keep %q", i, d.ID, deliveries[0].ID)
+				}
+			}
+			last := deliveries[0]
+			if last.Succeeded != tc.expectedSucceeded {
+				t.Errorf("last attempt succeeded = %v, want %v", last.Succeeded, tc.expectedSucceeded)
+			}
+			if failed := deliveries[len(deliveries)-1]; failed.Succeeded || failed.StatusCode != tc.statuses[0] || failed.Error == "" {
+				t.Errorf("first attempt = %+v, want failure with status %d", failed, tc.statuses[0])
+			}
+		})
+	}
+}
+
+func TestServiceRegister(t *testing.T) {
+	s := newTestService(t, 1)
+
+	// --- Define Test Scenarios ---
+	testCases := []struct {
+		name        string
+		url         string
+		expectedErr error
+	}{
+		{name: "HTTPS URL", url: "https://example.com/hooks"},
+		{name: "HTTP URL with port", url: "http://127.0.0.1:9000/hooks"},
+		{name: "Relative URL", url: "/hooks", expectedErr: ErrInvalidURL},
+		{name: "Other scheme", url: "ftp://example.com/hooks", expectedErr: ErrInvalidURL},
+		{name: "Missing host", url: "https:///hooks", expectedErr: ErrInvalidURL},
+		{name: "Unparsable", url: "http://[::1", expectedErr: ErrInvalidURL},
+	}
+
+	// --- Execute Scenarios ---
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			webhook, err := s.Register(testTenant, tc.url, []string{"job.completed"}, "")
+			if !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("Register(%q) error = %v, want %v", tc.url, err, tc.expectedErr)
+			}
+			if err != nil {
+				return
+			}
+			if webhook.ID == "" || webhook.Secret == "" {
+				t.Errorf("Register(%q) = %+v, want an ID and a generated secret", tc.url, webhook)
+			}
+			if got, err := s.Get(testTenant, webhook.ID); err != nil || got.URL != tc.url {
+				t.Errorf("Get() = %+v, %v, want the registered webhook", got, err)
+			}
+		})
+	}
+}
+
+func TestServiceRegisterRefusesPrivateAddresses(t *testing.T) {
+	s := NewService(Options{Timeout: time.Second})
+	t.Cleanup(s.Close)
+	s.lookupIP = func(ctx context.Context, network, host string) ([]netip.Addr, error) {
+		switch host {
+		case "public.test":
+			return []netip.Addr{netip.MustParseAddr("93.184.216.34")}, nil
+		case "mixed.test":
+			return []netip.Addr{netip.MustParseAddr("93.184.216.34"), netip.MustParseAddr("10.0.0.5")}, nil
+		case "mapped.test":
+			return []netip.Addr{netip.MustParseAddr("::ffff:100.64.0.1")}, nil
+		}
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+
+	// --- Define Test Scenarios ---
+	testCases := []struct {
+		name        string
+		url         string
+		expectedErr error
+	}{
+		{name: "Public host", url: "https://public.test/hooks"},
+		{name: "Public IP", url: "http://93.184.216.34:9000/hooks"},
+		{name: "Loopback", url: "http://127.0.0.1:9000/hooks", expectedErr: ErrPrivateAddress},
+		{name: "IPv6 loopback", url: "http://[::1]:9000/hooks", expectedErr: ErrPrivateAddress},
+		{name: "Cloud metadata", url: "http://169.254.169.254/latest/meta-data", expectedErr: ErrPrivateAddress},
+		{name: "Private network", url: "http://192.168.1.10/hooks", expectedErr: ErrPrivateAddress},
+		{name: "Unspecified", url: "http://0.0.0.0/hooks", expectedErr: ErrPrivateAddress},
+		{name: "IPv4-mapped private", url: "http://[::ffff:10.0.0.1]/hooks", expectedErr: ErrPrivateAddress},
+		{name: "This network", url: "http://0.1.2.3/hooks", expectedErr: ErrPrivateAddress},
+		{name: "Carrier-grade NAT", url: "http://100.64.0.1/hooks", expectedErr: ErrPrivateAddress},
+		{name: "Benchmarking", url: "http://198.18.0.1/hooks", expectedErr: ErrPrivateAddress},
+		{name: "IETF protocol assignments", url: "http://192.0.0.8/hooks", expectedErr: ErrPrivateAddress},
+		{name: "NAT64 private", url: "http://[64:ff9b::a00:1]/hooks", expectedErr: ErrPrivateAddress},
+		{name: "NAT64 public", url: "http://[64:ff9b::5db8:d822]/hooks"},
+		{name: "IPv6 unique local", url: "http://[fd00::1]/hooks", expectedErr: ErrPrivateAddress},
+		{name: "IPv6 link-local with zone", url: "http://[fe80::1%25eth0]/hooks", expectedErr: ErrPrivateAddress},
+		{name: "Host with a mapped address", url: "https://mapped.test/hooks", expectedErr: ErrPrivateAddress},
+		{name: "Host with a private address", url: "https://mixed.test/hooks", expectedErr: ErrPrivateAddress},
+		{name: "Unresolvable host", url: "https://missing.test/hooks", expectedErr: ErrPrivateAddress},
+	}
+
+	// --- Execute Scenarios ---
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := s.Register(testTenant, tc.url, []string{"job.completed"}, "")
+			if !errors.Is(err, tc.expectedErr) {
+				t.Errorf("Register(%q) error = %v, want %v", tc.url, err, tc.expectedErr)
+			}
+		})
+	}
+}
+
+func TestServiceRegisterCapsWebhooksPerTenant(t *testing.T) {
+	s := NewService(Options{MaxPerTenant: 2, AllowPrivateNetworks: true})
+	t.Cleanup(s.Close)
+
+	for range 2 {
+		if _, err := s.Register(testTenant, "http://127.0.0.1/hooks", []string{"job.completed"}, ""); err != nil {
+			t.Fatalf("Register() unexpected error: %v", err)
+		}
+	}
+	webhook, err := s.Register("globex", "http://127.0.0.1/hooks", []string{"job.completed"}, "")
+	if err != nil {
+		t.Fatalf("Register() by another tenant unexpected error: %v", err)
+	}
+	if _, err := s.Register(testTenant, "http://127.0.0.1/hooks", []string{"job.completed"}, ""); !errors.Is(err, ErrTooManyWebhooks) {
+		t.Errorf("Register() past the cap error = %v, want %v", err, ErrTooManyWebhooks)
+	}
+
+	if err := s.Delete("globex", webhook.ID); err != nil {
+		t.Fatalf("Delete() unexpected error: %v", err)
+	}
+	first := s.List(testTenant)[0]
+	if err := s.Delete(testTenant, first.ID); err != nil {
+		t.Fatalf("Delete() unexpected error: %v", err)
+	}
+	if _, err := s.Register(testTenant, "http://127.0.0.1/hooks", []string{"job.completed"}, ""); err != nil {
+		t.Errorf("Register() after Delete() unexpected error: %v", err)
+	}
+}
+
+// TestServiceRefusesToDialPrivateAddresses stands for a host rebound to a
+// loopback address after registration: the delivery must fail without
+// reaching the receiver.
+func TestServiceRefusesToDialPrivateAddresses(t *testing.T) {
+	s := NewService(Options{QueueSize: 1, Timeout: time.Second, LogSize: 10})
+	t.Cleanup(s.Close)
+	rec := newReceiver(t)
+	s.webhooks["rebound"] = &Webhook{ID: "rebound", Tenant: testTenant, URL: rec.URL, Events: []string{"job.completed"}}
+
+	s.Publish(testTenant, "job.completed", map[string]string{"job_id": "J1"})
+
+	deliveries := waitDeliveries(t, s, "rebound", 1)
+	if d := deliveries[0]; d.Succeeded || !strings.Contains(d.Error, ErrPrivateAddress.Error()) {
+		t.Errorf("logged delivery = %+v, want a failure refusing the private address", d)
+	}
+	if calls := rec.calls.Load(); calls != 0 {
+		t.Errorf("receiver got %d deliveries, want 0", calls)
+	}
+}
+
+func TestServiceDelete(t *testing.T) {
+	s := newTestService(t, 1)
+	first, _ := s.Register(testTenant, "https://example.com/first", []string{"job.completed"}, "")
+	second, _ := s.Register(testTenant, "https://example.com/second", []string{"job.completed"}, "")
+
+	if err := s.Delete(testTenant, first.ID); err != nil {
+		t.Fatalf("Delete() unexpected error: %v", err)
+	}
+	if err := s.Delete(testTenant, first.ID); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("second Delete() error = %v, want %v", err, store.ErrNotFound)
+	}
+	if _, err := s.Deliveries(testTenant, first.ID); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("Deliveries() after Delete() error = %v, want %v", err, store.ErrNotFound)
+	}
+	if list := s.List(testTenant); len(list) != 1 || list[0].ID != second.ID {
+		t.Errorf("List() = %+v, want only %s", list, second.ID)
+	}
+
+	// Other tenants can neither see nor delete the webhook
+	if _, err := s.Get("globex", second.ID); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("Get() by another tenant error = %v, want %v", err, store.ErrNotFound)
+	}
+	if err := s.Delete("globex", second.ID); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("Delete() by another tenant error = %v, want %v", err, store.ErrNotFound)
+	}
+	if list := s.List("globex"); len(list) != 0 {
+		t.Errorf("List() of another tenant = %+v, want none", list)
+	}
+}
+
+func TestServiceBackoff(t *testing.T) {
+	s := &Service{options: Options{InitialBackoff: time.Second, MaxBackoff: 10 * time.Second}}
+
+	// --- Define Test Scenarios ---
+	testCases := []struct {
+		attempt  int
+		expected time.Duration
+	}{
+		{attempt: 1, expected: time.Second},
+		{attempt: 2, expected: 2 * time.Second},
+		{attempt: 4, expected: 8 * time.Second},
+		{attempt: 5, expected: 10 * time.Second},
+		{attempt: 80, expected: 10 * time.Second},
+	}
+
+	// --- Execute Scenarios ---
+	for _, tc := range testCases {
+		if got := s.backoff(tc.attempt); got != tc.expected {
+			t.Errorf("backoff(%d) = %v, want %v", tc.attempt, got, tc.expected)
+		}
+	}
+}